@@ -0,0 +1,107 @@
+// Package assets Статические ресурсы веб интерфейса (css, шрифты), встроенные в исполняемый файл.
+// Файлы скачиваются в каталог static командой make assets (go generate). Веб интерфейс не обращается
+// к внешним CDN, поэтому make build завершается ошибкой, если какой-либо ресурс не был скачан
+package assets
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"log"
+	"sync"
+)
+
+//go:generate go run ../cmd/fetchassets -dir static
+
+// Asset Внешний ресурс: путь в каталоге static и адрес на CDN, с которого он скачивается
+type Asset struct {
+	Path string
+	URL  string
+}
+
+// Styles Таблицы стилей веб интерфейса в порядке подключения.
+// Шрифты и картинки, на которые они ссылаются, скачиваются вместе с ними
+var Styles = []Asset{
+	{Path: "tailwindcss/base.min.css", URL: "https://unpkg.com/tailwindcss@2.1.2/dist/base.min.css"},
+	{Path: "tailwindcss/components.min.css", URL: "https://unpkg.com/tailwindcss@2.1.2/dist/components.min.css"},
+	{Path: "tailwindcss/typography.min.css", URL: "https://unpkg.com/@tailwindcss/typography@0.4.0/dist/typography.min.css"},
+	{Path: "tailwindcss/utilities.min.css", URL: "https://unpkg.com/tailwindcss@2.1.2/dist/utilities.min.css"},
+	{Path: "flowbite/flowbite.min.css", URL: "https://unpkg.com/flowbite@1.4.4/dist/flowbite.min.css"},
+	{Path: "fontawesome/css/all.css", URL: "https://use.fontawesome.com/releases/v5.11.2/css/all.css"},
+	{Path: "inter/inter.css", URL: "https://fonts.googleapis.com/css2?family=Inter:wght@300;400;500;600;700&display=swap"},
+}
+
+//go:embed static
+var embedded embed.FS
+
+var (
+	static   fs.FS
+	etags    map[string]string
+	loadOnce sync.Once
+)
+
+// FS Встроенные файлы. Пути относительно каталога static
+func FS() fs.FS { //nolint:ireturn
+	loadOnce.Do(load)
+
+	return static
+}
+
+// ETag Хэш содержимого встроенного файла. false, если файла нет
+func ETag(name string) (string, bool) {
+	loadOnce.Do(load)
+
+	etag, ok := etags[name]
+
+	return etag, ok
+}
+
+// Embedded Встроен ли ресурс в исполняемый файл
+func (a Asset) Embedded() bool {
+	_, ok := ETag(a.Path)
+
+	return ok
+}
+
+// Missing Ресурсы, которые не встроены в исполняемый файл
+func Missing() []Asset {
+	var res []Asset
+
+	for _, a := range Styles {
+		if !a.Embedded() {
+			res = append(res, a)
+		}
+	}
+
+	return res
+}
+
+// Чтение встроенных файлов и расчет их хэшей
+func load() {
+	var err error
+	if static, err = fs.Sub(embedded, "static"); err != nil {
+		log.Panicln(err)
+	}
+
+	etags = map[string]string{}
+
+	err = fs.WalkDir(static, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(static, path)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		sum := sha256.Sum256(data)
+		etags[path] = hex.EncodeToString(sum[:8])
+
+		return nil
+	})
+	if err != nil {
+		log.Panicln(err)
+	}
+}
@@ -0,0 +1,142 @@
+// Скачивание ресурсов веб интерфейса (assets.Styles) для встраивания в сервер.
+// Файлы, на которые ссылаются таблицы стилей через url(...), скачиваются рядом с ними,
+// абсолютные ссылки заменяются на относительные. Запускается через go generate в каталоге assets.
+// С флагом -check только проверяет, что все ресурсы встроены в сборку (используется make build)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/log-server/assets"
+	"github.com/pkg/errors"
+)
+
+// Google Fonts отдает woff2 только современным браузерам
+const userAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+
+// Ссылки на файлы в css
+var cssURL = regexp.MustCompile(`url\(\s*['"]?([^'")]+)['"]?\s*\)`)
+
+var client = &http.Client{Timeout: time.Minute}
+
+func main() {
+	dir := flag.String("dir", "static", "target directory")
+	check := flag.Bool("check", false, "only check that all assets are embedded")
+	flag.Parse()
+
+	if *check {
+		missing := assets.Missing()
+		for _, a := range missing {
+			log.Printf("missing %s (%s)", a.Path, a.URL)
+		}
+
+		if len(missing) > 0 {
+			log.Fatalf("%d web assets are not downloaded, run make assets", len(missing))
+		}
+
+		return
+	}
+
+	for _, a := range assets.Styles {
+		if err := fetchStyle(*dir, a); err != nil {
+			log.Fatalf("%s: %v", a.URL, err)
+		}
+
+		log.Printf("%s -> %s", a.URL, a.Path)
+	}
+}
+
+// Скачать таблицу стилей вместе с файлами, на которые она ссылается
+func fetchStyle(dir string, a assets.Asset) error {
+	base, err := url.Parse(a.URL)
+	if err != nil {
+		return errors.Wrap(err, "bad url")
+	}
+
+	data, err := download(a.URL)
+	if err != nil {
+		return err
+	}
+
+	css := string(data)
+	saved := map[string]bool{}
+
+	for _, m := range cssURL.FindAllStringSubmatch(css, -1) {
+		ref := m[1]
+		if strings.HasPrefix(ref, "data:") || saved[ref] {
+			continue
+		}
+
+		saved[ref] = true
+
+		refURL, err := url.Parse(ref)
+		if err != nil {
+			return errors.Wrapf(err, "bad reference %s", ref)
+		}
+
+		// относительные ссылки сохраняются как есть, абсолютные - в подкаталог files
+		target := path.Join(path.Dir(a.Path), refURL.Path)
+		if refURL.IsAbs() {
+			target = path.Join(path.Dir(a.Path), "files", path.Base(refURL.Path))
+			css = strings.ReplaceAll(css, ref, "files/"+path.Base(refURL.Path))
+		}
+
+		refURL.Fragment = ""
+		refURL.RawQuery = ""
+
+		file, err := download(base.ResolveReference(refURL).String())
+		if err != nil {
+			return err
+		}
+
+		if err := save(dir, target, file); err != nil {
+			return err
+		}
+	}
+
+	return save(dir, a.Path, []byte(css))
+}
+
+func download(u string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "request error")
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "download error")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", u, resp.Status) //nolint:goerr113
+	}
+
+	data, err := io.ReadAll(resp.Body)
+
+	return data, errors.Wrap(err, "read error")
+}
+
+func save(dir string, name string, data []byte) error {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return errors.Wrap(err, "mkdir error")
+	}
+
+	return errors.Wrap(os.WriteFile(target, data, 0o644), "write error") //nolint:gosec
+}
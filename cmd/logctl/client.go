@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/n-r-w/log-server/pkg/client"
+	"github.com/pkg/errors"
+)
+
+// Сохраненная сессия
+type session struct {
+	Server string `json:"server"`
+	Cookie string `json:"cookie"`
+}
+
+// Клиент REST API сервера. Аутентификация по токену API или по куки сессии, сохраненной командой login
+func newClient(server, token string) (*client.Client, error) {
+	server = strings.TrimRight(server, "/")
+
+	// без токена используется сессия, если она открыта на этом же сервере
+	var cookie string
+
+	if token == "" {
+		if s, err := loadSession(); err == nil && s.Server == server {
+			cookie = s.Cookie
+		}
+	}
+
+	c, err := client.New(client.Config{
+		Server:  server,
+		Token:   token,
+		Session: cookie,
+		// tail -f, выгрузка и загрузка записей могут длиться долго
+		HTTPClient:   &http.Client{Timeout: 0}, //nolint:exhaustivestruct,exhaustruct
+		Retries:      0,
+		RetryBackoff: 0,
+		DisableGzip:  false,
+		UserAgent:    "logctl",
+	})
+
+	return c, errors.Wrap(err, "client")
+}
+
+// Подсказка к ошибке 401, если нет ни токена, ни сессии
+func explainError(err error, token string) error {
+	var apiErr *client.Error
+	if token == "" && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		return errors.Wrap(err, "run logctl login or set LOGCTL_TOKEN")
+	}
+
+	return err
+}
+
+// Путь к файлу сессии: ~/.config/logctl/session.json
+func sessionPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err, "session")
+	}
+
+	return filepath.Join(dir, "logctl", "session.json"), nil
+}
+
+func loadSession() (*session, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "session")
+	}
+
+	var s session
+
+	return &s, errors.Wrap(json.Unmarshal(data, &s), "session")
+}
+
+func saveSession(s *session) error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil { //nolint:gomnd
+		return errors.Wrap(err, "session")
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "session")
+	}
+
+	// в файле секрет сессии, поэтому он доступен только владельцу
+	return errors.Wrap(os.WriteFile(path, data, 0o600), "session") //nolint:gomnd
+}
+
+func removeSession() error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "session")
+	}
+
+	return nil
+}
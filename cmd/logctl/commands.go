@@ -0,0 +1,630 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/importer"
+	"github.com/n-r-w/log-server/pkg/client"
+	"github.com/pkg/errors"
+)
+
+const (
+	// количество записей в одном запросе add-log при чтении из stdin
+	addBatchSize = 1000
+	// размер страницы при поиске последних записей
+	tailPageSize = 500
+	// максимальное количество страниц, просматриваемых при поиске последних записей, подходящих под фильтр
+	tailMaxPages = 20
+)
+
+// Команда logctl
+type command struct {
+	usage string
+	run   func(ctx context.Context, c *client.Client, args []string) error
+}
+
+var commands = map[string]command{
+	"login":  {usage: "open a session with login and password", run: runLogin},
+	"logout": {usage: "close the saved session", run: runLogout},
+	"query":  {usage: "print records for a time interval", run: runQuery},
+	"tail":   {usage: "print the latest records and optionally follow new ones (-f)", run: runTail},
+	"add":    {usage: "add a record from arguments or records from stdin lines", run: runAdd},
+	"users":  {usage: "list users", run: runUsers},
+	"export": {usage: "download records as csv, ndjson or proto-delimited", run: runExport},
+	"import": {usage: "upload a file with historical records (admin)", run: runImport},
+}
+
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: logctl %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runLogin(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("login", "")
+	user := fs.String("user", "", "login")
+	password := fs.String("password", os.Getenv("LOGCTL_PASSWORD"),
+		"password (env LOGCTL_PASSWORD, read from stdin if empty)")
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if *user == "" {
+		return errors.New("-user is required")
+	}
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return errors.Wrap(err, "password")
+		}
+
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	if err := c.Login(ctx, *user, *password); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if err := saveSession(&session{Server: c.Server(), Cookie: c.Session()}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "logged in to %s as %s\n", c.Server(), *user)
+
+	return nil
+}
+
+func runLogout(ctx context.Context, c *client.Client, args []string) error {
+	if err := newFlagSet("logout", "").Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if c.Session() != "" {
+		if err := c.Logout(ctx); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	return removeSession()
+}
+
+// Фильтр записей на стороне клиента
+type filter struct {
+	minLevel uint
+	source   string
+	pattern  *regexp.Regexp
+}
+
+// Флаги фильтра. Возвращает функцию, создающую фильтр после разбора флагов
+func filterFlags(fs *flag.FlagSet) func() (*filter, error) {
+	level := fs.String("level", "", "minimum level: number or debug, info, warning, error, critical")
+	source := fs.String("source", "", "source (message2)")
+	grep := fs.String("grep", "", "regular expression for any of the messages")
+
+	return func() (*filter, error) {
+		f := &filter{minLevel: 0, source: *source, pattern: nil}
+
+		if *level != "" {
+			var err error
+			if f.minLevel, err = parseLevel(*level); err != nil {
+				return nil, err
+			}
+		}
+
+		if *grep != "" {
+			var err error
+			if f.pattern, err = regexp.Compile(*grep); err != nil {
+				return nil, errors.Wrap(err, "grep")
+			}
+		}
+
+		return f, nil
+	}
+}
+
+func parseLevel(s string) (uint, error) {
+	for level := client.LevelDebug; level <= client.LevelCritical; level++ {
+		if strings.EqualFold(s, levelName(level)) {
+			return level, nil
+		}
+	}
+
+	level, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("bad level %q", s)
+	}
+
+	return uint(level), nil
+}
+
+func (f *filter) matches(r *client.Record) bool {
+	if r.Level < f.minLevel || (f.source != "" && r.Message2 != f.source) {
+		return false
+	}
+
+	return f.pattern == nil || f.pattern.MatchString(r.Message1) || f.pattern.MatchString(r.Message2) ||
+		f.pattern.MatchString(r.Message3)
+}
+
+// Время: RFC3339, местное время без смещения, now или now-<длительность>, например now-1h30m
+func parseTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if rest := strings.TrimPrefix(v, "now"); rest != v {
+		if rest == "" {
+			return now, nil
+		}
+
+		d, err := time.ParseDuration(strings.TrimPrefix(rest, "-"))
+		if err != nil || !strings.HasPrefix(rest, "-") {
+			return time.Time{}, fmt.Errorf("bad time %q", v)
+		}
+
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("bad time %q", v)
+}
+
+func runQuery(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("query", "")
+	from := fs.String("from", "now-1h", "interval start: RFC3339, local time, now-<duration>")
+	to := fs.String("to", "", "interval end (default: no limit)")
+	limit := fs.Int("limit", 0, "print only the latest records (0: all)")
+	output := fs.String("o", outputTable, "output: table or json")
+	parseFilter := filterFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	f, err := parseFilter()
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	timeFrom, err := parseTime(*from, now)
+	if err != nil {
+		return err
+	}
+
+	timeTo, err := parseTime(*to, now)
+	if err != nil {
+		return err
+	}
+
+	records, limited, err := c.Records(ctx, timeFrom, timeTo)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if limited {
+		fmt.Fprintf(os.Stderr, "only the latest %d records of the interval are returned, narrow the interval or use export\n",
+			len(records))
+	}
+
+	// записи приходят от новых к старым без учета ID при одинаковом времени, выводятся от старых к новым
+	sort.SliceStable(records, func(i, j int) bool { return isNewer(&records[i], &records[j]) })
+
+	var res []client.Record
+
+	for i := range records {
+		if *limit > 0 && len(res) >= *limit {
+			break
+		}
+
+		if f.matches(&records[i]) {
+			res = append(res, records[i])
+		}
+	}
+
+	reverse(res)
+
+	return p.records(res, true)
+}
+
+func runTail(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("tail", "")
+	count := fs.Int("n", 10, "number of latest records to print")
+	follow := fs.Bool("f", false, "wait for new records")
+	interval := fs.Duration("interval", 2*time.Second, "poll interval with -f") //nolint:gomnd
+	output := fs.String("o", outputTable, "output: table or json")
+	parseFilter := filterFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	f, err := parseFilter()
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		return err
+	}
+
+	// новые записи запрашиваются начиная с самой новой из уже полученных, а не только подходящих под фильтр
+	records, newest, err := latest(ctx, c, *count, f)
+	if err != nil {
+		return err
+	}
+
+	reverse(records)
+
+	if err := p.records(records, true); err != nil || !*follow {
+		return err
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	header := len(records) == 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		records, newest, err = newRecords(ctx, c, newest, f)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if len(records) > 0 {
+			if err := p.records(records, header); err != nil {
+				return err
+			}
+
+			header = false
+		}
+	}
+}
+
+// Последние count записей, подходящих под фильтр, от новых к старым. Просматривается не больше tailMaxPages страниц,
+// поэтому для редких записей их может быть меньше count. Возвращает также самую новую из всех записей
+func latest(ctx context.Context, c *client.Client, count int, f *filter) ([]client.Record, client.Record, error) {
+	var (
+		res    []client.Record
+		newest client.Record
+		cursor string
+	)
+
+	for pages := 0; len(res) < count && pages < tailMaxPages; pages++ {
+		page, err := c.Page(ctx, client.PageQuery{
+			From: time.Time{}, To: time.Time{}, Cursor: cursor, Limit: tailPageSize,
+		})
+		if err != nil {
+			return nil, newest, err //nolint:wrapcheck
+		}
+
+		if pages == 0 && len(page.Records) > 0 {
+			newest = page.Records[0]
+		}
+
+		for i := range page.Records {
+			if len(res) < count && f.matches(&page.Records[i]) {
+				res = append(res, page.Records[i])
+			}
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor = page.NextCursor
+	}
+
+	return res, newest, nil
+}
+
+// Записи новее since (по времени записи и ID), подходящие под фильтр, от старых к новым.
+// Страницы запрашиваются от новых к старым начиная со времени since, пока не встретится since.
+// Записи, добавленные со временем раньше since, не возвращаются. Возвращает также самую новую из всех записей
+func newRecords(ctx context.Context, c *client.Client, since client.Record, f *filter,
+) ([]client.Record, client.Record, error) {
+	var (
+		res    []client.Record
+		cursor string
+	)
+
+	newest := since
+
+	for {
+		page, err := c.Page(ctx, client.PageQuery{
+			From: since.LogTime, To: time.Time{}, Cursor: cursor, Limit: tailPageSize,
+		})
+		if err != nil {
+			return nil, since, err //nolint:wrapcheck
+		}
+
+		for i := range page.Records {
+			r := &page.Records[i]
+			if !isNewer(r, &since) {
+				reverse(res)
+
+				return res, newest, nil
+			}
+
+			if isNewer(r, &newest) {
+				newest = *r
+			}
+
+			if f.matches(r) {
+				res = append(res, *r)
+			}
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor = page.NextCursor
+	}
+
+	reverse(res)
+
+	return res, newest, nil
+}
+
+// Запись r новее записи than в порядке страниц записей: по времени записи, затем по ID
+func isNewer(r, than *client.Record) bool {
+	return r.LogTime.After(than.LogTime) || (r.LogTime.Equal(than.LogTime) && r.ID > than.ID)
+}
+
+func reverse(records []client.Record) {
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+}
+
+func runAdd(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("add", "[message...]")
+	level := fs.String("level", "info", "level: number or debug, info, warning, error, critical")
+	source := fs.String("source", "", "source (message2)")
+	details := fs.String("details", "", "details (message3)")
+	logTime := fs.String("time", "now", "log time: RFC3339, local time, now-<duration>")
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	lvl, err := parseLevel(*level)
+	if err != nil {
+		return err
+	}
+
+	t, err := parseTime(*logTime, time.Now())
+	if err != nil {
+		return err
+	}
+
+	record := func(message string) client.Record {
+		return client.Record{
+			ID:       0,
+			LogTime:  t,
+			RealTime: time.Time{},
+			Level:    lvl,
+			Message1: message,
+			Message2: *source,
+			Message3: *details,
+		}
+	}
+
+	send := func(records []client.Record) error {
+		return c.Add(ctx, records) //nolint:wrapcheck
+	}
+
+	if fs.NArg() > 0 {
+		return send([]client.Record{record(strings.Join(fs.Args(), " "))})
+	}
+
+	// каждая непустая строка stdin - отдельная запись
+	var (
+		batch []client.Record
+		total int
+	)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, 1<<20) //nolint:gomnd
+
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			if *logTime == "now" {
+				t = time.Now()
+			}
+
+			batch = append(batch, record(line))
+		}
+
+		if len(batch) >= addBatchSize {
+			if err := send(batch); err != nil {
+				return err
+			}
+
+			total += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "stdin")
+	}
+
+	if len(batch) > 0 {
+		if err := send(batch); err != nil {
+			return err
+		}
+
+		total += len(batch)
+	}
+
+	fmt.Fprintf(os.Stderr, "added %d records\n", total)
+
+	return nil
+}
+
+func runUsers(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("users", "")
+	output := fs.String("o", outputTable, "output: table or json")
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	p, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		return err
+	}
+
+	users, err := c.Users(ctx)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return p.users(users)
+}
+
+func runExport(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("export", "")
+	format := fs.String("format", "csv", "csv, ndjson or proto-delimited")
+	from := fs.String("from", "", "interval start: RFC3339 or now-<duration> (default: no limit)")
+	to := fs.String("to", "", "interval end (default: no limit)")
+	tz := fs.String("tz", "", "time zone of times without offset and of CSV output (IANA)")
+	out := fs.String("out", "", "output file (default: stdout)")
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	body, err := c.Export(ctx, client.ExportQuery{Format: *format, From: *from, To: *to, TZ: *tz})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer body.Close()
+
+	if *out == "" {
+		_, err = io.Copy(os.Stdout, body)
+
+		return errors.Wrap(err, "export")
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return errors.Wrap(err, "export")
+	}
+
+	if _, err = io.Copy(file, body); err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close()
+	}
+
+	if err != nil {
+		// сервер обрывает соединение при ошибке, неполный файл не оставляем
+		_ = os.Remove(*out)
+
+		return errors.Wrap(err, "export")
+	}
+
+	return nil
+}
+
+func runImport(ctx context.Context, c *client.Client, args []string) error {
+	fs := newFlagSet("import", "file")
+	format := fs.String("format", "", "ndjson, csv or proto-delimited (default by file extension)")
+	skip := fs.Int("skip", 0, "continue an interrupted import after this line")
+	tz := fs.String("tz", "", "time zone of CSV times without offset (IANA)")
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return errors.New("one file is required")
+	}
+
+	path := fs.Arg(0)
+
+	f := importer.Format(*format)
+	if f == "" {
+		var err error
+		if f, err = importer.DetectFormat(path); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "import")
+	}
+	defer file.Close()
+
+	// прерванная загрузка возвращает прогресс вместе с ошибкой
+	res, err := c.Import(ctx, client.ImportQuery{Format: string(f), Skip: *skip, TZ: *tz}, file)
+	if res == nil {
+		return err //nolint:wrapcheck
+	}
+
+	for _, e := range res.Errors {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", path, e.Line, e.Error)
+	}
+
+	if res.Failed > len(res.Errors) {
+		fmt.Fprintf(os.Stderr, "%s: %d more errors\n", path, res.Failed-len(res.Errors))
+	}
+
+	fmt.Printf("%s: line %d, imported %d, failed %d\n", path, res.Line, res.Imported, res.Failed)
+
+	if res.Error != "" {
+		return fmt.Errorf("%s: %s, continue with -skip %d", path, res.Error, res.Line)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+// logctl Клиент командной строки для запросов к серверу журнала через REST API.
+// Аутентификация по токену API (-token, LOGCTL_TOKEN) или по сессии, открытой командой login
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("logctl", flag.ContinueOnError)
+	server := fs.String("server", envOr("LOGCTL_SERVER", "http://localhost:8080"), "server URL (env LOGCTL_SERVER)")
+	token := fs.String("token", os.Getenv("LOGCTL_TOKEN"), "API token (env LOGCTL_TOKEN)")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: logctl [flags] command [command flags]\n\nCommands:\n")
+
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(out, "  %-8s %s\n", name, commands[name].usage)
+		}
+
+		fmt.Fprintf(out, "\nFlags:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(out, "\nRun logctl command -h for command flags\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+
+		return 2 //nolint:gomnd
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+
+		return 2 //nolint:gomnd
+	}
+
+	// по Ctrl+C прерываются текущий запрос и tail -f
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c, err := newClient(*server, *token)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "logctl:", err)
+
+		return 2 //nolint:gomnd
+	}
+
+	if err := cmd.run(ctx, c, fs.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+
+		fmt.Fprintln(os.Stderr, "logctl:", explainError(err, *token))
+
+		return 1
+	}
+
+	return 0
+}
+
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+
+	return def
+}
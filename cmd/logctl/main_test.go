@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/testserver"
+	"github.com/n-r-w/log-server/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Вывод команды в stdout
+func capture(t *testing.T, run func() error) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	done := make(chan string)
+
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	err = run()
+
+	os.Stdout = stdout
+	w.Close()
+
+	require.NoError(t, err)
+
+	return <-done
+}
+
+func TestCommands(t *testing.T) {
+	srv := testserver.New(t)
+	ts := httptest.NewServer(srv.Router)
+	t.Cleanup(ts.Close)
+
+	newTestClient := func(cookie string) *client.Client {
+		c, err := client.New(client.Config{
+			Server:       ts.URL,
+			Token:        "",
+			Session:      cookie,
+			HTTPClient:   ts.Client(),
+			Retries:      0,
+			RetryBackoff: 0,
+			DisableGzip:  false,
+			UserAgent:    "",
+		})
+		require.NoError(t, err)
+
+		return c
+	}
+
+	c := newTestClient(srv.SessionCookie(t, srv.Config.SuperAdminID).Value)
+	ctx := context.Background()
+
+	require.NoError(t, runAdd(ctx, c, []string{"-level", "error", "-source", "billing", "payment", "failed"}))
+	require.NoError(t, runAdd(ctx, c, []string{"-time", "now-1m", "started"}))
+
+	// JSON запись в строке, от старых к новым
+	out := capture(t, func() error { return runQuery(ctx, c, []string{"-o", "json"}) })
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+
+	var record client.Record
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "payment failed", record.Message1)
+	assert.Equal(t, client.LevelError, record.Level)
+
+	out = capture(t, func() error { return runQuery(ctx, c, []string{"-level", "warning", "-grep", "pay"}) })
+	assert.Contains(t, out, "billing")
+	assert.NotContains(t, out, "started")
+
+	out = capture(t, func() error { return runTail(ctx, c, []string{"-n", "1"}) })
+	assert.Contains(t, out, "payment failed")
+	assert.NotContains(t, out, "started")
+
+	// tail -f: только записи новее последней полученной
+	all := &filter{minLevel: 0, source: "", pattern: nil}
+	_, newest, err := latest(ctx, c, 1, all)
+	require.NoError(t, err)
+	assert.Equal(t, "payment failed", newest.Message1)
+
+	require.NoError(t, runAdd(ctx, c, []string{"retried"}))
+
+	added, newest, err := newRecords(ctx, c, newest, all)
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	assert.Equal(t, "retried", added[0].Message1)
+	assert.Equal(t, added[0].ID, newest.ID)
+
+	added, _, err = newRecords(ctx, c, newest, all)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+
+	u := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(ctx, u))
+
+	out = capture(t, func() error { return runUsers(ctx, c, nil) })
+	assert.Contains(t, out, u.Login)
+
+	// выгруженный файл загружается обратно
+	path := filepath.Join(t.TempDir(), "logs.csv")
+	require.NoError(t, runExport(ctx, c, []string{"-format", "csv", "-out", path}))
+
+	out = capture(t, func() error { return runImport(ctx, c, []string{path}) })
+	assert.Contains(t, out, "imported 3, failed 0")
+
+	records, _, err := srv.LogRepo.Find(ctx, time.Time{}, time.Time{}, 100)
+	require.NoError(t, err)
+	assert.Len(t, *records, 6)
+
+	// без сессии сервер отвечает 401
+	err = runUsers(ctx, newTestClient(""), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Contains(t, explainError(err, "").Error(), "logctl login")
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+
+	v, err := parseTime("now-1h30m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-90*time.Minute), v)
+
+	v, err = parseTime("2022-04-23T12:00:00+02:00", now)
+	require.NoError(t, err)
+	assert.True(t, v.Equal(now))
+
+	_, err = parseTime("now+1h", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/n-r-w/log-server/pkg/client"
+	"github.com/pkg/errors"
+)
+
+// Форматы вывода
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var errBadOutput = errors.New("output must be table or json")
+
+// Вывод записей журнала и пользователей
+type printer struct {
+	w      io.Writer
+	format string
+	loc    *time.Location
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	if format != outputTable && format != outputJSON {
+		return nil, errBadOutput
+	}
+
+	return &printer{w: w, format: format, loc: time.Local}, nil
+}
+
+// Записи: таблица с первой строкой каждого сообщения или JSON запись в строке (удобно для jq)
+func (p *printer) records(records []client.Record, header bool) error {
+	if p.format == outputJSON {
+		enc := json.NewEncoder(p.w)
+		for i := range records {
+			if err := enc.Encode(&records[i]); err != nil {
+				return errors.Wrap(err, "json")
+			}
+		}
+
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0) //nolint:gomnd
+	if header {
+		fmt.Fprintln(tw, "ID\tTIME\tLEVEL\tSOURCE\tMESSAGE\tDETAILS")
+	}
+
+	for _, r := range records {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.LogTime.In(p.loc).Format("2006-01-02 15:04:05.000"),
+			levelName(r.Level), firstLine(r.Message2), firstLine(r.Message1), firstLine(r.Message3))
+	}
+
+	return errors.Wrap(tw.Flush(), "output")
+}
+
+func (p *printer) users(users []client.User) error {
+	if p.format == outputJSON {
+		return errors.Wrap(json.NewEncoder(p.w).Encode(users), "json")
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0) //nolint:gomnd
+	fmt.Fprintln(tw, "ID\tLOGIN\tNAME\tROLE")
+
+	for _, u := range users {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", u.ID, u.Login, u.Name, u.Role)
+	}
+
+	return errors.Wrap(tw.Flush(), "output")
+}
+
+// Название стандартного уровня или число
+func levelName(level uint) string {
+	switch level {
+	case client.LevelDebug:
+		return "debug"
+	case client.LevelInfo:
+		return "info"
+	case client.LevelWarning:
+		return "warning"
+	case client.LevelError:
+		return "error"
+	case client.LevelCritical:
+		return "critical"
+	default:
+		return fmt.Sprint(level)
+	}
+}
+
+// Первая строка текста, чтобы многострочные сообщения не ломали таблицу
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return s[:i] + " …"
+	}
+
+	return s
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/importer"
+	"github.com/n-r-w/log-server/internal/domain/usecase"
+	"github.com/n-r-w/log-server/internal/repository/psql"
+	"github.com/pkg/errors"
+)
+
+// Расширение файла с состоянием загрузки, по которому прерванная загрузка продолжается при повторном запуске
+const importStateExt = ".import-state"
+
+// logserver import [флаги] файл... Загрузка исторических записей из файлов напрямую в БД из конфига.
+// Ошибки в записях выводятся в stderr, после каждой пачки выводится прогресс и сохраняется состояние загрузки
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s import [flags] file...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+
+	configPath := fs.String("config-path", "", "path to config file (env "+config.EnvPrefix+"CONFIG_PATH)")
+	format := fs.String("format", "", "file format: ndjson, csv or proto-delimited (default by file extension)")
+	batchSize := fs.Int("batch-size", importer.DefaultBatchSize, "records per insert")
+	timezone := fs.String("tz", "UTC", "time zone of CSV times without offset")
+	restart := fs.Bool("restart", false, "ignore saved state and import files from the beginning")
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return errors.New("no files to import")
+	}
+
+	loc, err := time.LoadLocation(*timezone)
+	if err != nil {
+		return errors.Wrap(err, "tz")
+	}
+
+	var configArgs []string
+	if *configPath != "" {
+		configArgs = []string{"-config-path", *configPath}
+	}
+
+	cfg, err := config.LoadArgs(configArgs, os.Environ(), defaultConfigPath)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	dbo, err := psql.CreatePsqlDBO(cfg)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer dbo.Close()
+
+	logCase := usecase.NewLogCase(psql.NewLog(dbo, cfg), cfg)
+
+	// по Ctrl+C загрузка останавливается, состояние последней записанной пачки сохраняется
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, path := range fs.Args() {
+		opts := importer.Options{
+			Format:    "",
+			Location:  loc,
+			BatchSize: *batchSize,
+			Skip:      0,
+			Progress:  nil,
+			Error:     nil,
+		}
+
+		if *format == "" {
+			if opts.Format, err = importer.DetectFormat(path); err != nil {
+				return err //nolint:wrapcheck
+			}
+		} else if opts.Format, err = importer.ParseFormat(*format); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if err := importFile(ctx, logCase, path, opts, *restart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Загрузка одного файла с продолжением с места, сохраненного в <файл>.import-state
+func importFile(ctx context.Context, logCase usecase.LogInterface, path string, opts importer.Options,
+	restart bool,
+) error {
+	statePath := path + importStateExt
+
+	var saved importer.Progress
+
+	if !restart {
+		if data, err := os.ReadFile(statePath); err == nil {
+			if err := json.Unmarshal(data, &saved); err != nil {
+				return errors.Wrapf(err, "state file %s", statePath)
+			}
+
+			fmt.Fprintf(os.Stderr, "%s: resuming after line %d\n", path, saved.Line)
+		} else if !os.IsNotExist(err) {
+			return errors.Wrap(err, "state file")
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "import")
+	}
+	defer file.Close()
+
+	total := func(p importer.Progress) importer.Progress {
+		return importer.Progress{
+			Line:     p.Line,
+			Imported: saved.Imported + p.Imported,
+			Failed:   saved.Failed + p.Failed,
+		}
+	}
+
+	start := time.Now()
+	opts.Skip = saved.Line
+	opts.Error = func(e importer.LineError) {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", path, e.Line, e.Error)
+	}
+	opts.Progress = func(p importer.Progress) {
+		p = total(p)
+
+		if err := saveImportState(statePath, p); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "%s: line %d, imported %d, failed %d, %.0f records/s\n", path, p.Line,
+			p.Imported, p.Failed, float64(p.Imported-saved.Imported)/time.Since(start).Seconds())
+	}
+
+	progress, err := importer.Run(ctx, file, logCase.Import, opts)
+	if err != nil {
+		return errors.Wrapf(err, "%s: stopped after line %d, run the same command to resume", path, progress.Line)
+	}
+
+	progress = total(progress)
+	fmt.Printf("%s: done, imported %d, failed %d\n", path, progress.Imported, progress.Failed)
+
+	return nil
+}
+
+// Запись состояния через временный файл, чтобы при прерывании не оставить его недописанным
+func saveImportState(path string, p importer.Progress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "state file")
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil { //nolint:gomnd
+		return errors.Wrap(err, "state file")
+	}
+
+	return errors.Wrap(os.Rename(tmp, path), "state file")
+}
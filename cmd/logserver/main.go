@@ -1,70 +1,174 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"os"
+	"time"
+	// база часовых поясов для серверов и контейнеров без tzdata
+	_ "time/tzdata"
 
 	"github.com/gorilla/sessions"
+	"github.com/n-r-w/log-server/internal/app"
+	"github.com/n-r-w/log-server/internal/app/alerting"
 	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/forward"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/app/notify"
+	"github.com/n-r-w/log-server/internal/app/selflog"
 	"github.com/n-r-w/log-server/internal/domain"
 	"github.com/n-r-w/log-server/internal/domain/usecase"
 	"github.com/n-r-w/log-server/internal/presentation/httprouter"
 	"github.com/n-r-w/log-server/internal/repository"
 	"github.com/n-r-w/log-server/internal/repository/psql"
 	"github.com/n-r-w/log-server/internal/repository/testrepo"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+// Путь к конфигу, если он не задан флагом или переменной окружения
+const defaultConfigPath = "config/server.toml"
+
 func main() {
-	var configPath string
-	// описание флагов командной строки
-	flag.StringVar(&configPath, "config-path", "config/server.toml", "path to config file")
+	// logserver import: загрузка записей из файлов вместо запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	// читаем конфиг: значения по умолчанию, файл, переменные окружения LOGSERVER_*, флаги командной строки
+	loadConfig := func() (*config.Config, error) {
+		return config.LoadArgs(os.Args[1:], os.Environ(), defaultConfigPath)
+	}
 
-	// обработка командной строки
-	flag.Parse()
+	cfg, err := loadConfig()
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
 
-	// читаем конфиг
-	if err := config.Load(configPath); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := logger.Configure(cfg.LoggerOptions()); err != nil {
 		log.Fatal(err)
 	}
 
 	// создаем экземпляры объектов, реализующих различные интерфейсы
 
+	var dbo repository.DBOInterface
+
 	var userRepo repository.UserInterface
 
 	var logRepo repository.LogInterface
 
+	var tokenRepo repository.TokenInterface
+
+	var sessionRepo repository.SessionInterface
+
+	var searchRepo repository.SearchInterface
+
+	var alertRepo repository.AlertInterface
+
 	if true {
 		// реальная БД
-		dbo, err := psql.CreatePsqlDBO()
+		dbo, err = psql.CreatePsqlDBO(cfg)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		userRepo = psql.NewUser(dbo)
-		logRepo = psql.NewLog(dbo)
+		userRepo = psql.NewUser(dbo, cfg)
+		logRepo = psql.NewLog(dbo, cfg)
+		tokenRepo = psql.NewToken(dbo)
+		sessionRepo = psql.NewSession(dbo)
+		searchRepo = psql.NewSearch(dbo)
+		alertRepo = psql.NewAlert(dbo)
 	} else {
 		// фейковая БД
-		dbo, err := testrepo.CreateTestlDBO()
+		dbo, err = testrepo.CreateTestlDBO()
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		userRepo = testrepo.NewUser(dbo)
+		userRepo = testrepo.NewUser(dbo, cfg)
 		logRepo = testrepo.NewLog(dbo)
+		tokenRepo = testrepo.NewToken(dbo)
+		sessionRepo = testrepo.NewSession(dbo)
+		searchRepo = testrepo.NewSearch(dbo)
+		alertRepo = testrepo.NewAlert(dbo)
 	}
 
 	// создаем сценарии
-	userUsecase := usecase.NewUserCase(userRepo)
-	logCase := usecase.NewLogCase(logRepo)
+	userUsecase := usecase.NewUserCase(userRepo, sessionRepo, cfg)
+	// уведомления правил оповещений пишутся в журнал сервера и отправляются в каналы из конфига
+	senders, err := notify.New(cfg.NotifyChannels)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	notifyCase := usecase.NewNotifyCase(senders, cfg)
+	// правила оповещений проверяются на потоке добавляемых в журнал записей
+	alertCase := usecase.NewAlertCase(alertRepo, alerting.Notifiers{alerting.LogNotifier{}, notifyCase})
+
+	// добавленные записи также пересылаются получателям из конфига
+	sinks, err := forward.New(cfg.ForwardSinks)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	observers := []usecase.LogObserver{alertCase}
+	for _, sink := range sinks {
+		observers = append(observers, sink)
+	}
+
+	logCase := usecase.NewLogCase(logRepo, cfg, observers...)
+	healthCase := usecase.NewHealthCase(dbo, logCase)
+	tokenCase := usecase.NewTokenCase(tokenRepo, userRepo, cfg)
+	sessionCase := usecase.NewSessionCase(sessionRepo, userRepo, cfg)
+	searchCase := usecase.NewSearchCase(searchRepo)
 
 	// инициализируем домен
-	dom := domain.NewDomain(logCase, userUsecase)
+	dom := domain.NewDomain(logCase, userUsecase, healthCase, tokenCase, sessionCase, searchCase, alertCase, notifyCase)
 
 	// создаем роутер
-	sessionStore := sessions.NewCookieStore([]byte(config.AppConfig.SessionEncriptionKey))
-	router := httprouter.NewRouter(dom, sessionStore)
+	sessionStore := sessions.NewCookieStore([]byte(cfg.SessionEncriptionKey))
+	router := httprouter.NewRouter(dom, sessionStore, cfg)
+
+	// компоненты запускаются в порядке добавления и останавливаются в обратном:
+	// сначала HTTP сервер дожидается завершения текущих запросов, затем закрывается пул соединений с БД
+	lifecycle := app.NewLifecycle(time.Duration(cfg.ShutdownTimeoutSec) * time.Second)
+	lifecycle.Add(app.FuncComponent("database", nil, func(context.Context) error {
+		dbo.Close()
+
+		return nil
+	}), 0)
+	// журнал сервера в его же таблице log. Останавливается после HTTP сервера, но до закрытия БД
+	if cfg.SelfLog {
+		level, _ := logrus.ParseLevel(cfg.SelfLogLevel) // проверено при загрузке конфигурации
+		selfLog := selflog.New(logRepo, level)
+		logger.Logger().AddHook(selfLog)
+		lifecycle.Add(selfLog, 0)
+	}
+
+	// пересылка записей останавливается после HTTP сервера с отправкой накопленных записей
+	for _, sink := range sinks {
+		lifecycle.Add(sink, 0)
+	}
+	// отправка уведомлений останавливается после проверки правил
+	lifecycle.Add(notify.NewWorker(notifyCase), 0)
+	// правила оповещений загружаются до запуска HTTP сервера, чтобы не пропустить записи
+	lifecycle.Add(alerting.New(alertCase, time.Duration(cfg.AlertEvalIntervalSec)*time.Second), 0)
+	lifecycle.Add(router, time.Duration(cfg.HTTPDrainTimeoutSec)*time.Second)
+	// по SIGHUP или запросу /api/private/reload-config перечитываем конфигурацию и сертификаты
+	lifecycle.OnReload("configuration", func() error { return cfg.Reload(loadConfig) })
+	lifecycle.OnReload("tls certificates", router.ReloadTLS)
+	router.SetReload(lifecycle.Reload)
 
-	if err := router.Start(); err != nil {
+	if err := lifecycle.Run(context.Background()); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,119 @@
+// Package alerting Фоновая проверка правил оповещений и отправка уведомлений.
+// Сами правила и их состояние находятся в usecase.AlertInterface, здесь - периодический запуск проверки
+// и получатели уведомлений
+package alerting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/domain/usecase"
+	"github.com/pkg/errors"
+)
+
+// время на загрузку правил при запуске
+const loadTimeout = 10 * time.Second
+
+// Evaluator Периодическая проверка правил оповещений. Является компонентом app.Lifecycle:
+// при запуске загружает правила из хранилища
+type Evaluator struct {
+	alerts   usecase.AlertInterface
+	interval time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New Создание компонента. interval - период проверки правил
+func New(alerts usecase.AlertInterface, interval time.Duration) *Evaluator {
+	return &Evaluator{
+		alerts:   alerts,
+		interval: interval,
+		stopOnce: sync.Once{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Name Имя компонента (для app.Lifecycle)
+func (e *Evaluator) Name() string {
+	return "alert rules"
+}
+
+// Start Загрузка правил и запуск проверки в отдельной горутине
+func (e *Evaluator) Start(func(err error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), loadTimeout)
+	defer cancel()
+
+	if err := e.alerts.Load(ctx); err != nil {
+		return errors.Wrap(err, "alert rules")
+	}
+
+	go e.run()
+
+	return nil
+}
+
+// Stop Остановка проверки
+func (e *Evaluator) Stop(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stop) })
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "alert rules stop")
+	}
+}
+
+func (e *Evaluator) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			e.alerts.Evaluate(now)
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// LogNotifier Уведомления в журнал сервера: срабатывание - предупреждение, разрешение - информация
+type LogNotifier struct{}
+
+// Notify Записать уведомление в журнал
+func (LogNotifier) Notify(event model.AlertEvent) {
+	entry := logger.Logger().WithFields(map[string]interface{}{
+		"rule":      event.RuleName,
+		"ruleId":    event.RuleID,
+		"count":     event.Count,
+		"threshold": event.Threshold,
+		"windowSec": event.WindowSec,
+		"since":     event.Since,
+		"example":   event.Example,
+	})
+
+	if event.State == model.AlertFiring {
+		entry.Warnf("alert %s is firing", event.RuleName)
+	} else {
+		entry.Infof("alert %s is resolved", event.RuleName)
+	}
+}
+
+// Notifiers Отправка уведомления нескольким получателям по порядку
+type Notifiers []usecase.AlertNotifier
+
+// Notify Передать уведомление всем получателям
+func (n Notifiers) Notify(event model.AlertEvent) {
+	for _, notifier := range n {
+		notifier.Notify(event)
+	}
+}
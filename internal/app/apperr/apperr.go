@@ -0,0 +1,191 @@
+// Package apperr Типизированные ошибки предметной области.
+// Слои usecase и repository возвращают ошибки с указанием их типа, а слой presentation
+// преобразует тип в код ответа (HTTP статус и т.п.), не разбирая текст ошибки
+package apperr
+
+import (
+	"errors"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Kind Тип ошибки
+type Kind int
+
+const (
+	// KindInternal Внутренняя ошибка сервера. Используется для всех ошибок без явно указанного типа
+	KindInternal Kind = iota
+	// KindValidation Некорректные входные данные
+	KindValidation
+	// KindNotFound Объект не найден
+	KindNotFound
+	// KindForbidden Операция запрещена
+	KindForbidden
+	// KindConflict Конфликт с текущим состоянием данных (например, логин уже существует)
+	KindConflict
+	// KindUnavailable Хранилище или другой внешний ресурс временно недоступен
+	KindUnavailable
+)
+
+// String Текстовый код типа ошибки
+func (k Kind) String() string {
+	switch k {
+	case KindValidation:
+		return "validation"
+	case KindNotFound:
+		return "not_found"
+	case KindForbidden:
+		return "forbidden"
+	case KindConflict:
+		return "conflict"
+	case KindUnavailable:
+		return "unavailable"
+	case KindInternal:
+		return "internal"
+	default:
+		return "internal"
+	}
+}
+
+// Error Ошибка с указанием типа
+type Error struct {
+	Kind    Kind
+	Message string
+	// Fields Ошибки по отдельным полям (только для KindValidation)
+	Fields map[string]string
+	// Err Исходная ошибка
+	Err error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	default:
+		return e.Message + ": " + e.Err.Error()
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New Создать ошибку заданного типа
+func New(kind Kind, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Message: message,
+		Fields:  nil,
+		Err:     nil,
+	}
+}
+
+// Wrap Обернуть ошибку с указанием типа. Если err == nil, возвращает nil
+func Wrap(kind Kind, err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{
+		Kind:    kind,
+		Message: message,
+		Fields:  nil,
+		Err:     err,
+	}
+}
+
+// Validation Обернуть ошибку валидации. Ошибки ozzo-validation раскладываются по полям.
+// Если err == nil, возвращает nil
+func Validation(err error) error {
+	return ValidationPrefix(err, "")
+}
+
+// ValidationPrefix То же, что и Validation, но к именам полей добавляется префикс.
+// Используется при валидации массивов, например "[3]."
+func ValidationPrefix(err error, prefix string) error {
+	if err == nil {
+		return nil
+	}
+
+	fields := make(map[string]string)
+
+	var vErrors validation.Errors
+	if errors.As(err, &vErrors) {
+		collectFields(fields, prefix, vErrors)
+	}
+
+	return &Error{
+		Kind:    KindValidation,
+		Message: "validation error",
+		Fields:  fields,
+		Err:     err,
+	}
+}
+
+// Рекурсивный обход вложенных ошибок валидации
+func collectFields(fields map[string]string, prefix string, vErrors validation.Errors) {
+	for name, e := range vErrors {
+		var nested validation.Errors
+		if errors.As(e, &nested) {
+			collectFields(fields, prefix+name+".", nested)
+
+			continue
+		}
+
+		fields[prefix+name] = e.Error()
+	}
+}
+
+// Join Объединить несколько ошибок валидации в одну. Если ошибок нет, возвращает nil
+func Join(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	fields := make(map[string]string)
+
+	for _, err := range errs {
+		for k, v := range FieldsOf(err) {
+			fields[k] = v
+		}
+	}
+
+	return &Error{
+		Kind:    KindValidation,
+		Message: fmt.Sprintf("validation error: %d invalid items", len(errs)),
+		Fields:  fields,
+		Err:     nil,
+	}
+}
+
+// KindOf Тип ошибки. Для ошибок, не являющихся *Error, возвращает KindInternal
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return KindInternal
+}
+
+// FieldsOf Ошибки по полям или nil
+func FieldsOf(err error) map[string]string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+
+	return nil
+}
+
+// Is Относится ли ошибка к указанному типу
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
@@ -0,0 +1,67 @@
+// Package buildinfo Информация о сборке.
+// Значения переменных задаются при сборке через -ldflags, например:
+//
+//	go build -ldflags "-X github.com/n-r-w/log-server/internal/app/buildinfo.Version=1.0.0"
+//
+// Если они не заданы, используется информация, встроенная компилятором (debug.ReadBuildInfo)
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	// Version Версия приложения
+	Version = ""
+	// Commit Хэш коммита
+	Commit = ""
+	// BuildTime Время сборки
+	BuildTime = ""
+)
+
+// Info Информация о сборке
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// Get Информация о текущей сборке
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Modified:  false,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if info.Version == "" && bi.Main.Version != "" {
+		info.Version = bi.Main.Version
+	}
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"os"
+	"sync/atomic"
+
 	"github.com/BurntSushi/toml"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/pkg/errors"
 )
 
-// Конфиг logserver.toml
-type config struct {
+// Config Конфиг logserver.toml. Передается как зависимость в репозитории, сценарии и роутер
+type Config struct {
 	SuperAdminID            uint64
 	BindAddr                string `toml:"BIND_ADDR"`
 	SuperAdminLogin         string `toml:"SUPERADMIN_LOGIN"`
@@ -21,10 +26,69 @@ type config struct {
 	MaxLogRecordsResultWeb  int    `toml:"MAX_LOG_RECORDS_RESULT_WEB"`
 	PasswordRegex           string `toml:"PASSWORD_REGEX"`
 	PasswordRegexError      string `toml:"PASSWORD_REGEX_ERROR"`
-}
+	// MaxIngestQueue Максимальное количество одновременно выполняемых операций записи в журнал (0 - без ограничения)
+	MaxIngestQueue int `toml:"MAX_INGEST_QUEUE"`
+	// ShutdownTimeoutSec Общее время на остановку приложения
+	ShutdownTimeoutSec int `toml:"SHUTDOWN_TIMEOUT_SEC"`
+	// HTTPDrainTimeoutSec Время на завершение обработки текущих HTTP запросов при остановке
+	HTTPDrainTimeoutSec int `toml:"HTTP_DRAIN_TIMEOUT_SEC"`
+	// HTTPReadTimeoutSec Время на чтение запроса сервером (0 - без ограничения)
+	HTTPReadTimeoutSec int `toml:"HTTP_READ_TIMEOUT_SEC"`
+	// HTTPWriteTimeoutSec Время на запись ответа сервером (0 - без ограничения).
+	// Для выгрузки и загрузки записей продлевается до таймаута маршрута
+	HTTPWriteTimeoutSec int `toml:"HTTP_WRITE_TIMEOUT_SEC"`
+	// HTTPIdleTimeoutSec Время ожидания следующего запроса в keep-alive соединении (0 - равно таймауту чтения)
+	HTTPIdleTimeoutSec int `toml:"HTTP_IDLE_TIMEOUT_SEC"`
+	// MaxDecompressedBodyMB Максимальный размер тела запроса, сжатого gzip, после распаковки
+	MaxDecompressedBodyMB int `toml:"MAX_DECOMPRESSED_BODY_MB"`
+	// TLSCertFile Сертификат сервера. Если задан вместе с TLSKeyFile, сервер работает по HTTPS
+	TLSCertFile string `toml:"TLS_CERT_FILE"`
+	// TLSKeyFile Закрытый ключ сервера
+	TLSKeyFile string `toml:"TLS_KEY_FILE"`
+	// TLSClientCAFile Сертификаты УЦ для проверки клиентских сертификатов (mTLS)
+	TLSClientCAFile string `toml:"TLS_CLIENT_CA_FILE"`
+	// TLSClientCertRequired Требовать клиентский сертификат для всех соединений
+	TLSClientCertRequired bool `toml:"TLS_CLIENT_CERT_REQUIRED"`
+	// TLSClientCertUsers Соответствие CN клиентского сертификата логину пользователя
+	TLSClientCertUsers map[string]string `toml:"TLS_CLIENT_CERT_USERS"`
+	// QueryTimeoutSec Таймаут обработки запроса по умолчанию (0 - без ограничения)
+	QueryTimeoutSec int `toml:"QUERY_TIMEOUT_SEC"`
+	// RouteQueryTimeoutSec Таймауты для отдельных маршрутов (имя маршрута - секунды)
+	RouteQueryTimeoutSec map[string]int `toml:"ROUTE_QUERY_TIMEOUT_SEC"`
+	// LogFormat Формат журнала сервера: text или json
+	LogFormat string `toml:"LOG_FORMAT"`
+	// LogFile Файл журнала сервера с ротацией. Если не задан, журнал выводится в stderr
+	LogFile string `toml:"LOG_FILE"`
+	// LogFileMaxSizeMB Размер файла журнала, при превышении которого он ротируется
+	LogFileMaxSizeMB int `toml:"LOG_FILE_MAX_SIZE_MB"`
+	// LogFileMaxBackups Количество хранимых старых файлов журнала (0 - все)
+	LogFileMaxBackups int `toml:"LOG_FILE_MAX_BACKUPS"`
+	// LogFileMaxAgeDays Время хранения старых файлов журнала в днях (0 - без ограничения)
+	LogFileMaxAgeDays int `toml:"LOG_FILE_MAX_AGE_DAYS"`
+	// SelfLog Сохранять журнал сервера в его же таблицу log (источник model.SelfLogSource)
+	SelfLog bool `toml:"SELF_LOG"`
+	// SelfLogLevel Минимальный уровень записей, сохраняемых в таблицу log
+	SelfLogLevel string `toml:"SELF_LOG_LEVEL"`
+	// WebPageSize Количество записей журнала, загружаемых веб интерфейсом за один раз
+	WebPageSize int `toml:"WEB_PAGE_SIZE"`
+	// AlertEvalIntervalSec Период проверки правил оповещений (истечение окон и разрешение оповещений)
+	AlertEvalIntervalSec int `toml:"ALERT_EVAL_INTERVAL_SEC"`
+	// NotifyChannels Каналы уведомлений об оповещениях (задаются только в файле)
+	NotifyChannels []NotifyChannel `toml:"NOTIFY_CHANNELS"`
+	// NotifyRetries Количество повторных попыток отправки уведомления
+	NotifyRetries int `toml:"NOTIFY_RETRIES"`
+	// NotifyRetryBackoffMs Пауза перед первой повторной попыткой, далее удваивается
+	NotifyRetryBackoffMs int `toml:"NOTIFY_RETRY_BACKOFF_MS"`
+	// NotifyTimeoutSec Время на одну попытку отправки уведомления
+	NotifyTimeoutSec int `toml:"NOTIFY_TIMEOUT_SEC"`
+	// MaxExportRecords Максимальное количество записей в одной выгрузке /api/private/export (0 - без ограничения)
+	MaxExportRecords int `toml:"MAX_EXPORT_RECORDS"`
+	// ForwardSinks Получатели, в которые пересылаются добавленные в журнал записи (задаются только в файле)
+	ForwardSinks []ForwardSink `toml:"FORWARD_SINKS"`
 
-// AppConfig Глобальный конфиг
-var AppConfig *config
+	// Конфигурация, загруженная при последней перезагрузке, и ее параметры Runtime (*loadedConfig)
+	reloaded *atomic.Value
+}
 
 const (
 	superAdminID            = 1
@@ -33,32 +97,246 @@ const (
 	maxLogRecordsResult     = 100000
 	maxLogRecordsResultWeb  = 1000
 	defaultSessionAge       = 60 * 60 * 24 // 24 часа
+	queryTimeoutSec         = 15
+	maxIngestQueue          = 100
+	shutdownTimeoutSec      = 30
+	httpDrainTimeoutSec     = 15
+	httpReadTimeoutSec      = 15
+	httpWriteTimeoutSec     = 15
+	httpIdleTimeoutSec      = 60
+	maxDecompressedBodyMB   = 64
+	logFileMaxSizeMB        = 100
+	logFileMaxBackups       = 10
+	logFileMaxAgeDays       = 30
+	webPageSize             = 100
+	alertEvalIntervalSec    = 10
+	notifyRetries           = 3
+	notifyRetryBackoffMs    = 1000
+	notifyTimeoutSec        = 10
+	maxExportRecords        = 1000000
+)
+
+// Типы каналов уведомлений
+const (
+	// NotifyWebhook JSON уведомления методом POST с подписью HMAC-SHA256
+	NotifyWebhook = "webhook"
+	// NotifyEmail Письмо через SMTP
+	NotifyEmail = "email"
+	// NotifyHTTP POST запрос с телом по шаблону (чаты и т.п.)
+	NotifyHTTP = "http"
+)
+
+// NotifyChannel Канал уведомлений об оповещениях. Набор используемых полей зависит от типа
+type NotifyChannel struct {
+	// Name Уникальное имя канала
+	Name string `toml:"NAME"`
+	// Type Тип канала: webhook, email или http
+	Type string `toml:"TYPE"`
+	// URL Адрес для webhook и http
+	URL string `toml:"URL"`
+	// Secret Ключ подписи webhook. Подпись передается в заголовке X-Logserver-Signature
+	Secret string `toml:"SECRET"`
+	// Headers Дополнительные заголовки запроса для webhook и http
+	Headers map[string]string `toml:"HEADERS"`
+	// Template Шаблон text/template тела запроса http или текста письма. Данные шаблона - model.AlertEvent
+	Template string `toml:"TEMPLATE"`
+	// ContentType Тип содержимого запроса http (по умолчанию application/json)
+	ContentType string `toml:"CONTENT_TYPE"`
+	// SMTPAddr Адрес SMTP сервера host:port
+	SMTPAddr string `toml:"SMTP_ADDR"`
+	// SMTPUser Пользователь SMTP. Если не задан, аутентификация не выполняется
+	SMTPUser string `toml:"SMTP_USER"`
+	// SMTPPassword Пароль SMTP
+	SMTPPassword string `toml:"SMTP_PASSWORD"`
+	// From Отправитель письма
+	From string `toml:"FROM"`
+	// To Получатели письма
+	To []string `toml:"TO"`
+}
+
+// Типы получателей пересылаемых записей
+const (
+	// ForwardLogServer Другой экземпляр logserver (POST /api/private/add-log)
+	ForwardLogServer = "logserver"
+	// ForwardHTTP POST запрос с JSON массивом записей
+	ForwardHTTP = "http"
+	// ForwardKafkaREST Топик Kafka через REST прокси (Confluent REST Proxy API v2)
+	ForwardKafkaREST = "kafka-rest"
+	// ForwardFile Файл в формате NDJSON (одна запись в строке)
+	ForwardFile = "file"
 )
 
-// Load Инициализация конфига значениями по умолчанию
-func Load(path string) error {
-	AppConfig = &config{
+// ForwardSink Получатель пересылаемых записей журнала. Незаданные числовые параметры
+// принимают значения по умолчанию
+type ForwardSink struct {
+	// Name Уникальное имя получателя
+	Name string `toml:"NAME"`
+	// Type Тип получателя: logserver, http, kafka-rest или file
+	Type string `toml:"TYPE"`
+	// URL Адрес для logserver (адрес сервера), http и kafka-rest (адрес топика)
+	URL string `toml:"URL"`
+	// Token API токен для logserver
+	Token string `toml:"TOKEN"`
+	// Headers Дополнительные заголовки запроса
+	Headers map[string]string `toml:"HEADERS"`
+	// Path Файл для file
+	Path string `toml:"PATH"`
+	// MinLevel Пересылать записи с уровнем не ниже (0 - все)
+	MinLevel uint `toml:"MIN_LEVEL"`
+	// Pattern Пересылать записи, message1, message2 или message3 которых совпадает с регулярным выражением
+	Pattern string `toml:"PATTERN"`
+	// Sources Пересылать записи только этих источников (message2)
+	Sources []string `toml:"SOURCES"`
+	// BufferSize Размер буфера записей. При его заполнении новые записи отбрасываются
+	BufferSize int `toml:"BUFFER_SIZE"`
+	// BatchSize Максимальный размер пачки записей
+	BatchSize int `toml:"BATCH_SIZE"`
+	// FlushIntervalMs Период отправки накопленных записей
+	FlushIntervalMs int `toml:"FLUSH_INTERVAL_MS"`
+	// Retries Количество повторных попыток отправки пачки
+	Retries int `toml:"RETRIES"`
+	// RetryBackoffMs Пауза перед первой повторной попыткой, далее удваивается
+	RetryBackoffMs int `toml:"RETRY_BACKOFF_MS"`
+	// TimeoutSec Время на одну попытку отправки
+	TimeoutSec int `toml:"TIMEOUT_SEC"`
+}
+
+// Значения по умолчанию
+func defaults() *Config {
+	return &Config{
 		SuperAdminID:            superAdminID,
-		BindAddr:                "http://localhost:8080",
+		BindAddr:                "localhost:8080",
 		SuperAdminLogin:         "admin",
-		SuperPassword:           "admin",
+		SuperPassword:           "",
 		SessionAge:              defaultSessionAge,
 		LogLevel:                "debug",
-		DatabaseURL:             "log",
-		SessionEncriptionKey:    "e09469b1507d0e7a98831750aff903e0831a428f9addf3cfa348fa64dcf",
+		DatabaseURL:             "",
+		SessionEncriptionKey:    "",
 		MaxDbSessions:           maxDbSessions,
 		MaxDbSessionIdleTimeSec: maxDbSessionIdleTimeSec,
 		MaxLogRecordsResult:     maxLogRecordsResult,
 		MaxLogRecordsResultWeb:  maxLogRecordsResultWeb,
 		// PasswordRegex:           "^[A-Za-z0-9@$!%*?&]{8,}$",
-		PasswordRegex:      ".*",
-		PasswordRegexError: "Латинские буквы, цифры и символы @$!%*?& без пробелов, минимум 4 символа",
+		PasswordRegex:         ".*",
+		PasswordRegexError:    "Латинские буквы, цифры и символы @$!%*?& без пробелов, минимум 4 символа",
+		MaxIngestQueue:        maxIngestQueue,
+		ShutdownTimeoutSec:    shutdownTimeoutSec,
+		HTTPDrainTimeoutSec:   httpDrainTimeoutSec,
+		HTTPReadTimeoutSec:    httpReadTimeoutSec,
+		HTTPWriteTimeoutSec:   httpWriteTimeoutSec,
+		HTTPIdleTimeoutSec:    httpIdleTimeoutSec,
+		MaxDecompressedBodyMB: maxDecompressedBodyMB,
+		TLSCertFile:           "",
+		TLSKeyFile:            "",
+		TLSClientCAFile:       "",
+		TLSClientCertRequired: false,
+		TLSClientCertUsers:    map[string]string{},
+		QueryTimeoutSec:       queryTimeoutSec,
+		RouteQueryTimeoutSec:  map[string]int{},
+		LogFormat:             logger.FormatText,
+		LogFile:               "",
+		LogFileMaxSizeMB:      logFileMaxSizeMB,
+		LogFileMaxBackups:     logFileMaxBackups,
+		LogFileMaxAgeDays:     logFileMaxAgeDays,
+		SelfLog:               false,
+		SelfLogLevel:          "warning",
+		WebPageSize:           webPageSize,
+		AlertEvalIntervalSec:  alertEvalIntervalSec,
+		NotifyChannels:        nil,
+		NotifyRetries:         notifyRetries,
+		NotifyRetryBackoffMs:  notifyRetryBackoffMs,
+		NotifyTimeoutSec:      notifyTimeoutSec,
+		MaxExportRecords:      maxExportRecords,
+		ForwardSinks:          nil,
+		reloaded:              new(atomic.Value),
 	}
+}
+
+// Default Конфигурация со значениями по умолчанию и тестовыми секретами (только для тестов).
+// В defaults секретов нет, поэтому сервер не запустится, пока они не заданы
+func Default() *Config {
+	cfg := defaults()
+	cfg.SuperPassword = "admin"
+	cfg.DatabaseURL = "log"
+	cfg.SessionEncriptionKey = "test-session-key-0123456789abcdef0123456789abcdef"
+
+	return cfg
+}
 
+// Load Загрузка конфигурации: значения по умолчанию, затем файл path (если задан),
+// затем переменные окружения LOGSERVER_*. Результат проверяется
+func Load(path string) (*Config, error) {
+	return load(path, os.Environ(), nil)
+}
+
+// LoadArgs Загрузка конфигурации с учетом аргументов командной строки.
+// Порядок применения: значения по умолчанию, файл, переменные окружения LOGSERVER_*, флаги командной строки.
+// Путь к файлу берется из флага -config-path, переменной LOGSERVER_CONFIG_PATH или defaultPath
+func LoadArgs(args []string, environ []string, defaultPath string) (*Config, error) {
+	fs, configPath, flagValues := newFlagSet()
+	if err := fs.Parse(args); err != nil {
+		return nil, errors.Wrap(err, "flags error")
+	}
+
+	path := *configPath
 	if path == "" {
-		return nil
+		path = lookupEnv(environ, EnvPrefix+"CONFIG_PATH")
 	}
-	_, err := toml.DecodeFile(path, AppConfig)
 
-	return errors.Wrap(err, "toml error")
+	if path == "" {
+		path = defaultPath
+	}
+
+	return load(path, environ, flagValues)
+}
+
+// Загрузка по слоям
+func load(path string, environ []string, flagValues map[string]*flagValue) (*Config, error) {
+	cfg := defaults()
+
+	if path != "" {
+		if _, err := toml.DecodeFile(path, cfg); err != nil {
+			return nil, errors.Wrap(err, "toml error")
+		}
+	}
+
+	if err := applyEnv(cfg, environ); err != nil {
+		return nil, err
+	}
+
+	if err := applyFlags(cfg, flagValues); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// TLSEnabled Работает ли сервер по HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// LoggerOptions Настройки журнала сервера
+func (c *Config) LoggerOptions() logger.Options {
+	return logger.Options{
+		Level:      c.Runtime().LogLevel,
+		Format:     c.LogFormat,
+		File:       c.LogFile,
+		MaxSizeMB:  c.LogFileMaxSizeMB,
+		MaxBackups: c.LogFileMaxBackups,
+		MaxAgeDays: c.LogFileMaxAgeDays,
+	}
+}
+
+// SuperAdmin Параметры встроенного администратора
+func (c *Config) SuperAdmin() model.SuperAdmin {
+	return model.SuperAdmin{
+		ID:       c.SuperAdminID,
+		Login:    c.SuperAdminLogin,
+		Password: c.SuperPassword,
+	}
 }
@@ -0,0 +1,146 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Обязательные секреты, у которых нет значений по умолчанию
+var secrets = []string{
+	"LOGSERVER_SUPERADMIN_PASSWORD=secret",
+	"LOGSERVER_SESSION_ENCRYPTION_KEY=0123456789abcdef0123456789abcdef",
+	"LOGSERVER_DATABASE_URL=host=db",
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	return path
+}
+
+func TestLoadArgsPrecedence(t *testing.T) {
+	path := writeFile(t, "server.toml", `
+BIND_ADDR = "127.0.0.1:1000"
+MAX_DB_SESSIONS = 10
+QUERY_TIMEOUT_SEC = 5
+`)
+
+	environ := append([]string{
+		"LOGSERVER_CONFIG_PATH=" + path,
+		"LOGSERVER_MAX_DB_SESSIONS=20",
+		"LOGSERVER_QUERY_TIMEOUT_SEC=7",
+		"LOGSERVER_ROUTE_QUERY_TIMEOUT_SEC=records=3,users=4",
+	}, secrets...)
+	args := []string{"-query-timeout-sec", "9", "-tls-client-cert-required=false"}
+
+	cfg, err := config.LoadArgs(args, environ, "")
+	require.NoError(t, err)
+
+	// файл
+	assert.Equal(t, "127.0.0.1:1000", cfg.BindAddr)
+	// переменная окружения важнее файла
+	assert.Equal(t, 20, cfg.MaxDbSessions)
+	// флаг важнее переменной окружения
+	assert.Equal(t, 9, cfg.QueryTimeoutSec)
+	assert.Equal(t, map[string]int{"records": 3, "users": 4}, cfg.RouteQueryTimeoutSec)
+	// значение по умолчанию
+	assert.Equal(t, "admin", cfg.SuperAdminLogin)
+}
+
+func TestLoadArgsSecretFile(t *testing.T) {
+	secret := writeFile(t, "database_url", "host=db user=logs\n")
+
+	// DATABASE_URL (последний в secrets) берется из файла
+	cfg, err := config.LoadArgs(nil, append(secrets[:2:2], "LOGSERVER_DATABASE_URL_FILE="+secret), "")
+	require.NoError(t, err)
+	assert.Equal(t, "host=db user=logs", cfg.DatabaseURL)
+
+	_, err = config.LoadArgs(nil, []string{"LOGSERVER_DATABASE_URL_FILE=" + secret + ".missing"}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LOGSERVER_DATABASE_URL_FILE")
+}
+
+func TestLoadArgsValidation(t *testing.T) {
+	_, err := config.LoadArgs([]string{
+		"-bind-addr", "http://localhost:8080",
+		"-session-encryption-key", "short",
+		"-tls-cert-file", "server.pem",
+		"-max-db-sessions", "0",
+		"-log-format", "xml",
+	}, nil, "")
+	require.Error(t, err)
+
+	for _, key := range []string{"BIND_ADDR", "SESSION_ENCRYPTION_KEY", "TLS_CERT_FILE", "MAX_DB_SESSIONS", "LOG_FORMAT"} {
+		assert.Contains(t, err.Error(), key+":")
+	}
+
+	_, err = config.LoadArgs(nil, []string{"LOGSERVER_MAX_INGEST_QUEUE=many"}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LOGSERVER_MAX_INGEST_QUEUE")
+
+	// секретов по умолчанию нет
+	_, err = config.LoadArgs(nil, nil, "")
+	require.Error(t, err)
+
+	for _, key := range []string{"SUPERADMIN_PASSWORD", "DATABASE_URL", "SESSION_ENCRYPTION_KEY"} {
+		assert.Contains(t, err.Error(), key+":")
+	}
+}
+
+func TestLoadArgsNotifyChannels(t *testing.T) {
+	path := writeFile(t, "server.toml", `
+[[NOTIFY_CHANNELS]]
+NAME = "ops"
+TYPE = "webhook"
+URL = "https://hooks.example.com/logserver"
+SECRET = "secret"
+
+[[NOTIFY_CHANNELS]]
+NAME = "ops"
+TYPE = "email"
+SMTP_ADDR = "smtp.example.com"
+
+[[NOTIFY_CHANNELS]]
+NAME = "chat"
+TYPE = "sms"
+`)
+
+	_, err := config.LoadArgs([]string{"-config-path", path}, nil, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `channel "ops": duplicate name`)
+	assert.Contains(t, err.Error(), `channel "ops": SMTP_ADDR`)
+	assert.Contains(t, err.Error(), `channel "ops": FROM and TO must be set`)
+	assert.Contains(t, err.Error(), `channel "chat": TYPE`)
+	assert.NotContains(t, err.Error(), "channel 1")
+}
+
+func TestLoadArgsForwardSinks(t *testing.T) {
+	path := writeFile(t, "server.toml", `
+[[FORWARD_SINKS]]
+NAME = "upstream"
+TYPE = "logserver"
+URL = "https://logs.example.com"
+MIN_LEVEL = 4
+
+[[FORWARD_SINKS]]
+NAME = "archive"
+TYPE = "file"
+PATTERN = "("
+BATCH_SIZE = -1
+`)
+
+	_, err := config.LoadArgs([]string{"-config-path", path}, nil, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `sink "archive": PATH must be set`)
+	assert.Contains(t, err.Error(), `sink "archive": PATTERN`)
+	assert.Contains(t, err.Error(), `sink "archive": BATCH_SIZE must not be negative`)
+	assert.NotContains(t, err.Error(), `sink "upstream"`)
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
+)
+
+// Параметры, которые применяются без перезапуска (см. Runtime)
+var reloadableKeys = map[string]bool{
+	"LOG_LEVEL":                  true,
+	"MAX_LOG_RECORDS_RESULT_WEB": true,
+	"PASSWORD_REGEX":             true,
+	"PASSWORD_REGEX_ERROR":       true,
+	"SESSION_AGE":                true,
+	"WEB_PAGE_SIZE":              true,
+}
+
+// Параметры, значения которых не выводятся в журнал
+var secretKeys = map[string]bool{
+	"SUPERADMIN_PASSWORD":    true,
+	"DATABASE_URL":           true,
+	"SESSION_ENCRYPTION_KEY": true,
+	"NOTIFY_CHANNELS":        true,
+	"FORWARD_SINKS":          true,
+}
+
+// Runtime Параметры, которые можно изменить без перезапуска сервера (SIGHUP или /api/private/reload-config)
+type Runtime struct {
+	LogLevel               string
+	MaxLogRecordsResultWeb int
+	SessionAge             int
+	WebPageSize            int
+	PasswordPolicy         model.PasswordPolicy
+}
+
+// Change Изменение параметра при перезагрузке конфигурации
+type Change struct {
+	Key string
+	Old string
+	New string
+	// Applied Изменение применено. Остальные параметры требуют перезапуска
+	Applied bool
+}
+
+func (c Change) String() string {
+	if c.Applied {
+		return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+	}
+
+	return fmt.Sprintf("%s: %s -> %s (requires restart)", c.Key, c.Old, c.New)
+}
+
+// Последняя загруженная конфигурация вместе с вычисленными из нее параметрами Runtime
+type loadedConfig struct {
+	config  *Config
+	runtime Runtime
+}
+
+func newLoadedConfig(c *Config) *loadedConfig {
+	return &loadedConfig{
+		config: c,
+		runtime: Runtime{
+			LogLevel:               c.LogLevel,
+			MaxLogRecordsResultWeb: c.MaxLogRecordsResultWeb,
+			SessionAge:             c.SessionAge,
+			WebPageSize:            c.WebPageSize,
+			PasswordPolicy: model.PasswordPolicy{
+				// регулярное выражение проверено при загрузке конфигурации
+				Regex:   regexp.MustCompile(c.PasswordRegex),
+				Message: c.PasswordRegexError,
+			},
+		},
+	}
+}
+
+// Runtime Текущие значения параметров, изменяемых без перезапуска.
+// Компоненты должны читать такие параметры через этот метод, а не из полей Config
+func (c *Config) Runtime() Runtime {
+	return c.loaded().runtime
+}
+
+// Последняя загруженная конфигурация
+func (c *Config) latest() *Config {
+	return c.loaded().config
+}
+
+func (c *Config) loaded() *loadedConfig {
+	if c.reloaded == nil {
+		return newLoadedConfig(c)
+	}
+
+	if v, ok := c.reloaded.Load().(*loadedConfig); ok {
+		return v
+	}
+
+	// параметры исходной конфигурации вычисляются при первом обращении, т.к. до запуска сервера поля Config
+	// могут меняться (например, в тестах)
+	v := newLoadedConfig(c)
+	if !c.reloaded.CompareAndSwap(nil, v) {
+		v, _ = c.reloaded.Load().(*loadedConfig)
+	}
+
+	return v
+}
+
+// Apply Применить новую конфигурацию, загруженную и проверенную через Load/LoadArgs.
+// Параметры Runtime заменяются атомарно, остальные изменения только возвращаются в списке (требуют перезапуска)
+func (c *Config) Apply(next *Config) []Change {
+	changes := diff(c.latest(), next)
+	c.reloaded.Store(newLoadedConfig(next))
+
+	return changes
+}
+
+// Reload Загрузить конфигурацию через load и применить ее. Если новая конфигурация некорректна,
+// продолжает действовать текущая. Уровень журналирования применяется сразу, изменения записываются в журнал
+func (c *Config) Reload(load func() (*Config, error)) error {
+	next, err := load()
+	if err != nil {
+		return apperr.Wrap(apperr.KindValidation, err, "configuration rejected")
+	}
+
+	changes := c.Apply(next)
+
+	if err := logger.SetLevel(c.Runtime().LogLevel); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if len(changes) == 0 {
+		logger.Logger().Infoln("configuration reloaded, no changes")
+	}
+
+	for _, change := range changes {
+		logger.Logger().Infof("configuration changed %v", change)
+	}
+
+	return nil
+}
+
+// Список изменившихся параметров
+func diff(prev, next *Config) []Change {
+	var changes []Change
+
+	nextFields := configFields(next)
+
+	for i, f := range configFields(prev) {
+		oldValue := fmt.Sprint(f.value.Interface())
+		newValue := fmt.Sprint(nextFields[i].value.Interface())
+
+		if reflect.DeepEqual(f.value.Interface(), nextFields[i].value.Interface()) {
+			continue
+		}
+
+		if secretKeys[f.key] {
+			oldValue, newValue = "***", "***"
+		}
+
+		changes = append(changes, Change{
+			Key:     f.key,
+			Old:     oldValue,
+			New:     newValue,
+			Applied: reloadableKeys[f.key],
+		})
+	}
+
+	return changes
+}
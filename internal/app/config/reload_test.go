@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Reload(t *testing.T) {
+	cfg, err := config.LoadArgs(nil, secrets, "")
+	require.NoError(t, err)
+
+	path := writeFile(t, "server.toml", `
+LOG_LEVEL = "warning"
+SESSION_AGE = 60
+PASSWORD_REGEX = "^.{8,}$"
+MAX_DB_SESSIONS = 5
+`)
+
+	require.NoError(t, cfg.Reload(func() (*config.Config, error) {
+		return config.LoadArgs(nil, secrets, path)
+	}))
+
+	runtime := cfg.Runtime()
+	assert.Equal(t, "warning", runtime.LogLevel)
+	assert.Equal(t, 60, runtime.SessionAge)
+	assert.False(t, runtime.PasswordPolicy.Regex.MatchString("123"))
+	// выражение компилируется один раз при применении конфигурации
+	assert.Same(t, runtime.PasswordPolicy.Regex, cfg.Runtime().PasswordPolicy.Regex)
+	assert.Equal(t, logrus.WarnLevel, logger.Logger().GetLevel())
+	// требует перезапуска
+	assert.Equal(t, 50, cfg.MaxDbSessions)
+
+	// некорректная конфигурация отклоняется, действует предыдущая
+	bad := writeFile(t, "bad.toml", `SESSION_AGE = -1`)
+	err = cfg.Reload(func() (*config.Config, error) {
+		return config.LoadArgs(nil, secrets, bad)
+	})
+	require.Error(t, err)
+	assert.True(t, apperr.Is(err, apperr.KindValidation))
+	assert.Equal(t, 60, cfg.Runtime().SessionAge)
+
+	logger.Logger().SetLevel(logrus.DebugLevel)
+}
+
+func TestConfig_Apply(t *testing.T) {
+	cfg := config.Default()
+	next := config.Default()
+	next.SessionAge = 10
+	next.BindAddr = "localhost:9090"
+	next.SessionEncriptionKey = "0123456789abcdef0123456789abcdef-new"
+
+	changes := cfg.Apply(next)
+	require.Len(t, changes, 3)
+
+	byKey := make(map[string]config.Change)
+	for _, c := range changes {
+		byKey[c.Key] = c
+	}
+
+	assert.True(t, byKey["SESSION_AGE"].Applied)
+	assert.False(t, byKey["BIND_ADDR"].Applied)
+	// значения секретов не выводятся
+	assert.Equal(t, "***", byKey["SESSION_ENCRYPTION_KEY"].New)
+	assert.Equal(t, 10, cfg.Runtime().SessionAge)
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvPrefix Префикс переменных окружения. Имя переменной - префикс и ключ конфига, например LOGSERVER_BIND_ADDR
+	EnvPrefix = "LOGSERVER_"
+	// Суффикс переменной окружения, содержащей путь к файлу со значением (секреты docker/kubernetes),
+	// например LOGSERVER_DATABASE_URL_FILE=/run/secrets/database_url
+	envFileSuffix = "_FILE"
+)
+
+// Параметр конфига, описанный тегом toml
+type configField struct {
+	key   string
+	value reflect.Value
+}
+
+// Параметры конфига, которые можно задать через файл, переменные окружения и флаги
+func configFields(cfg *Config) []configField {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	fields := make([]configField, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("toml")
+		if key == "" {
+			continue
+		}
+
+		fields = append(fields, configField{
+			key:   key,
+			value: v.Field(i),
+		})
+	}
+
+	return fields
+}
+
+// Имя флага командной строки для ключа конфига: BIND_ADDR -> bind-addr
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// Установка значения из строки. Словари задаются в виде "ключ=значение,ключ=значение"
+func setValue(v reflect.Value, raw string) error {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+
+		v.SetBool(b)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+
+		for _, item := range strings.Split(raw, ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+
+			k, val, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("invalid map item %q, expected key=value", item)
+			}
+
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, strings.TrimSpace(val)); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(k)), elem)
+		}
+
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+
+	return nil
+}
+
+// Значение переменной окружения из списка вида KEY=VALUE
+func lookupEnv(environ []string, name string) string {
+	value, _ := lookupEnvOK(environ, name)
+
+	return value
+}
+
+func lookupEnvOK(environ []string, name string) (string, bool) {
+	// берем последнее значение, как это делает os.Getenv
+	value, found := "", false
+
+	for _, e := range environ {
+		if k, v, ok := strings.Cut(e, "="); ok && k == name {
+			value, found = v, true
+		}
+	}
+
+	return value, found
+}
+
+// Применение переменных окружения LOGSERVER_<KEY> и LOGSERVER_<KEY>_FILE
+func applyEnv(cfg *Config, environ []string) error {
+	for _, f := range configFields(cfg) {
+		name := EnvPrefix + f.key
+
+		raw, ok := lookupEnvOK(environ, name)
+		if !ok {
+			fileName, fileOK := lookupEnvOK(environ, name+envFileSuffix)
+			if !fileOK {
+				continue
+			}
+
+			data, err := os.ReadFile(fileName)
+			if err != nil {
+				return errors.Wrapf(err, "%s%s", name, envFileSuffix)
+			}
+
+			raw, name = strings.TrimRight(string(data), "\r\n"), name+envFileSuffix
+		}
+
+		if err := setValue(f.value, raw); err != nil {
+			return errors.Wrap(err, name)
+		}
+	}
+
+	return nil
+}
+
+// Значение флага командной строки. Запоминает, был ли флаг задан явно
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.value
+}
+
+func (f *flagValue) Set(s string) error {
+	f.value = s
+	f.set = true
+
+	return nil
+}
+
+// IsBoolFlag Позволяет указывать логические флаги без значения (-tls-client-cert-required)
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// Набор флагов: -config-path и по одному флагу на каждый ключ конфига
+func newFlagSet() (fs *flag.FlagSet, configPath *string, values map[string]*flagValue) {
+	fs = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	configPath = fs.String("config-path", "", "path to config file (env "+EnvPrefix+"CONFIG_PATH)")
+	values = make(map[string]*flagValue)
+
+	for _, f := range configFields(defaults()) {
+		// списки структур задаются только в файле
+		if f.value.Kind() == reflect.Slice {
+			continue
+		}
+
+		v := &flagValue{
+			value:  "",
+			set:    false,
+			isBool: f.value.Kind() == reflect.Bool,
+		}
+		values[f.key] = v
+
+		fs.Var(v, flagName(f.key), fmt.Sprintf("overrides %s (env %s%s)", f.key, EnvPrefix, f.key))
+	}
+
+	return fs, configPath, values
+}
+
+// Применение явно заданных флагов командной строки
+func applyFlags(cfg *Config, values map[string]*flagValue) error {
+	for _, f := range configFields(cfg) {
+		v, ok := values[f.key]
+		if !ok || !v.set {
+			continue
+		}
+
+		if err := setValue(f.value, v.value); err != nil {
+			return errors.Wrap(err, "-"+flagName(f.key))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// Минимальная длина ключа шифрования сессий
+const minSessionKeyLength = 32
+
+// Ошибки проверки конфигурации
+type validationErrors []string
+
+func (e validationErrors) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e, "\n  ")
+}
+
+// Проверка конфигурации. Возвращает все найденные ошибки сразу
+func (c *Config) validate() error {
+	var errs validationErrors
+
+	add := func(key, format string, args ...interface{}) {
+		errs = append(errs, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	if host, port, err := net.SplitHostPort(c.BindAddr); err != nil {
+		add("BIND_ADDR", "expected host:port, got %q", c.BindAddr)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 || strings.Contains(host, "/") {
+		add("BIND_ADDR", "invalid address %q", c.BindAddr)
+	}
+
+	required := map[string]string{
+		"SUPERADMIN_LOGIN":    c.SuperAdminLogin,
+		"SUPERADMIN_PASSWORD": c.SuperPassword,
+		"DATABASE_URL":        c.DatabaseURL,
+	}
+	for _, key := range []string{"SUPERADMIN_LOGIN", "SUPERADMIN_PASSWORD", "DATABASE_URL"} {
+		if required[key] == "" {
+			add(key, "must be set")
+		}
+	}
+
+	if len(c.SessionEncriptionKey) < minSessionKeyLength {
+		add("SESSION_ENCRYPTION_KEY", "must be at least %d characters", minSessionKeyLength)
+	}
+
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		add("LOG_LEVEL", "unknown level %q", c.LogLevel)
+	}
+
+	if c.LogFormat != logger.FormatText && c.LogFormat != logger.FormatJSON {
+		add("LOG_FORMAT", "expected %s or %s, got %q", logger.FormatText, logger.FormatJSON, c.LogFormat)
+	}
+
+	if _, err := logrus.ParseLevel(c.SelfLogLevel); err != nil {
+		add("SELF_LOG_LEVEL", "unknown level %q", c.SelfLogLevel)
+	}
+
+	if _, err := regexp.Compile(c.PasswordRegex); err != nil {
+		add("PASSWORD_REGEX", "%v", err)
+	}
+
+	positive := []struct {
+		key   string
+		value int
+	}{
+		{"SESSION_AGE", c.SessionAge},
+		{"MAX_DB_SESSIONS", c.MaxDbSessions},
+		{"MAX_LOG_RECORDS_RESULT", c.MaxLogRecordsResult},
+		{"MAX_LOG_RECORDS_RESULT_WEB", c.MaxLogRecordsResultWeb},
+		{"SHUTDOWN_TIMEOUT_SEC", c.ShutdownTimeoutSec},
+		{"WEB_PAGE_SIZE", c.WebPageSize},
+		{"ALERT_EVAL_INTERVAL_SEC", c.AlertEvalIntervalSec},
+		{"NOTIFY_TIMEOUT_SEC", c.NotifyTimeoutSec},
+		{"MAX_DECOMPRESSED_BODY_MB", c.MaxDecompressedBodyMB},
+	}
+	for _, p := range positive {
+		if p.value <= 0 {
+			add(p.key, "must be positive, got %d", p.value)
+		}
+	}
+
+	nonNegative := []struct {
+		key   string
+		value int
+	}{
+		{"MAX_DB_SESSION_IDLE_TIME_SEC", c.MaxDbSessionIdleTimeSec},
+		{"MAX_INGEST_QUEUE", c.MaxIngestQueue},
+		{"HTTP_DRAIN_TIMEOUT_SEC", c.HTTPDrainTimeoutSec},
+		{"HTTP_READ_TIMEOUT_SEC", c.HTTPReadTimeoutSec},
+		{"HTTP_WRITE_TIMEOUT_SEC", c.HTTPWriteTimeoutSec},
+		{"HTTP_IDLE_TIMEOUT_SEC", c.HTTPIdleTimeoutSec},
+		{"QUERY_TIMEOUT_SEC", c.QueryTimeoutSec},
+		{"LOG_FILE_MAX_SIZE_MB", c.LogFileMaxSizeMB},
+		{"LOG_FILE_MAX_BACKUPS", c.LogFileMaxBackups},
+		{"LOG_FILE_MAX_AGE_DAYS", c.LogFileMaxAgeDays},
+		{"NOTIFY_RETRIES", c.NotifyRetries},
+		{"NOTIFY_RETRY_BACKOFF_MS", c.NotifyRetryBackoffMs},
+		{"MAX_EXPORT_RECORDS", c.MaxExportRecords},
+	}
+	for _, p := range nonNegative {
+		if p.value < 0 {
+			add(p.key, "must not be negative, got %d", p.value)
+		}
+	}
+
+	for route, timeout := range c.RouteQueryTimeoutSec {
+		if timeout < 0 {
+			add("ROUTE_QUERY_TIMEOUT_SEC", "timeout for route %q must not be negative, got %d", route, timeout)
+		}
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		add("TLS_CERT_FILE", "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	if c.TLSClientCAFile != "" && !c.TLSEnabled() {
+		add("TLS_CLIENT_CA_FILE", "requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	if c.TLSClientCertRequired && c.TLSClientCAFile == "" {
+		add("TLS_CLIENT_CERT_REQUIRED", "requires TLS_CLIENT_CA_FILE")
+	}
+
+	c.validateNotifyChannels(add)
+	c.validateForwardSinks(add)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Проверка каналов уведомлений. Шаблоны проверяются при создании каналов
+func (c *Config) validateNotifyChannels(add func(key, format string, args ...interface{})) {
+	const key = "NOTIFY_CHANNELS"
+
+	names := make(map[string]bool, len(c.NotifyChannels))
+
+	for i, ch := range c.NotifyChannels {
+		if ch.Name == "" {
+			add(key, "channel %d: NAME must be set", i+1)
+		} else if names[ch.Name] {
+			add(key, "channel %q: duplicate name", ch.Name)
+		}
+
+		names[ch.Name] = true
+
+		switch ch.Type {
+		case NotifyWebhook, NotifyHTTP:
+			if u, err := url.Parse(ch.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				add(key, "channel %q: URL must be an http(s) address, got %q", ch.Name, ch.URL)
+			}
+
+			if ch.Type == NotifyHTTP && ch.Template == "" {
+				add(key, "channel %q: TEMPLATE must be set", ch.Name)
+			}
+		case NotifyEmail:
+			if _, _, err := net.SplitHostPort(ch.SMTPAddr); err != nil {
+				add(key, "channel %q: SMTP_ADDR expected host:port, got %q", ch.Name, ch.SMTPAddr)
+			}
+
+			if ch.From == "" || len(ch.To) == 0 {
+				add(key, "channel %q: FROM and TO must be set", ch.Name)
+			}
+		default:
+			add(key, "channel %q: TYPE expected %s, %s or %s, got %q",
+				ch.Name, NotifyWebhook, NotifyEmail, NotifyHTTP, ch.Type)
+		}
+	}
+}
+
+// Проверка получателей пересылаемых записей
+func (c *Config) validateForwardSinks(add func(key, format string, args ...interface{})) {
+	const key = "FORWARD_SINKS"
+
+	names := make(map[string]bool, len(c.ForwardSinks))
+
+	for i, sink := range c.ForwardSinks {
+		if sink.Name == "" {
+			add(key, "sink %d: NAME must be set", i+1)
+		} else if names[sink.Name] {
+			add(key, "sink %q: duplicate name", sink.Name)
+		}
+
+		names[sink.Name] = true
+
+		switch sink.Type {
+		case ForwardLogServer, ForwardHTTP, ForwardKafkaREST:
+			if u, err := url.Parse(sink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				add(key, "sink %q: URL must be an http(s) address, got %q", sink.Name, sink.URL)
+			}
+		case ForwardFile:
+			if sink.Path == "" {
+				add(key, "sink %q: PATH must be set", sink.Name)
+			}
+		default:
+			add(key, "sink %q: TYPE expected %s, %s, %s or %s, got %q",
+				sink.Name, ForwardLogServer, ForwardHTTP, ForwardKafkaREST, ForwardFile, sink.Type)
+		}
+
+		if _, err := regexp.Compile(sink.Pattern); err != nil {
+			add(key, "sink %q: PATTERN %v", sink.Name, err)
+		}
+
+		for _, p := range []struct {
+			name  string
+			value int
+		}{
+			{"BUFFER_SIZE", sink.BufferSize},
+			{"BATCH_SIZE", sink.BatchSize},
+			{"FLUSH_INTERVAL_MS", sink.FlushIntervalMs},
+			{"RETRIES", sink.Retries},
+			{"RETRY_BACKOFF_MS", sink.RetryBackoffMs},
+			{"TIMEOUT_SEC", sink.TimeoutSec},
+		} {
+			if p.value < 0 {
+				add(key, "sink %q: %s must not be negative, got %d", sink.Name, p.name, p.value)
+			}
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package forward Пересылка добавленных в журнал записей другим получателям: другому экземпляру logserver,
+// HTTP сервису, в Kafka через REST прокси или в файл. Каждый получатель имеет свой фильтр, буфер и повторные попытки
+package forward
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/app/metrics"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
+)
+
+// Значения по умолчанию для незаданных параметров получателя
+const (
+	defaultBufferSize      = 10000
+	defaultBatchSize       = 500
+	defaultFlushIntervalMs = 1000
+	defaultRetries         = 5
+	defaultRetryBackoffMs  = 1000
+	defaultTimeoutSec      = 10
+	// максимальная пауза между попытками
+	maxBackoff = time.Minute
+)
+
+// Отправка пачки записей получателю
+type output interface {
+	write(ctx context.Context, records []model.LogRecord) error
+}
+
+// Sink Получатель пересылаемых записей. Является usecase.LogObserver: подходящие записи ставятся в буфер
+// без блокировки и отправляются пачками в отдельной горутине. Является компонентом app.Lifecycle:
+// при остановке отправляет накопленные записи без повторных попыток
+type Sink struct {
+	name    string
+	output  output
+	filter  filter
+	records chan model.LogRecord
+
+	batchSize     int
+	flushInterval time.Duration
+	retries       int
+	backoff       time.Duration
+	timeout       time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Фильтр записей
+type filter struct {
+	minLevel uint
+	pattern  *regexp.Regexp
+	sources  map[string]bool
+}
+
+// New Создание получателей по конфигурации
+func New(sinks []config.ForwardSink) ([]*Sink, error) {
+	result := make([]*Sink, 0, len(sinks))
+
+	for _, cfg := range sinks {
+		sink, err := newSink(cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "forward sink %s", cfg.Name)
+		}
+
+		result = append(result, sink)
+	}
+
+	return result, nil
+}
+
+func newSink(cfg config.ForwardSink) (*Sink, error) {
+	var out output
+
+	switch cfg.Type {
+	case config.ForwardLogServer:
+		out = newLogServer(cfg.URL, cfg.Token, cfg.Headers)
+	case config.ForwardHTTP:
+		out = &httpOutput{url: cfg.URL, headers: cfg.Headers}
+	case config.ForwardKafkaREST:
+		out = &kafkaREST{url: cfg.URL, headers: cfg.Headers}
+	case config.ForwardFile:
+		out = &fileOutput{path: cfg.Path}
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+
+	f := filter{
+		minLevel: cfg.MinLevel,
+		pattern:  nil,
+		sources:  nil,
+	}
+
+	if cfg.Pattern != "" {
+		pattern, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "pattern")
+		}
+
+		f.pattern = pattern
+	}
+
+	if len(cfg.Sources) > 0 {
+		f.sources = make(map[string]bool, len(cfg.Sources))
+		for _, source := range cfg.Sources {
+			f.sources[source] = true
+		}
+	}
+
+	return &Sink{
+		name:          cfg.Name,
+		output:        out,
+		filter:        f,
+		records:       make(chan model.LogRecord, orDefault(cfg.BufferSize, defaultBufferSize)),
+		batchSize:     orDefault(cfg.BatchSize, defaultBatchSize),
+		flushInterval: time.Duration(orDefault(cfg.FlushIntervalMs, defaultFlushIntervalMs)) * time.Millisecond,
+		retries:       orDefault(cfg.Retries, defaultRetries),
+		backoff:       time.Duration(orDefault(cfg.RetryBackoffMs, defaultRetryBackoffMs)) * time.Millisecond,
+		timeout:       time.Duration(orDefault(cfg.TimeoutSec, defaultTimeoutSec)) * time.Second,
+		stopOnce:      sync.Once{},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}, nil
+}
+
+func orDefault(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+
+	return value
+}
+
+// Подходит ли запись под фильтр
+func (f *filter) matches(record *model.LogRecord) bool {
+	if record.Level < f.minLevel {
+		return false
+	}
+
+	if f.sources != nil && !f.sources[record.Message2] {
+		return false
+	}
+
+	if f.pattern == nil {
+		return true
+	}
+
+	return f.pattern.MatchString(record.Message1) || f.pattern.MatchString(record.Message2) ||
+		f.pattern.MatchString(record.Message3)
+}
+
+// Observe Поставить подходящие записи в буфер. Не блокируется: при заполнении буфера записи отбрасываются
+func (s *Sink) Observe(records []model.LogRecord) {
+	dropped := 0
+
+	for i := range records {
+		if !s.filter.matches(&records[i]) {
+			continue
+		}
+
+		record := records[i]
+		// получатель назначает свой ID
+		record.ID = 0
+
+		select {
+		case s.records <- record:
+		default:
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		metrics.ForwardRecords.WithLabelValues(s.name, metrics.ForwardDropped).Add(float64(dropped))
+	}
+
+	metrics.ForwardQueue.WithLabelValues(s.name).Set(float64(len(s.records)))
+}
+
+// Name Имя компонента (для app.Lifecycle)
+func (s *Sink) Name() string {
+	return "forward " + s.name
+}
+
+// Start Запуск отправки в отдельной горутине
+func (s *Sink) Start(func(err error)) error {
+	go s.run()
+
+	return nil
+}
+
+// Stop Остановка с отправкой накопленных записей
+func (s *Sink) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "forward %s flush", s.name)
+	}
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.LogRecord, 0, s.batchSize)
+
+	for {
+		select {
+		case record := <-s.records:
+			batch = append(batch, record)
+			if len(batch) >= s.batchSize {
+				batch = s.flush(batch, s.retries)
+			}
+		case <-ticker.C:
+			batch = s.flush(batch, s.retries)
+		case <-s.stop:
+			// забираем все, что успели поставить в очередь
+			for {
+				select {
+				case record := <-s.records:
+					batch = append(batch, record)
+					if len(batch) >= s.batchSize {
+						batch = s.flush(batch, 0)
+					}
+				default:
+					s.flush(batch, 0)
+
+					return
+				}
+			}
+		}
+	}
+}
+
+// Отправка пачки с повторными попытками. Возвращает пустой буфер для следующей пачки.
+// Пауза между попытками прерывается остановкой, после чего пачка отправляется последний раз
+func (s *Sink) flush(batch []model.LogRecord, retries int) []model.LogRecord {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	defer metrics.ForwardQueue.WithLabelValues(s.name).Set(float64(len(s.records)))
+
+	backoff := s.backoff
+
+	var err error
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-s.stop:
+				retries = attempt
+			}
+
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		if err = s.write(batch); err == nil {
+			metrics.ForwardRecords.WithLabelValues(s.name, metrics.ForwardSent).Add(float64(len(batch)))
+
+			return make([]model.LogRecord, 0, s.batchSize)
+		}
+	}
+
+	metrics.ForwardRecords.WithLabelValues(s.name, metrics.ForwardFailed).Add(float64(len(batch)))
+	logger.Logger().WithError(err).Warnf("forward %s: %d records lost", s.name, len(batch))
+
+	return make([]model.LogRecord, 0, s.batchSize)
+}
+
+// Одна попытка отправки с таймаутом
+func (s *Sink) write(batch []model.LogRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	return s.output.write(ctx, batch)
+}
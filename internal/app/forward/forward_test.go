@@ -0,0 +1,138 @@
+package forward_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/forward"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecords() []model.LogRecord {
+	now := time.Date(2022, 4, 23, 18, 25, 43, 0, time.UTC)
+
+	return []model.LogRecord{
+		{ID: 1, LogTime: now, RealTime: now, Level: 2, Message1: "started", Message2: "billing", Message3: ""},
+		{ID: 2, LogTime: now, RealTime: now, Level: 4, Message1: "failed", Message2: "billing", Message3: ""},
+		{ID: 3, LogTime: now, RealTime: now, Level: 5, Message1: "panic", Message2: "auth", Message3: ""},
+	}
+}
+
+// Сервер, запоминающий запросы
+type receiver struct {
+	mutex    sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	fail     int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if rc.fail > 0 {
+		rc.fail--
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		return
+	}
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, string(data))
+}
+
+func (rc *receiver) received() []string {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	return append([]string(nil), rc.bodies...)
+}
+
+func TestSinks(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "forward.ndjson")
+
+	sinks, err := forward.New([]config.ForwardSink{
+		{Name: "upstream", Type: config.ForwardLogServer, URL: srv.URL + "/", Token: "token", MinLevel: 4},
+		{Name: "kafka", Type: config.ForwardKafkaREST, URL: srv.URL + "/topics/logs", Pattern: "^pan"},
+		{Name: "file", Type: config.ForwardFile, Path: path, Sources: []string{"billing"}},
+	})
+	require.NoError(t, err)
+
+	for _, sink := range sinks {
+		require.NoError(t, sink.Start(nil))
+		sink.Observe(testRecords())
+	}
+
+	// при остановке накопленные записи отправляются
+	for _, sink := range sinks {
+		require.NoError(t, sink.Stop(context.Background()))
+	}
+
+	require.Len(t, rc.requests, 2)
+
+	bodies := map[string]string{}
+	for i, r := range rc.requests {
+		bodies[r.URL.Path] = rc.bodies[i]
+	}
+
+	var records []model.LogRecord
+	require.NoError(t, json.Unmarshal([]byte(bodies["/api/private/add-log"]), &records))
+	require.Len(t, records, 2)
+	assert.Equal(t, "failed", records[0].Message1)
+	assert.Zero(t, records[0].ID)
+
+	for _, r := range rc.requests {
+		if r.URL.Path == "/api/private/add-log" {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		}
+	}
+
+	assert.Contains(t, bodies["/topics/logs"], `{"records":[{"key":"auth","value":{"id":0,`)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"message1":"started"`)
+}
+
+func TestSinkRetry(t *testing.T) {
+	rc := &receiver{fail: 2}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	sinks, err := forward.New([]config.ForwardSink{{
+		Name: "http", Type: config.ForwardHTTP, URL: srv.URL, FlushIntervalMs: 10, RetryBackoffMs: 1,
+	}})
+	require.NoError(t, err)
+	require.NoError(t, sinks[0].Start(nil))
+
+	defer func() { assert.NoError(t, sinks[0].Stop(context.Background())) }()
+
+	sinks[0].Observe(testRecords())
+
+	require.Eventually(t, func() bool { return len(rc.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	var records []model.LogRecord
+	require.NoError(t, json.Unmarshal([]byte(rc.received()[0]), &records))
+	assert.Len(t, records, 3)
+}
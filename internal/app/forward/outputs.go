@@ -0,0 +1,109 @@
+package forward
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/n-r-w/log-server/internal/app/httpout"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
+)
+
+// JSON массив записей методом POST
+type httpOutput struct {
+	url     string
+	headers map[string]string
+}
+
+func (h *httpOutput) write(ctx context.Context, records []model.LogRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return errors.Wrap(err, "json")
+	}
+
+	return httpout.Post(ctx, h.url, "application/json", h.headers, body)
+}
+
+// Другой экземпляр logserver. Аутентификация по API токену
+func newLogServer(url, token string, headers map[string]string) *httpOutput {
+	h := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		h[k] = v
+	}
+
+	if token != "" {
+		h["Authorization"] = "Bearer " + token
+	}
+
+	return &httpOutput{
+		url:     strings.TrimRight(url, "/") + "/api/private/add-log",
+		headers: h,
+	}
+}
+
+// Топик Kafka через REST прокси. Ключ сообщения - источник записи (message2)
+type kafkaREST struct {
+	url     string
+	headers map[string]string
+}
+
+func (k *kafkaREST) write(ctx context.Context, records []model.LogRecord) error {
+	type message struct {
+		Key   *string         `json:"key,omitempty"`
+		Value model.LogRecord `json:"value"`
+	}
+
+	messages := make([]message, 0, len(records))
+
+	for i := range records {
+		m := message{
+			Key:   nil,
+			Value: records[i],
+		}
+
+		if records[i].Message2 != "" {
+			m.Key = &records[i].Message2
+		}
+
+		messages = append(messages, m)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"records": messages})
+	if err != nil {
+		return errors.Wrap(err, "json")
+	}
+
+	return httpout.Post(ctx, k.url, "application/vnd.kafka.json.v2+json", k.headers, body)
+}
+
+// Файл NDJSON. Файл открывается на каждую пачку, поэтому его можно ротировать внешними средствами
+type fileOutput struct {
+	path string
+}
+
+func (f *fileOutput) write(_ context.Context, records []model.LogRecord) error {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	for i := range records {
+		if err := enc.Encode(&records[i]); err != nil {
+			return errors.Wrap(err, "json")
+		}
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640) //nolint:gomnd
+	if err != nil {
+		return errors.Wrap(err, "file")
+	}
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+
+		return errors.Wrap(err, "file")
+	}
+
+	return errors.Wrap(file.Close(), "file")
+}
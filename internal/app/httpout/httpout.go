@@ -0,0 +1,46 @@
+// Package httpout Отправка исходящих HTTP запросов во внешние системы (каналы уведомлений, пересылка записей)
+package httpout
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// максимальный размер ответа получателя, включаемый в текст ошибки
+const maxErrorBody = 512
+
+// Post Отправка POST запроса. Успешным считается ответ 2xx, иначе в ошибку включается начало ответа получателя
+func Post(ctx context.Context, url, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "request")
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "logserver")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package httpout_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/app/httpout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPost(t *testing.T) {
+	var got *http.Request
+
+	var body string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(" upstream down\n" + strings.Repeat("x", 1024)))
+		}
+	}))
+	defer srv.Close()
+
+	require.NoError(t, httpout.Post(context.Background(), srv.URL+"/ok", "application/json",
+		map[string]string{"X-Token": "secret"}, []byte(`{"a":1}`)))
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "secret", got.Header.Get("X-Token"))
+	assert.Equal(t, "logserver", got.Header.Get("User-Agent"))
+	assert.Equal(t, `{"a":1}`, body)
+
+	// в ошибку попадает статус и только начало ответа
+	err := httpout.Post(context.Background(), srv.URL+"/fail", "text/plain", nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 502: upstream down")
+	assert.Less(t, len(err.Error()), 600)
+}
@@ -0,0 +1,194 @@
+// Package importer Загрузка исторических записей журнала из файлов NDJSON, CSV или protobuf с префиксом длины.
+// Записи проверяются по одной, некорректные пропускаются с указанием номера строки, остальные записываются
+// большими пачками. После каждой пачки сообщается номер последней обработанной строки, с которой можно
+// продолжить прерванную загрузку
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
+)
+
+// Format Формат файла
+type Format string
+
+// Поддерживаемые форматы. Совпадают с форматами выгрузки /api/private/export
+const (
+	FormatNDJSON         Format = "ndjson"
+	FormatCSV            Format = "csv"
+	FormatProtoDelimited Format = "proto-delimited"
+)
+
+// DefaultBatchSize Количество записей в пачке по умолчанию
+const DefaultBatchSize = 5000
+
+var (
+	errUnknownFormat  = errors.New("format must be ndjson, csv or proto-delimited")
+	errReservedSource = errors.New("message2: source is reserved for server records")
+)
+
+// ParseFormat Формат по названию
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatNDJSON, FormatCSV, FormatProtoDelimited:
+		return f, nil
+	default:
+		return "", errUnknownFormat
+	}
+}
+
+// DetectFormat Формат по расширению файла: .ndjson, .jsonl, .csv, .pb
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".pb":
+		return FormatProtoDelimited, nil
+	default:
+		return "", errors.Wrapf(errUnknownFormat, "cannot detect format of %s", path)
+	}
+}
+
+// InsertFunc Запись пачки проверенных записей (usecase.LogInterface.Import)
+type InsertFunc func(ctx context.Context, records *[]model.LogRecord) error
+
+// Options Параметры загрузки
+type Options struct {
+	Format Format
+	// Location Часовой пояс времени без указания пояса (CSV). По умолчанию UTC
+	Location *time.Location
+	// BatchSize Количество записей в пачке. По умолчанию DefaultBatchSize
+	BatchSize int
+	// Skip Пропустить строки до Skip включительно: номер строки из Progress прерванной загрузки
+	Skip int
+	// Progress Вызывается после записи каждой пачки
+	Progress func(Progress)
+	// Error Вызывается для каждой некорректной записи
+	Error func(LineError)
+}
+
+// Progress Состояние загрузки. Строки до Line включительно обработаны: записаны или отклонены
+type Progress struct {
+	Line     int `json:"line"`
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+}
+
+// LineError Ошибка в записи. Line - номер строки (для proto-delimited - номер сообщения)
+type LineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Run Загрузка записей из r. Возвращает состояние на момент окончания или прерывания загрузки:
+// при ошибке чтения файла (apperr.KindValidation) или записи пачки Progress.Line указывает, после какой строки продолжить
+func Run(ctx context.Context, r io.Reader, insert InsertFunc, opts Options) (Progress, error) {
+	progress := Progress{
+		Line:     opts.Skip,
+		Imported: 0,
+		Failed:   0,
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	rd, err := newReader(r, opts.Format, loc)
+	if err != nil {
+		return progress, apperr.Wrap(apperr.KindValidation, err, "bad file")
+	}
+
+	batch := make([]model.LogRecord, 0, batchSize)
+	// строка последней прочитанной записи и количество отклоненных записей после последней пачки
+	last := opts.Skip
+	failed := 0
+
+	flush := func() error {
+		if len(batch) > 0 {
+			if err := insert(ctx, &batch); err != nil {
+				return err
+			}
+		}
+
+		progress.Imported += len(batch)
+		progress.Failed += failed
+		progress.Line = last
+		batch = batch[:0]
+		failed = 0
+
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+
+		return nil
+	}
+
+	for {
+		record, line, err := rd.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var recErr *recordError
+		if err != nil && !errors.As(err, &recErr) {
+			return progress, apperr.Wrap(apperr.KindValidation, err, fmt.Sprintf("bad file at line %d", line))
+		}
+
+		if line <= opts.Skip {
+			continue
+		}
+
+		last = line
+
+		if err == nil {
+			err = validate(&record)
+		}
+
+		if err != nil {
+			failed++
+
+			if opts.Error != nil {
+				opts.Error(LineError{Line: line, Error: err.Error()})
+			}
+
+			continue
+		}
+
+		if batch = append(batch, record); len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return progress, err
+			}
+		}
+	}
+
+	return progress, flush()
+}
+
+// Проверка записи так же, как при добавлении через API
+func validate(record *model.LogRecord) error {
+	if err := record.Validate(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if record.Message2 == model.SelfLogSource {
+		return errReservedSource
+	}
+
+	return nil
+}
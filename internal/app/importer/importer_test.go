@@ -0,0 +1,127 @@
+package importer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/app/importer"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Загрузка в память с записью пачек
+type memory struct {
+	batches [][]model.LogRecord
+}
+
+func (m *memory) insert(_ context.Context, records *[]model.LogRecord) error {
+	m.batches = append(m.batches, append([]model.LogRecord(nil), *records...))
+
+	return nil
+}
+
+func (m *memory) records() []model.LogRecord {
+	var res []model.LogRecord
+	for _, b := range m.batches {
+		res = append(res, b...)
+	}
+
+	return res
+}
+
+func run(t *testing.T, format importer.Format, data string, skip int) (*memory, importer.Progress, []importer.LineError) {
+	t.Helper()
+
+	var (
+		mem    memory
+		errs   []importer.LineError
+		states []importer.Progress
+	)
+
+	progress, err := importer.Run(context.Background(), strings.NewReader(data), mem.insert, importer.Options{
+		Format:    format,
+		Location:  time.UTC,
+		BatchSize: 2,
+		Skip:      skip,
+		Progress:  func(p importer.Progress) { states = append(states, p) },
+		Error:     func(e importer.LineError) { errs = append(errs, e) },
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, states)
+	assert.Equal(t, progress, states[len(states)-1])
+
+	return &mem, progress, errs
+}
+
+func TestNDJSON(t *testing.T) {
+	data := `{"logTime": "2022-04-23T10:00:00Z", "level": 2, "message1": "first"}
+not json
+
+{"logTime": "2022-04-23T10:01:00Z", "level": 2, "message1": ""}
+{"logTime": "2022-04-23T10:02:00Z", "level": 4, "message1": "second", "message2": "billing"}
+{"logTime": "2022-04-23T10:03:00Z", "level": 4, "message1": "self", "message2": "logserver"}
+{"logTime": "2022-04-23T10:04:00Z", "level": 3, "message1": "third"}`
+
+	mem, progress, errs := run(t, importer.FormatNDJSON, data, 0)
+
+	assert.Equal(t, importer.Progress{Line: 7, Imported: 3, Failed: 3}, progress)
+	assert.Len(t, mem.batches, 2)
+
+	records := mem.records()
+	require.Len(t, records, 3)
+	assert.Equal(t, "billing", records[1].Message2)
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Equal(t, 4, errs[1].Line)
+	assert.Equal(t, 6, errs[2].Line)
+
+	// продолжение после записанной пачки
+	mem, progress, _ = run(t, importer.FormatNDJSON, data, 5)
+	assert.Equal(t, importer.Progress{Line: 7, Imported: 1, Failed: 1}, progress)
+	assert.Equal(t, "third", mem.records()[0].Message1)
+}
+
+func TestCSV(t *testing.T) {
+	data := `level,logTime,message1,message3
+2,2022-04-23T10:00:00+03:00,first,"multi
+line"
+x,2022-04-23 10:00:00,bad level,
+3,2022-04-23 10:00:00,'=1+2,'-
+4,2022-04-23 10:00:00,''=quoted,
+`
+
+	mem, progress, errs := run(t, importer.FormatCSV, data, 0)
+
+	assert.Equal(t, importer.Progress{Line: 6, Imported: 3, Failed: 1}, progress)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 4, errs[0].Line)
+
+	records := mem.records()
+	require.Len(t, records, 3)
+	assert.Equal(t, "multi\nline", records[0].Message3)
+	// формулы, экранированные при выгрузке
+	assert.Equal(t, "=1+2", records[1].Message1)
+	assert.Equal(t, "-", records[1].Message3)
+	// экранированный апостроф
+	assert.Equal(t, "'=quoted", records[2].Message1)
+	assert.True(t, records[0].LogTime.Equal(time.Date(2022, 4, 23, 7, 0, 0, 0, time.UTC)))
+	assert.True(t, records[1].LogTime.Equal(time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)))
+
+	_, err := importer.Run(context.Background(), strings.NewReader("time,text\n"), (&memory{}).insert,
+		importer.Options{Format: importer.FormatCSV}) //nolint:exhaustivestruct,exhaustruct
+	assert.True(t, apperr.Is(err, apperr.KindValidation))
+}
+
+func TestDetectFormat(t *testing.T) {
+	f, err := importer.DetectFormat("/tmp/old.JSONL")
+	require.NoError(t, err)
+	assert.Equal(t, importer.FormatNDJSON, f)
+
+	_, err = importer.DetectFormat("old.log")
+	assert.Error(t, err)
+}
@@ -0,0 +1,248 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	schemalog "github.com/n-r-w/log-server/api/schema/schema.log"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/tool"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// максимальный размер одного сообщения proto-delimited
+const maxMessageSize = 64 << 20
+
+// Чтение записей файла
+type reader interface {
+	// next Следующая запись и номер ее строки. В конце файла возвращает io.EOF.
+	// Ошибка типа *recordError относится к одной записи, чтение можно продолжить, остальные ошибки прерывают загрузку
+	next() (record model.LogRecord, line int, err error)
+}
+
+// Ошибка разбора одной записи
+type recordError struct {
+	err error
+}
+
+func (e *recordError) Error() string {
+	return e.err.Error()
+}
+
+// Создание читателя файла в формате format. Время без часового пояса считается временем в loc
+func newReader(r io.Reader, format Format, loc *time.Location) (reader, error) {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonReader{r: bufio.NewReader(r), line: 0}, nil
+	case FormatCSV:
+		return newCSVReader(r, loc)
+	case FormatProtoDelimited:
+		return &protoReader{r: bufio.NewReader(r), line: 0}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// JSON запись в каждой строке. Пустые строки пропускаются
+type ndjsonReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (n *ndjsonReader) next() (model.LogRecord, int, error) {
+	for {
+		data, err := n.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return model.LogRecord{}, n.line, errors.Wrap(err, "read")
+		}
+
+		if len(data) == 0 && err != nil {
+			return model.LogRecord{}, n.line, io.EOF
+		}
+
+		n.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var record model.LogRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return model.LogRecord{}, n.line, &recordError{err: errors.Wrap(err, "json")}
+		}
+
+		return record, n.line, nil
+	}
+}
+
+// Колонки CSV в том виде, в котором их выгружает /api/private/export
+const (
+	columnID       = "id"
+	columnLogTime  = "logtime"
+	columnRealTime = "realtime"
+	columnLevel    = "level"
+	columnMessage1 = "message1"
+	columnMessage2 = "message2"
+	columnMessage3 = "message3"
+)
+
+// CSV с заголовком. Порядок колонок произвольный, колонки id и realTime игнорируются
+type csvReader struct {
+	r       *csv.Reader
+	loc     *time.Location
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader, loc *time.Location) (*csvReader, error) {
+	c := &csvReader{
+		r:       csv.NewReader(r),
+		loc:     loc,
+		columns: map[string]int{},
+	}
+	c.r.ReuseRecord = true
+
+	header, err := c.r.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "csv header")
+	}
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		switch name {
+		case columnID, columnLogTime, columnRealTime, columnLevel, columnMessage1, columnMessage2, columnMessage3:
+			c.columns[name] = i
+		default:
+			return nil, fmt.Errorf("csv header: unknown column %q", name)
+		}
+	}
+
+	for _, name := range []string{columnLogTime, columnLevel, columnMessage1} {
+		if _, ok := c.columns[name]; !ok {
+			return nil, fmt.Errorf("csv header: column %q is required", name)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *csvReader) next() (model.LogRecord, int, error) {
+	fields, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return model.LogRecord{}, parseErr.StartLine, &recordError{err: err}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return model.LogRecord{}, 0, io.EOF
+		}
+
+		return model.LogRecord{}, 0, errors.Wrap(err, "read")
+	}
+
+	line, _ := c.r.FieldPos(0)
+
+	// формулы и значения с апострофом в начале в выгрузке экранированы апострофом (tool.EscapeCSVFormula)
+	value := func(column string) string {
+		if i, ok := c.columns[column]; ok {
+			return tool.UnescapeCSVFormula(fields[i])
+		}
+
+		return ""
+	}
+
+	record := model.LogRecord{
+		ID:       0,
+		LogTime:  time.Time{},
+		RealTime: time.Time{},
+		Level:    0,
+		Message1: value(columnMessage1),
+		Message2: value(columnMessage2),
+		Message3: value(columnMessage3),
+	}
+
+	if record.LogTime, err = parseTime(value(columnLogTime), c.loc); err != nil {
+		return model.LogRecord{}, line, &recordError{err: errors.Wrap(err, "logTime")}
+	}
+
+	level, err := strconv.ParseUint(value(columnLevel), 10, 32)
+	if err != nil {
+		return model.LogRecord{}, line, &recordError{err: errors.Wrap(err, "level")}
+	}
+
+	record.Level = uint(level)
+
+	return record, line, nil
+}
+
+// Время в RFC3339 или без часового пояса
+func parseTime(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse time %q", v)
+}
+
+// Сообщения schemalog.LogRecord с префиксом длины (varint). Номер строки - номер сообщения
+type protoReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (p *protoReader) next() (model.LogRecord, int, error) {
+	// io.EOF только если файл закончился перед префиксом длины
+	size, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return model.LogRecord{}, p.line, err //nolint:wrapcheck
+	}
+
+	p.line++
+
+	if size > maxMessageSize {
+		return model.LogRecord{}, p.line, fmt.Errorf("message size %d exceeds %d", size, maxMessageSize)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return model.LogRecord{}, p.line, errors.Wrap(err, "read")
+	}
+
+	var msg schemalog.LogRecord
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return model.LogRecord{}, p.line, &recordError{err: errors.Wrap(err, "protobuf")}
+	}
+
+	record := model.LogRecord{
+		ID:       0,
+		LogTime:  time.Time{},
+		RealTime: time.Time{},
+		Level:    uint(msg.Level),
+		Message1: msg.Message1,
+		Message2: msg.Message2,
+		Message3: msg.Message3,
+	}
+
+	if msg.LogTime != nil {
+		record.LogTime = msg.LogTime.AsTime()
+	}
+
+	return record, p.line, nil
+}
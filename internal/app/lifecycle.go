@@ -0,0 +1,231 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/logger"
+)
+
+// Component Компонент приложения, запуском и остановкой которого управляет Lifecycle
+// (HTTP сервер, фоновые задачи, пул соединений с БД и т.п.)
+type Component interface {
+	// Name Имя компонента для журнала
+	Name() string
+	// Start Запуск компонента. Не должен блокироваться: длительная работа выполняется в отдельной горутине.
+	// Если компонент аварийно завершил работу после запуска, он должен вызвать fail,
+	// что приведет к остановке всего приложения
+	Start(fail func(err error)) error
+	// Stop Остановка компонента. Должна завершиться до истечения контекста
+	Stop(ctx context.Context) error
+}
+
+// Lifecycle Управление жизненным циклом приложения. Компоненты запускаются в порядке добавления,
+// а останавливаются в обратном порядке по сигналу SIGINT/SIGTERM или при аварии одного из компонентов.
+// По сигналу SIGHUP вызываются обработчики перезагрузки (сертификаты, конфигурация и т.п.)
+type Lifecycle struct {
+	components      []lifecycleEntry
+	reloaders       []reloadEntry
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+
+	failOnce sync.Once
+	failc    chan error
+}
+
+type lifecycleEntry struct {
+	component Component
+	// время на остановку компонента (завершение обработки текущих запросов и т.п.)
+	drainTimeout time.Duration
+}
+
+type reloadEntry struct {
+	name   string
+	reload func() error
+}
+
+// NewLifecycle Создание менеджера жизненного цикла. shutdownTimeout - общее время на остановку всех компонентов
+func NewLifecycle(shutdownTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{
+		components:      nil,
+		reloaders:       nil,
+		shutdownTimeout: shutdownTimeout,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		failOnce:        sync.Once{},
+		failc:           make(chan error, 1),
+	}
+}
+
+// Add Добавить компонент. drainTimeout - время на его остановку (0 - ограничено только общим временем остановки)
+func (l *Lifecycle) Add(component Component, drainTimeout time.Duration) {
+	l.components = append(l.components, lifecycleEntry{
+		component:    component,
+		drainTimeout: drainTimeout,
+	})
+}
+
+// OnReload Добавить обработчик перезагрузки по SIGHUP. Ошибка перезагрузки журналируется,
+// но не останавливает приложение
+func (l *Lifecycle) OnReload(name string, reload func() error) {
+	l.reloaders = append(l.reloaders, reloadEntry{
+		name:   name,
+		reload: reload,
+	})
+}
+
+// Reload Вызвать все обработчики перезагрузки. Возвращает первую ошибку
+func (l *Lifecycle) Reload() error {
+	var firstErr error
+
+	for _, r := range l.reloaders {
+		if err := r.reload(); err != nil {
+			logger.Logger().Errorf("reload %s: %v", r.name, err)
+
+			if firstErr == nil {
+				firstErr = fmt.Errorf("reload %s: %w", r.name, err)
+			}
+
+			continue
+		}
+
+		logger.Logger().Infof("reloaded %s", r.name)
+	}
+
+	return firstErr
+}
+
+// Fail Сообщить об аварии. Приводит к остановке приложения. Учитывается только первая ошибка
+func (l *Lifecycle) Fail(err error) {
+	l.failOnce.Do(func() {
+		l.failc <- err
+	})
+}
+
+// Run Запуск всех компонентов и ожидание сигнала завершения, аварии компонента или отмены ctx.
+// После этого компоненты останавливаются в обратном порядке. Возвращает причину аварии или ошибку остановки
+func (l *Lifecycle) Run(ctx context.Context) error {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, l.signals...)
+	defer signal.Stop(sigc)
+
+	hupc := make(chan os.Signal, 1)
+	signal.Notify(hupc, syscall.SIGHUP)
+	defer signal.Stop(hupc)
+
+	// запуск
+	for i, e := range l.components {
+		logger.Logger().Infof("starting %s", e.component.Name())
+
+		if err := e.component.Start(l.Fail); err != nil {
+			err = fmt.Errorf("start %s: %w", e.component.Name(), err)
+			// останавливаем то, что уже успели запустить
+			if stopErr := l.stop(l.components[:i]); stopErr != nil {
+				logger.Logger().Errorln(stopErr)
+			}
+
+			return err
+		}
+	}
+
+	// ожидание
+	var runErr error
+
+	for done := false; !done; {
+		select {
+		case <-hupc:
+			logger.Logger().Infoln("received SIGHUP, reloading")
+
+			_ = l.Reload()
+		case sig := <-sigc:
+			logger.Logger().Infof("received signal %v, shutting down", sig)
+
+			done = true
+		case runErr = <-l.failc:
+			logger.Logger().Errorf("component failure, shutting down: %v", runErr)
+
+			done = true
+		case <-ctx.Done():
+			logger.Logger().Infoln("shutting down")
+
+			done = true
+		}
+	}
+
+	stopErr := l.stop(l.components)
+	if runErr != nil {
+		return runErr
+	}
+
+	return stopErr
+}
+
+// Остановка компонентов в обратном порядке
+func (l *Lifecycle) stop(components []lifecycleEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	var firstErr error
+
+	for i := len(components) - 1; i >= 0; i-- {
+		e := components[i]
+
+		stopCtx, stopCancel := ctx, context.CancelFunc(func() {})
+		if e.drainTimeout > 0 {
+			stopCtx, stopCancel = context.WithTimeout(ctx, e.drainTimeout)
+		}
+
+		logger.Logger().Infof("stopping %s", e.component.Name())
+
+		if err := e.component.Stop(stopCtx); err != nil {
+			logger.Logger().Errorf("stop %s: %v", e.component.Name(), err)
+
+			if firstErr == nil {
+				firstErr = fmt.Errorf("stop %s: %w", e.component.Name(), err)
+			}
+		}
+
+		stopCancel()
+	}
+
+	return firstErr
+}
+
+// FuncComponent Компонент на основе функций. start и stop могут быть nil
+func FuncComponent(name string, start func(fail func(err error)) error, stop func(ctx context.Context) error) Component {
+	return &funcComponent{
+		name:  name,
+		start: start,
+		stop:  stop,
+	}
+}
+
+type funcComponent struct {
+	name  string
+	start func(fail func(err error)) error
+	stop  func(ctx context.Context) error
+}
+
+func (f *funcComponent) Name() string {
+	return f.name
+}
+
+func (f *funcComponent) Start(fail func(err error)) error {
+	if f.start == nil {
+		return nil
+	}
+
+	return f.start(fail)
+}
+
+func (f *funcComponent) Stop(ctx context.Context) error {
+	if f.stop == nil {
+		return nil
+	}
+
+	return f.stop(ctx)
+}
@@ -0,0 +1,80 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+// Компонент, записывающий порядок вызовов
+func testComponent(name string, calls *[]string, startErr error, failc chan func(err error)) app.Component {
+	return app.FuncComponent(name,
+		func(fail func(err error)) error {
+			*calls = append(*calls, "start "+name)
+			if failc != nil {
+				failc <- fail
+			}
+
+			return startErr
+		},
+		func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+
+			return nil
+		})
+}
+
+func TestLifecycle_Run(t *testing.T) {
+	var calls []string
+
+	lc := app.NewLifecycle(time.Second)
+	lc.Add(testComponent("db", &calls, nil, nil), 0)
+	lc.Add(testComponent("http", &calls, nil, nil), time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, lc.Run(ctx))
+	assert.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, calls)
+}
+
+func TestLifecycle_StartError(t *testing.T) {
+	var calls []string
+
+	startErr := errors.New("listen error")
+
+	lc := app.NewLifecycle(time.Second)
+	lc.Add(testComponent("db", &calls, nil, nil), 0)
+	lc.Add(testComponent("http", &calls, startErr, nil), 0)
+	lc.Add(testComponent("jobs", &calls, nil, nil), 0)
+
+	err := lc.Run(context.Background())
+	assert.ErrorIs(t, err, startErr)
+	assert.Equal(t, []string{"start db", "start http", "stop db"}, calls)
+}
+
+func TestLifecycle_Fail(t *testing.T) {
+	var calls []string
+
+	failc := make(chan func(err error), 1)
+
+	failErr := errors.New("serve error")
+
+	lc := app.NewLifecycle(time.Second)
+	lc.Add(testComponent("db", &calls, nil, nil), 0)
+	lc.Add(testComponent("http", &calls, nil, failc), 0)
+
+	go func() {
+		// авария после запуска
+		fail := <-failc
+		fail(failErr)
+	}()
+
+	err := lc.Run(context.Background())
+	assert.ErrorIs(t, err, failErr)
+	assert.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, calls)
+}
@@ -1,8 +1,55 @@
 package logger
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+	"os"
 
-var loggerInstance *logrus.Logger // журналирование
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var (
+	loggerInstance *logrus.Logger // журналирование
+	// файл журнала с ротацией, если вывод идет в файл
+	fileOutput *lumberjack.Logger
+)
+
+// Тип ключа для хранения значений в контексте
+type contextKey int8
+
+const (
+	// Ключ для хранения в контексте уникального номера запроса
+	ctxKeyRequestID contextKey = iota
+	// Ключ для признака того, что записи журнала не надо сохранять в таблицу log
+	ctxKeySkipSelfLog
+)
+
+// SkipSelfLogField Поле записи журнала, отключающее ее сохранение в таблицу log (см. пакет selflog).
+// Используется при журналировании ошибок самой записи, чтобы они не порождали новые записи
+const SkipSelfLogField = "skip_self_log"
+
+// Форматы вывода журнала
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options Настройки журналирования
+type Options struct {
+	// Level Уровень (debug, info, warning, error и т.д.)
+	Level string
+	// Format Формат вывода: text или json
+	Format string
+	// File Файл журнала. Если не задан, журнал выводится в stderr
+	File string
+	// MaxSizeMB Размер файла, при превышении которого он ротируется
+	MaxSizeMB int
+	// MaxBackups Количество хранимых старых файлов (0 - все)
+	MaxBackups int
+	// MaxAgeDays Время хранения старых файлов в днях (0 - без ограничения)
+	MaxAgeDays int
+}
 
 // Logger Глобальный логер
 func Logger() *logrus.Logger {
@@ -12,3 +59,87 @@ func Logger() *logrus.Logger {
 
 	return loggerInstance
 }
+
+// WithRequestID Добавить в контекст номер запроса
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestID, requestID)
+}
+
+// RequestID Номер запроса из контекста. Пустая строка, если его там нет
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKeyRequestID).(string)
+
+	return id
+}
+
+// WithoutSelfLog Контекст, записи журнала в рамках которого не сохраняются в таблицу log
+func WithoutSelfLog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeySkipSelfLog, true)
+}
+
+// FromContext Логер с полями, взятыми из контекста (номер запроса)
+func FromContext(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(Logger())
+	if id := RequestID(ctx); id != "" {
+		entry = entry.WithField("request_id", id)
+	}
+
+	if skip, _ := ctx.Value(ctxKeySkipSelfLog).(bool); skip {
+		entry = entry.WithField(SkipSelfLogField, true)
+	}
+
+	return entry
+}
+
+// Configure Применить настройки журналирования: уровень, формат и вывод
+func Configure(opts Options) error {
+	if err := SetLevel(opts.Level); err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		Logger().SetFormatter(&logrus.JSONFormatter{}) //nolint:exhaustivestruct,exhaustruct
+	case FormatText, "":
+		Logger().SetFormatter(&logrus.TextFormatter{}) //nolint:exhaustivestruct,exhaustruct
+	default:
+		return errors.Errorf("unknown log format %q", opts.Format)
+	}
+
+	prevOutput := fileOutput
+
+	if opts.File == "" {
+		fileOutput = nil
+
+		Logger().SetOutput(os.Stderr)
+	} else {
+		fileOutput = &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSizeMB,
+			MaxAge:     opts.MaxAgeDays,
+			MaxBackups: opts.MaxBackups,
+			LocalTime:  false,
+			Compress:   true,
+		}
+
+		Logger().SetOutput(fileOutput)
+	}
+
+	if prevOutput != nil {
+		_ = prevOutput.Close()
+	}
+
+	return nil
+}
+
+// SetLevel Установить уровень журналирования (debug, info, warning, error и т.д.)
+func SetLevel(level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return errors.Wrap(err, "log level error")
+	}
+
+	Logger().SetLevel(lvl)
+
+	return nil
+}
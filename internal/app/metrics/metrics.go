@@ -0,0 +1,160 @@
+// Package metrics Метрики Prometheus. Метрики регистрируются в реестре по умолчанию
+// и отдаются через маршрут /metrics
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "logserver"
+
+var (
+	// HTTPRequests Количество обработанных HTTP запросов
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of processed HTTP requests by route, method and status code",
+	}, []string{"route", "method", "code"})
+
+	// HTTPDuration Время обработки HTTP запросов
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	// HTTPInFlight Количество запросов, обрабатываемых в данный момент
+	HTTPInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being processed",
+	})
+
+	// StreamsInFlight Количество активных потоковых выгрузок (export) и загрузок (import) записей журнала
+	StreamsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "streams_in_flight",
+		Help:      "Number of log record export and import streams currently in progress",
+	})
+
+	// RecordsIngested Количество записанных в журнал записей
+	RecordsIngested = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "log",
+		Name:      "records_ingested_total",
+		Help:      "Number of log records successfully stored",
+	})
+
+	// RecordsImported Количество записей, загруженных из файлов (logserver import, /api/private/import)
+	RecordsImported = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "log",
+		Name:      "records_imported_total",
+		Help:      "Number of historical log records loaded from files",
+	})
+
+	// RecordsRejected Количество отклоненных записей
+	RecordsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "log",
+		Name:      "records_rejected_total",
+		Help:      "Number of log records rejected by reason",
+	}, []string{"reason"})
+
+	// BatchSize Размер пачки записей, переданных на запись
+	BatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "log",
+		Name:      "batch_size",
+		Help:      "Number of records in an insert batch",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 9), //nolint:gomnd
+	})
+
+	// QueryDuration Время выполнения операций с хранилищем
+	QueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "query_duration_seconds",
+		Help:      "Storage operation latency by operation and result",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	// AlertsFiring Количество сработавших правил оповещений
+	AlertsFiring = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "alert",
+		Name:      "rules_firing",
+		Help:      "Number of alert rules currently firing",
+	})
+
+	// AlertNotifications Количество отправленных уведомлений по состоянию (firing, resolved)
+	AlertNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alert",
+		Name:      "notifications_total",
+		Help:      "Number of alert notifications by state",
+	}, []string{"state"})
+
+	// NotifyDeliveries Количество отправок уведомлений по каналам и результату (delivered, failed, dropped)
+	NotifyDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notify",
+		Name:      "deliveries_total",
+		Help:      "Number of alert notification deliveries by channel and result",
+	}, []string{"channel", "result"})
+
+	// ForwardRecords Количество пересланных записей журнала по получателям и результату (forwarded, failed, dropped)
+	ForwardRecords = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "forward",
+		Name:      "records_total",
+		Help:      "Number of log records forwarded to sinks by sink and result",
+	}, []string{"sink", "result"})
+
+	// ForwardQueue Количество записей в буфере получателя
+	ForwardQueue = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "forward",
+		Name:      "queue_length",
+		Help:      "Number of log records waiting to be forwarded by sink",
+	}, []string{"sink"})
+)
+
+// Причины отклонения записей
+const (
+	RejectValidation = "validation"
+	RejectStorage    = "storage"
+	RejectOverload   = "overload"
+)
+
+// Результаты отправки уведомлений
+const (
+	NotifyDelivered = "delivered"
+	NotifyFailed    = "failed"
+	NotifyDropped   = "dropped"
+)
+
+// Результаты пересылки записей
+const (
+	ForwardSent    = "forwarded"
+	ForwardFailed  = "failed"
+	ForwardDropped = "dropped"
+)
+
+// ObserveQuery Учесть время выполнения операции с хранилищем, начатой в момент start
+func ObserveQuery(operation string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	QueryDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
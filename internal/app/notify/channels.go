@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/httpout"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
+)
+
+// SignatureHeader Заголовок с подписью тела webhook: sha256=<hex HMAC-SHA256 тела по ключу SECRET>
+const SignatureHeader = "X-Logserver-Signature"
+
+// JSON уведомление с подписью
+type webhook struct {
+	info    model.NotifyChannel
+	url     string
+	secret  []byte
+	headers map[string]string
+}
+
+func (w *webhook) Channel() model.NotifyChannel {
+	return w.info
+}
+
+func (w *webhook) Send(ctx context.Context, event model.AlertEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "json")
+	}
+
+	headers := make(map[string]string, len(w.headers)+1)
+	for k, v := range w.headers {
+		headers[k] = v
+	}
+
+	if len(w.secret) > 0 {
+		headers[SignatureHeader] = Sign(w.secret, body)
+	}
+
+	return httpout.Post(ctx, w.url, "application/json", headers, body)
+}
+
+// Sign Подпись тела webhook для заголовка SignatureHeader
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// POST запрос с телом по шаблону
+type templateHTTP struct {
+	info        model.NotifyChannel
+	url         string
+	headers     map[string]string
+	template    *template.Template
+	contentType string
+}
+
+func (h *templateHTTP) Channel() model.NotifyChannel {
+	return h.info
+}
+
+func (h *templateHTTP) Send(ctx context.Context, event model.AlertEvent) error {
+	body, err := execute(h.template, event)
+	if err != nil {
+		return err
+	}
+
+	return httpout.Post(ctx, h.url, h.contentType, h.headers, body)
+}
+
+// Письмо через SMTP. Если сервер поддерживает STARTTLS, соединение шифруется
+type email struct {
+	info     model.NotifyChannel
+	addr     string
+	user     string
+	password string
+	from     string
+	to       []string
+	template *template.Template
+}
+
+func (e *email) Channel() model.NotifyChannel {
+	return e.info
+}
+
+func (e *email) Send(ctx context.Context, event model.AlertEvent) error {
+	body, err := execute(e.template, event)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("[logserver] %s is %s", event.RuleName, event.State)
+	if event.Test {
+		subject = "[logserver] test notification"
+	}
+
+	msg, err := e.message(subject, body)
+	if err != nil {
+		return err
+	}
+
+	return e.send(ctx, msg)
+}
+
+// Текст письма с заголовками
+func (e *email) message(subject string, body []byte) ([]byte, error) {
+	var b strings.Builder
+
+	b.WriteString("From: " + e.from + "\r\n")
+	b.WriteString("To: " + strings.Join(e.to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write(body); err != nil {
+		return nil, errors.Wrap(err, "message")
+	}
+
+	if err := qp.Close(); err != nil {
+		return nil, errors.Wrap(err, "message")
+	}
+
+	return []byte(b.String()), nil
+}
+
+// Отправка письма. В отличие от smtp.SendMail учитывает таймаут ctx
+func (e *email) send(ctx context.Context, msg []byte) error {
+	host, _, _ := net.SplitHostPort(e.addr) // проверено при загрузке конфигурации
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return errors.Wrap(err, "smtp")
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return errors.Wrap(err, "smtp")
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return errors.Wrap(err, "smtp starttls")
+		}
+	}
+
+	if e.user != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.user, e.password, host)); err != nil {
+			return errors.Wrap(err, "smtp auth")
+		}
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return errors.Wrap(err, "smtp")
+	}
+
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return errors.Wrapf(err, "smtp recipient %s", to)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "smtp")
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return errors.Wrap(err, "smtp")
+	}
+
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "smtp")
+	}
+
+	return errors.Wrap(client.Quit(), "smtp")
+}
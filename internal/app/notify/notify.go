@@ -0,0 +1,200 @@
+// Package notify Каналы уведомлений об оповещениях (webhook, email, http по шаблону) и фоновая отправка.
+// Очередь, повторные попытки и журнал доставки находятся в usecase.NotifyInterface
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"text/template"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/domain/usecase"
+	"github.com/pkg/errors"
+)
+
+// Текст письма по умолчанию
+const defaultEmailTemplate = `Alert rule "{{.RuleName}}" is {{.State}}
+{{if .Test}}
+This is a test notification.
+{{end}}
+Matching records in window: {{.Count}} (threshold {{.Threshold}}, window {{.WindowSec}} sec)
+Firing since: {{.Since.Format "2006-01-02 15:04:05 MST"}}
+Time: {{.Time.Format "2006-01-02 15:04:05 MST"}}
+{{with .Example}}Last record: {{.}}
+{{end}}`
+
+// New Создание каналов по конфигурации. Ошибки отправки записываются в журнал сервера
+func New(channels []config.NotifyChannel) ([]usecase.NotifySender, error) {
+	senders := make([]usecase.NotifySender, 0, len(channels))
+
+	for _, ch := range channels {
+		sender, err := newSender(ch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "notification channel %s", ch.Name)
+		}
+
+		senders = append(senders, logged{sender})
+	}
+
+	return senders, nil
+}
+
+func newSender(ch config.NotifyChannel) (usecase.NotifySender, error) {
+	info := model.NotifyChannel{
+		Name: ch.Name,
+		Type: ch.Type,
+	}
+
+	switch ch.Type {
+	case config.NotifyWebhook:
+		return &webhook{
+			info:    info,
+			url:     ch.URL,
+			secret:  []byte(ch.Secret),
+			headers: ch.Headers,
+		}, nil
+	case config.NotifyHTTP:
+		tmpl, err := parseTemplate(ch.Name, ch.Template)
+		if err != nil {
+			return nil, err
+		}
+
+		contentType := ch.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+
+		return &templateHTTP{
+			info:        info,
+			url:         ch.URL,
+			headers:     ch.Headers,
+			template:    tmpl,
+			contentType: contentType,
+		}, nil
+	case config.NotifyEmail:
+		text := ch.Template
+		if text == "" {
+			text = defaultEmailTemplate
+		}
+
+		tmpl, err := parseTemplate(ch.Name, text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &email{
+			info:     info,
+			addr:     ch.SMTPAddr,
+			user:     ch.SMTPUser,
+			password: ch.SMTPPassword,
+			from:     ch.From,
+			to:       ch.To,
+			template: tmpl,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", ch.Type)
+	}
+}
+
+// Шаблон с функцией json для подстановки значений в JSON: {"text": {{json .RuleName}}}
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+
+			return string(data), err //nolint:wrapcheck
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "template")
+	}
+
+	return tmpl, nil
+}
+
+func execute(tmpl *template.Template, event model.AlertEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return nil, errors.Wrap(err, "template")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Запись ошибок отправки в журнал сервера
+type logged struct {
+	usecase.NotifySender
+}
+
+func (l logged) Send(ctx context.Context, event model.AlertEvent) error {
+	err := l.NotifySender.Send(ctx, event)
+	if err != nil {
+		logger.Logger().WithFields(map[string]interface{}{
+			"channel": l.Channel().Name,
+			"rule":    event.RuleName,
+			"state":   event.State,
+		}).Warnf("notification failed: %v", err)
+	}
+
+	return err //nolint:wrapcheck
+}
+
+// Worker Фоновая отправка уведомлений. Является компонентом app.Lifecycle.
+// При остановке текущие отправки прерываются, уведомления в очереди теряются
+type Worker struct {
+	notify usecase.NotifyInterface
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewWorker Создание компонента
+func NewWorker(notify usecase.NotifyInterface) *Worker {
+	return &Worker{
+		notify: notify,
+		cancel: nil,
+		done:   make(chan struct{}),
+		once:   sync.Once{},
+	}
+}
+
+// Name Имя компонента (для app.Lifecycle)
+func (w *Worker) Name() string {
+	return "notifications"
+}
+
+// Start Запуск отправки в отдельной горутине
+func (w *Worker) Start(func(err error)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	go func() {
+		defer close(w.done)
+
+		w.notify.Run(ctx)
+	}()
+
+	return nil
+}
+
+// Stop Остановка отправки
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+
+	w.once.Do(w.cancel)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "notifications stop")
+	}
+}
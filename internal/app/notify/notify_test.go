@@ -0,0 +1,195 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/notify"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() model.AlertEvent {
+	now := time.Date(2022, 4, 23, 18, 25, 43, 0, time.UTC)
+
+	return model.AlertEvent{
+		RuleID:    1,
+		RuleName:  "errors",
+		State:     model.AlertFiring,
+		Count:     11,
+		Threshold: 10,
+		WindowSec: 300,
+		Since:     now,
+		Time:      now,
+		Example:   "connection refused",
+		Test:      false,
+	}
+}
+
+func TestWebhookAndTemplate(t *testing.T) {
+	var (
+		signature   string
+		webhookBody string
+		chatBody    string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		if r.URL.Path == "/webhook" {
+			signature, webhookBody = r.Header.Get(notify.SignatureHeader), string(data)
+		} else {
+			chatBody = string(data)
+		}
+	}))
+	defer srv.Close()
+
+	senders, err := notify.New([]config.NotifyChannel{
+		{Name: "hook", Type: config.NotifyWebhook, URL: srv.URL + "/webhook", Secret: "secret"},
+		{
+			Name: "chat", Type: config.NotifyHTTP, URL: srv.URL + "/chat",
+			Template: `{"text": {{json (printf "%s is %s: %s" .RuleName .State .Example)}}}`,
+		},
+	})
+	require.NoError(t, err)
+
+	for _, sender := range senders {
+		require.NoError(t, sender.Send(context.Background(), testEvent()))
+	}
+
+	assert.Contains(t, webhookBody, `"ruleName":"errors"`)
+	assert.Equal(t, notify.Sign([]byte("secret"), []byte(webhookBody)), signature)
+	assert.Equal(t, `{"text": "errors is firing: connection refused"}`, chatBody)
+
+	_, err = notify.New([]config.NotifyChannel{{Name: "bad", Type: config.NotifyHTTP, Template: "{{"}})
+	assert.Error(t, err)
+}
+
+// SMTP сервер, принимающий одно письмо
+func stubSMTP(t *testing.T) (addr string, message <-chan string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 stub")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 stub")
+			case cmd == "DATA":
+				reply("354 go ahead")
+
+				var data strings.Builder
+
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+
+					data.WriteString(line)
+				}
+
+				messages <- data.String()
+
+				reply("250 ok")
+			case cmd == "QUIT":
+				reply("221 bye")
+
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), messages
+}
+
+func TestEmail(t *testing.T) {
+	addr, messages := stubSMTP(t)
+
+	senders, err := notify.New([]config.NotifyChannel{{
+		Name: "ops", Type: config.NotifyEmail, SMTPAddr: addr,
+		From: "logserver@example.com", To: []string{"ops@example.com"},
+	}})
+	require.NoError(t, err)
+
+	require.NoError(t, senders[0].Send(context.Background(), testEvent()))
+
+	msg := <-messages
+	assert.Contains(t, msg, "To: ops@example.com")
+	assert.Contains(t, msg, "Subject: [logserver] errors is firing")
+	assert.Contains(t, msg, "Last record: connection refused")
+}
+
+func TestRetries(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	senders, err := notify.New([]config.NotifyChannel{{Name: "hook", Type: config.NotifyWebhook, URL: srv.URL}})
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.NotifyRetries = 2
+	cfg.NotifyRetryBackoffMs = 1
+
+	notifyCase := usecase.NewNotifyCase(senders, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go notifyCase.Run(ctx)
+
+	notifyCase.Notify(testEvent())
+
+	admin := &model.User{ID: 1, Login: "admin", Role: model.RoleAdmin} //nolint:exhaustivestruct,exhaustruct
+
+	var deliveries []model.NotifyDelivery
+
+	require.Eventually(t, func() bool {
+		deliveries, err = notifyCase.Deliveries(admin)
+
+		return err == nil && len(deliveries) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.True(t, deliveries[0].Delivered)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+}
@@ -0,0 +1,219 @@
+// Package selflog Сохранение журнала самого сервера в его же таблицу log.
+// Записи сохраняются с источником model.SelfLogSource (поле message2), поля записи - в message3 в виде JSON
+package selflog
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// размер буфера записей. При его заполнении новые записи отбрасываются
+	bufferSize = 1000
+	// максимальный размер пачки записей
+	batchSize = 100
+	// период записи накопленных записей
+	flushInterval = time.Second
+	// время на запись одной пачки
+	insertTimeout = 5 * time.Second
+)
+
+// Hook Хук logrus, сохраняющий записи журнала сервера в таблицу log. Запись выполняется пачками
+// в отдельной горутине, поэтому журналирование не блокируется при недоступности БД.
+// Является компонентом app.Lifecycle: при остановке записывает накопленные записи
+type Hook struct {
+	repo    repository.LogInterface
+	levels  []logrus.Level
+	entries chan model.LogRecord
+	dropped uint64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New Создание хука. Сохраняются записи с уровнем level и выше
+func New(repo repository.LogInterface, level logrus.Level) *Hook {
+	levels := make([]logrus.Level, 0, len(logrus.AllLevels))
+
+	for _, l := range logrus.AllLevels {
+		if l <= level {
+			levels = append(levels, l)
+		}
+	}
+
+	return &Hook{
+		repo:     repo,
+		levels:   levels,
+		entries:  make(chan model.LogRecord, bufferSize),
+		dropped:  0,
+		stopOnce: sync.Once{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Levels Уровни записей, обрабатываемых хуком
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire Поставить запись в очередь на сохранение. Не блокируется
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	if skip, _ := entry.Data[logger.SkipSelfLogField].(bool); skip {
+		return nil
+	}
+
+	select {
+	case h.entries <- newRecord(entry):
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+
+	return nil
+}
+
+// Dropped Количество записей, отброшенных из-за переполнения буфера
+func (h *Hook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Name Имя компонента (для app.Lifecycle)
+func (h *Hook) Name() string {
+	return "self log"
+}
+
+// Start Запуск записи в отдельной горутине
+func (h *Hook) Start(func(err error)) error {
+	go h.run()
+
+	return nil
+}
+
+// Stop Остановка с записью накопленных записей
+func (h *Hook) Stop(ctx context.Context) error {
+	h.stopOnce.Do(func() { close(h.stop) })
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "self log flush")
+	}
+}
+
+func (h *Hook) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.LogRecord, 0, batchSize)
+
+	for {
+		select {
+		case record := <-h.entries:
+			batch = append(batch, record)
+			if len(batch) >= batchSize {
+				batch = h.flush(batch)
+			}
+		case <-ticker.C:
+			batch = h.flush(batch)
+		case <-h.stop:
+			// забираем все, что успели поставить в очередь
+			for {
+				select {
+				case record := <-h.entries:
+					batch = append(batch, record)
+					if len(batch) >= batchSize {
+						batch = h.flush(batch)
+					}
+				default:
+					h.flush(batch)
+
+					return
+				}
+			}
+		}
+	}
+}
+
+// Запись пачки. Возвращает пустой буфер для следующей пачки
+func (h *Hook) flush(batch []model.LogRecord) []model.LogRecord {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	// ошибки записи журналируются, но не сохраняются в таблицу, иначе они порождали бы новые записи
+	ctx, cancel := context.WithTimeout(logger.WithoutSelfLog(context.Background()), insertTimeout)
+	defer cancel()
+
+	if err := h.repo.Insert(ctx, &batch); err != nil {
+		logger.FromContext(ctx).WithError(err).Warnf("self log: %d records lost", len(batch))
+	}
+
+	return make([]model.LogRecord, 0, batchSize)
+}
+
+// Преобразование записи logrus в запись журнала
+func newRecord(entry *logrus.Entry) model.LogRecord {
+	fields := make(map[string]interface{}, len(entry.Data))
+
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		fields[k] = v
+	}
+
+	var data string
+	if len(fields) > 0 {
+		if b, err := json.Marshal(fields); err == nil {
+			data = string(b)
+		}
+	}
+
+	message := entry.Message
+	if message == "" {
+		// message1 обязательно
+		message = "-"
+	}
+
+	return model.LogRecord{
+		ID:       0,
+		LogTime:  entry.Time,
+		RealTime: time.Now(),
+		Level:    level(entry.Level),
+		Message1: message,
+		Message2: model.SelfLogSource,
+		Message3: data,
+	}
+}
+
+// Уровень записи журнала, соответствующий уровню logrus
+func level(l logrus.Level) uint {
+	switch l {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return model.LevelCritical
+	case logrus.ErrorLevel:
+		return model.LevelError
+	case logrus.WarnLevel:
+		return model.LevelWarning
+	case logrus.InfoLevel:
+		return model.LevelInfo
+	case logrus.DebugLevel, logrus.TraceLevel:
+		return model.LevelDebug
+	default:
+		return model.LevelDebug
+	}
+}
@@ -0,0 +1,48 @@
+package selflog_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/app/selflog"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository/testrepo"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	dbo, err := testrepo.CreateTestlDBO()
+	require.NoError(t, err)
+
+	logRepo := testrepo.NewLog(dbo)
+	hook := selflog.New(logRepo, logrus.WarnLevel)
+	require.NoError(t, hook.Start(func(error) {}))
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	log.AddHook(hook)
+
+	log.Info("below level")
+	log.WithError(errors.New("connection refused")).Error("insert failed")
+	log.WithField(logger.SkipSelfLogField, true).Warn("skipped")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, hook.Stop(ctx))
+
+	records, _, err := logRepo.Find(context.Background(), time.Time{}, time.Time{}, 100)
+	require.NoError(t, err)
+	require.Len(t, *records, 1)
+
+	r := (*records)[0]
+	assert.Equal(t, "insert failed", r.Message1)
+	assert.Equal(t, model.SelfLogSource, r.Message2)
+	assert.Equal(t, model.LevelError, r.Level)
+	assert.JSONEq(t, `{"error": "connection refused"}`, r.Message3)
+}
@@ -7,16 +7,34 @@ import "github.com/n-r-w/log-server/internal/domain/usecase"
 // Инициализируется на старте путем выбора нужных реализаций в зависимости
 // от необходимости обычной работы, юнит-тестов и т.п.
 type Domain struct {
-	LogUsecase  usecase.LogInterface
-	UserUsecase usecase.UserInterface
+	LogUsecase     usecase.LogInterface
+	UserUsecase    usecase.UserInterface
+	HealthUsecase  usecase.HealthInterface
+	TokenUsecase   usecase.TokenInterface
+	SessionUsecase usecase.SessionInterface
+	SearchUsecase  usecase.SearchInterface
+	AlertUsecase   usecase.AlertInterface
+	NotifyUsecase  usecase.NotifyInterface
 }
 
 // NewDomain - Создание объекта Domain
 func NewDomain(
 	logUsecase usecase.LogInterface,
-	userUsecase usecase.UserInterface) *Domain {
+	userUsecase usecase.UserInterface,
+	healthUsecase usecase.HealthInterface,
+	tokenUsecase usecase.TokenInterface,
+	sessionUsecase usecase.SessionInterface,
+	searchUsecase usecase.SearchInterface,
+	alertUsecase usecase.AlertInterface,
+	notifyUsecase usecase.NotifyInterface) *Domain {
 	return &Domain{
-		LogUsecase:  logUsecase,
-		UserUsecase: userUsecase,
+		LogUsecase:     logUsecase,
+		UserUsecase:    userUsecase,
+		HealthUsecase:  healthUsecase,
+		TokenUsecase:   tokenUsecase,
+		SessionUsecase: sessionUsecase,
+		SearchUsecase:  searchUsecase,
+		AlertUsecase:   alertUsecase,
+		NotifyUsecase:  notifyUsecase,
 	}
 }
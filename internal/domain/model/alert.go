@@ -0,0 +1,115 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+// AlertState Состояние правила оповещения
+type AlertState string
+
+const (
+	// AlertOK Условие правила не выполняется
+	AlertOK AlertState = "ok"
+	// AlertFiring Условие правила выполняется
+	AlertFiring AlertState = "firing"
+	// AlertResolved Условие правила перестало выполняться (только в уведомлениях)
+	AlertResolved AlertState = "resolved"
+)
+
+const (
+	// Максимальная длина названия правила
+	maxAlertNameLength = 100
+	// MaxAlertWindowSec Максимальное окно правила
+	MaxAlertWindowSec = 24 * 60 * 60
+	// Максимальный порог правила
+	maxAlertThreshold = 100000
+)
+
+var errBadPattern = errors.New("bad regular expression")
+
+// AlertRule Правило оповещения: больше Threshold записей с уровнем не ниже MinLevel,
+// совпадающих с Pattern, за последние WindowSec секунд
+type AlertRule struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	// MinLevel Минимальный уровень записи (0 - любой)
+	MinLevel uint `json:"minLevel"`
+	// Pattern Регулярное выражение, которое ищется в message1, message2 и message3 (пустое - любая запись)
+	Pattern   string `json:"pattern"`
+	Threshold int    `json:"threshold"`
+	WindowSec int    `json:"windowSec"`
+	Enabled   bool   `json:"enabled"`
+	// SilencedUntil До этого момента уведомления по правилу не отправляются
+	SilencedUntil *time.Time `json:"silencedUntil,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// Prepare Нормализация перед сохранением
+func (r *AlertRule) Prepare() {
+	r.Name = strings.TrimSpace(r.Name)
+}
+
+// Validate Валидация правила
+func (r *AlertRule) Validate() error {
+	return validation.ValidateStruct(
+		r,
+		validation.Field(&r.Name, validation.Required, validation.Length(1, maxAlertNameLength)),
+		validation.Field(&r.Pattern, validation.By(validatePattern)),
+		validation.Field(&r.Threshold, validation.Min(0), validation.Max(maxAlertThreshold)),
+		validation.Field(&r.WindowSec, validation.Required, validation.Min(1), validation.Max(MaxAlertWindowSec)),
+	)
+}
+
+// Silenced Заглушено ли правило в момент now
+func (r *AlertRule) Silenced(now time.Time) bool {
+	return r.SilencedUntil != nil && now.Before(*r.SilencedUntil)
+}
+
+// Window Окно правила
+func (r *AlertRule) Window() time.Duration {
+	return time.Duration(r.WindowSec) * time.Second
+}
+
+// Проверка регулярного выражения
+func validatePattern(value interface{}) error {
+	pattern, _ := value.(string)
+	if _, err := regexp.Compile(pattern); err != nil {
+		return errBadPattern
+	}
+
+	return nil
+}
+
+// AlertStatus Текущее состояние правила
+type AlertStatus struct {
+	RuleID uint64     `json:"ruleId"`
+	State  AlertState `json:"state"`
+	// Count Количество совпавших записей в окне
+	Count int `json:"count"`
+	// Since Начало срабатывания
+	Since    *time.Time `json:"since,omitempty"`
+	Silenced bool       `json:"silenced"`
+}
+
+// AlertEvent Уведомление о срабатывании или разрешении правила
+type AlertEvent struct {
+	RuleID    uint64     `json:"ruleId"`
+	RuleName  string     `json:"ruleName"`
+	State     AlertState `json:"state"`
+	Count     int        `json:"count"`
+	Threshold int        `json:"threshold"`
+	WindowSec int        `json:"windowSec"`
+	// Since Начало срабатывания
+	Since time.Time `json:"since"`
+	// Time Момент уведомления
+	Time time.Time `json:"time"`
+	// Example Последняя совпавшая запись (message1)
+	Example string `json:"example,omitempty"`
+	// Test Тестовое уведомление, отправленное вручную
+	Test bool `json:"test,omitempty"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// DBStats Состояние пула соединений хранилища
+type DBStats struct {
+	TotalConns    int32 `json:"totalConns"`
+	IdleConns     int32 `json:"idleConns"`
+	AcquiredConns int32 `json:"acquiredConns"`
+	MaxConns      int32 `json:"maxConns"`
+}
+
+// HealthCheck Результат проверки готовности одного из компонентов сервиса
+type HealthCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
@@ -4,12 +4,29 @@
 package model
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pkg/errors"
 )
 
+// Уровни записей журнала. Сервер не ограничивает клиентов этими значениями,
+// они используются для записей самого сервера (см. SelfLogSource)
+const (
+	LevelDebug    uint = 1
+	LevelInfo     uint = 2
+	LevelWarning  uint = 3
+	LevelError    uint = 4
+	LevelCritical uint = 5
+)
+
+// SelfLogSource Зарезервированное значение Message2 для записей журнала самого сервера.
+// Клиенты не могут добавлять записи с таким источником
+const SelfLogSource = "logserver"
+
 type LogRecord struct {
 	ID       uint64    `json:"id"`
 	LogTime  time.Time `json:"logTime"`
@@ -28,3 +45,54 @@ func (l *LogRecord) Validate() error {
 		validation.Field(&l.Message1, validation.Required),
 	), "validation error")
 }
+
+var errBadCursor = errors.New("bad cursor")
+
+// LogCursor Позиция в выборке записей журнала, упорядоченной по убыванию времени записи и ID.
+// Позволяет читать выборку по страницам без OFFSET
+type LogCursor struct {
+	LogTime time.Time
+	ID      uint64
+}
+
+// String Текстовое представление курсора для передачи клиенту
+func (c LogCursor) String() string {
+	return fmt.Sprintf("%d_%d", c.LogTime.UnixNano(), c.ID)
+}
+
+// ParseLogCursor Разбор текстового представления курсора
+func ParseLogCursor(s string) (*LogCursor, error) {
+	nanoText, idText, ok := strings.Cut(s, "_")
+	if !ok {
+		return nil, errBadCursor
+	}
+
+	nano, err := strconv.ParseInt(nanoText, 10, 64)
+	if err != nil {
+		return nil, errBadCursor
+	}
+
+	id, err := strconv.ParseUint(idText, 10, 64)
+	if err != nil {
+		return nil, errBadCursor
+	}
+
+	return &LogCursor{
+		LogTime: time.Unix(0, nano).UTC(),
+		ID:      id,
+	}, nil
+}
+
+// LogPage Страница записей журнала. NextCursor пустой, если страница последняя
+type LogPage struct {
+	Records    []LogRecord `json:"records"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+// LogRecordContext Запись журнала вместе с соседними записями (по времени записи).
+// Newer - более новые записи, Older - более старые, обе выборки по убыванию времени записи
+type LogRecordContext struct {
+	Record LogRecord   `json:"record"`
+	Newer  []LogRecord `json:"newer"`
+	Older  []LogRecord `json:"older"`
+}
@@ -4,16 +4,10 @@ import (
 	"testing"
 	"time"
 
-	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/stretchr/testify/assert"
 )
 
-func initLogTestCase(t *testing.T) {
-	t.Helper()
-	assert.NoError(t, config.Load(""))
-}
-
 func TestLogRecord_Validate(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -56,8 +50,6 @@ func TestLogRecord_Validate(t *testing.T) {
 		},
 	}
 
-	initLogTestCase(t)
-
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.isValid {
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// NotifyChannel Канал уведомлений (без параметров подключения и секретов)
+type NotifyChannel struct {
+	Name string `json:"name"`
+	// Type Тип канала: webhook, email или http
+	Type string `json:"type"`
+}
+
+// NotifyDelivery Результат отправки уведомления в канал
+type NotifyDelivery struct {
+	ID        uint64     `json:"id"`
+	Channel   string     `json:"channel"`
+	RuleID    uint64     `json:"ruleId"`
+	RuleName  string     `json:"ruleName"`
+	State     AlertState `json:"state"`
+	Test      bool       `json:"test,omitempty"`
+	Delivered bool       `json:"delivered"`
+	// Attempts Количество выполненных попыток (0 - уведомление не было отправлено)
+	Attempts int `json:"attempts"`
+	// Error Ошибка последней попытки
+	Error string `json:"error,omitempty"`
+	// Time Момент завершения отправки
+	Time time.Time `json:"time"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+// SearchQueryKeys Параметры поиска по журналу, которые сохраняются в SavedSearch.Query
+var SearchQueryKeys = []string{"from", "to", "tz"}
+
+// Максимальная длина названия сохраненного поиска
+const maxSearchNameLength = 100
+
+var errBadSearchQuery = errors.New("query must contain from and to")
+
+// SavedSearch Сохраненный поиск по журналу. Query - строка запроса страницы поиска /search
+// (параметры SearchQueryKeys). Общий поиск (Shared) видят все пользователи, менять его может только владелец
+type SavedSearch struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"userId"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Shared    bool      `json:"shared"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Prepare Нормализация: убираются пробелы в названии и лишние параметры запроса
+func (s *SavedSearch) Prepare() {
+	s.Name = strings.TrimSpace(s.Name)
+
+	values, err := url.ParseQuery(strings.TrimPrefix(strings.TrimSpace(s.Query), "?"))
+	if err != nil {
+		// ошибку покажет Validate
+		return
+	}
+
+	query := url.Values{}
+
+	for _, key := range SearchQueryKeys {
+		if v := strings.TrimSpace(values.Get(key)); v != "" {
+			query.Set(key, v)
+		}
+	}
+
+	s.Query = query.Encode()
+}
+
+// Validate Валидация названия и запроса
+func (s *SavedSearch) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.Name, validation.Required, validation.Length(1, maxSearchNameLength)),
+		validation.Field(&s.Query, validation.By(validateSearchQuery)),
+	)
+}
+
+// Values Параметры запроса поиска
+func (s *SavedSearch) Values() url.Values {
+	values, _ := url.ParseQuery(s.Query)
+
+	return values
+}
+
+// Запрос должен содержать интервал дат
+func validateSearchQuery(value interface{}) error {
+	query, _ := value.(string)
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return errors.Wrap(err, "bad query")
+	}
+
+	if values.Get("from") == "" || values.Get("to") == "" {
+		return errBadSearchQuery
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Session Сессия пользователя, открытая логином по паролю. Идентификатор сессии (секрет) хранится
+// только в куки клиента, в хранилище находится его хэш
+type Session struct {
+	ID         uint64    `json:"id"`
+	UserID     uint64    `json:"userId"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	RemoteAddr string    `json:"remoteAddr"`
+	UserAgent  string    `json:"userAgent"`
+	// Current Сессия, из которой сделан запрос
+	Current bool `json:"current"`
+}
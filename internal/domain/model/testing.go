@@ -13,8 +13,10 @@ func TestUser(t *testing.T) *User {
 		ID:                10,
 		Login:             "testrepo@example.com",
 		Name:              "Ivan Petrov",
+		Role:              RoleUser,
 		Password:          "Qw!12345",
 		EncryptedPassword: "",
+		Timezone:          "",
 	}
 }
 
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// APIToken Токен доступа к API. Сам токен (секрет) выдается один раз при создании,
+// в хранилище находится только его хэш
+type APIToken struct {
+	ID         uint64     `json:"id"`
+	UserID     uint64     `json:"userId"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
@@ -8,41 +8,122 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	_ "github.com/go-ozzo/ozzo-validation/v4/is"
-	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/tool"
 	"github.com/pkg/errors"
 )
 
+// Role Роль пользователя
+type Role string
+
+const (
+	// RoleAdmin Администратор: управление пользователями и токенами, чтение и запись журнала
+	RoleAdmin Role = "admin"
+	// RoleUser Пользователь: чтение и запись журнала
+	RoleUser Role = "user"
+	// RoleReader Только чтение журнала
+	RoleReader Role = "reader"
+)
+
+// Roles Все роли
+var Roles = []Role{RoleAdmin, RoleUser, RoleReader}
+
+var errBadTimezone = errors.New("unknown time zone")
+
 // User Модель пользователя
 type User struct {
 	ID                uint64 `json:"id"`
 	Login             string `json:"login"`
 	Name              string `json:"name"`
+	Role              Role   `json:"role"`
 	Password          string `json:"password,omitempty"`
 	EncryptedPassword string `json:"-"`
+	// Timezone Часовой пояс веб интерфейса (IANA, например Europe/Moscow). Пустой - часовой пояс браузера
+	Timezone string `json:"timezone"`
+}
+
+// IsAdmin Имеет ли пользователь права администратора
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// CanWrite Может ли пользователь добавлять записи в журнал
+func (u *User) CanWrite() bool {
+	return u.Role == RoleAdmin || u.Role == RoleUser
+}
+
+// PasswordPolicy Требования к паролю
+type PasswordPolicy struct {
+	Regex   *regexp.Regexp
+	Message string
+}
+
+// SuperAdmin Параметры встроенного администратора. Админ не содержится в БД и всегда неявно присутствует
+type SuperAdmin struct {
+	ID       uint64
+	Login    string
+	Password string
 }
 
 // Validate Валидация ...
-func (u *User) Validate() error {
+func (u *User) Validate(policy PasswordPolicy) error {
+	rules := []validation.Rule{validation.When(len(u.EncryptedPassword) == 0, validation.Required)}
+	if policy.Regex != nil {
+		rules = append(rules, validation.When(len(u.EncryptedPassword) == 0,
+			validation.Match(policy.Regex).Error(policy.Message)))
+	}
+
 	return validation.ValidateStruct(
 		u,
 		validation.Field(&u.Login, validation.Required),
 		validation.Field(&u.Name, validation.Required),
-		validation.Field(&u.Password, validation.When(len(u.EncryptedPassword) == 0, validation.Required)),
-		validation.Field(&u.Password, validation.When(len(u.EncryptedPassword) == 0,
-			validation.Match(regexp.MustCompile(config.AppConfig.PasswordRegex)).Error(config.AppConfig.PasswordRegexError))),
+		validation.Field(&u.Role, validation.In(RoleAdmin, RoleUser, RoleReader)),
+		validation.Field(&u.Password, rules...),
+		validation.Field(&u.Timezone, validation.By(validateTimezone)),
 	)
 }
 
+// Location Часовой пояс пользователя. nil, если не задан
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return nil
+	}
+
+	return loc
+}
+
+// Проверка названия часового пояса
+func validateTimezone(value interface{}) error {
+	tz, _ := value.(string)
+	if tz == "" {
+		return nil
+	}
+
+	if _, err := time.LoadLocation(tz); err != nil {
+		return errBadTimezone
+	}
+
+	return nil
+}
+
 // Prepare Подготовка данных после первой инициализации (инициализация хэша пароля)
 func (u *User) Prepare(sanitize bool) error {
 	u.Login = strings.TrimSpace(u.Login)
 	u.Name = strings.TrimSpace(u.Name)
 	u.Password = strings.TrimSpace(u.Password)
 
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+
 	if len(u.Password) > 0 {
 		enc, err := tool.EncryptPassword(u.Password)
 		if err != nil {
@@ -69,14 +150,26 @@ func (u *User) ComparePassword(password string) bool {
 	return tool.ComparePassword(u.EncryptedPassword, password)
 }
 
-// AdminUser - Фейковый пользователь - админ
-func AdminUser() *User {
+// IsAdmin Является ли пользователь с данным ID админом
+func (a SuperAdmin) IsAdmin(userID uint64) bool {
+	return userID == a.ID
+}
+
+// IsAdminLogin Является ли логин логином админа
+func (a SuperAdmin) IsAdminLogin(login string) bool {
+	return strings.EqualFold(login, a.Login)
+}
+
+// User - Фейковый пользователь - админ
+func (a SuperAdmin) User() *User {
 	user := &User{
-		ID:                config.AppConfig.SuperAdminID,
+		ID:                a.ID,
 		Name:              "admin",
-		Login:             config.AppConfig.SuperAdminLogin,
-		Password:          config.AppConfig.SuperPassword,
+		Login:             a.Login,
+		Role:              RoleAdmin,
+		Password:          a.Password,
 		EncryptedPassword: "",
+		Timezone:          "",
 	}
 
 	if err := tool.LogIf(user.Prepare(true), "internal error"); err != nil {
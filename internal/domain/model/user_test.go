@@ -1,17 +1,18 @@
 package model_test
 
 import (
+	"regexp"
 	"testing"
 
-	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/tool"
 	"github.com/stretchr/testify/assert"
 )
 
-func initUserTestCase(t *testing.T) {
-	t.Helper()
-	assert.NoError(t, config.Load(""))
+// Требования к паролю, как в конфигурации по умолчанию
+var testPasswordPolicy = model.PasswordPolicy{
+	Regex:   regexp.MustCompile(".*"),
+	Message: "bad password",
 }
 
 func TestUser_Validate(t *testing.T) {
@@ -56,22 +57,18 @@ func TestUser_Validate(t *testing.T) {
 		},
 	}
 
-	initUserTestCase(t)
-
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.isValid {
-				assert.NoError(t, tc.user().Validate())
+				assert.NoError(t, tc.user().Validate(testPasswordPolicy))
 			} else {
-				assert.Error(t, tc.user().Validate())
+				assert.Error(t, tc.user().Validate(testPasswordPolicy))
 			}
 		})
 	}
 }
 
 func TestUser_Prepare(t *testing.T) {
-	initUserTestCase(t)
-
 	u := model.TestUser(t)
 
 	assert.NoError(t, u.Prepare(false))
@@ -81,8 +78,6 @@ func TestUser_Prepare(t *testing.T) {
 }
 
 func TestUser_ComparePassword(t *testing.T) {
-	initUserTestCase(t)
-
 	u := model.TestUser(t)
 	assert.NoError(t, u.Prepare(false))
 
@@ -0,0 +1,417 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/metrics"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+	"github.com/pkg/errors"
+)
+
+// Состояние правила в движке оповещений
+type alertState struct {
+	rule    model.AlertRule
+	pattern *regexp.Regexp // nil - любая запись
+	// совпавшие записи в окне по возрастанию времени поступления, сгруппированные по секундам,
+	// чтобы поток записей не занимал память. count - их общее количество
+	hits    []alertHits
+	count   int
+	example string
+
+	firing bool
+	since  time.Time
+	// отправлено ли уведомление о срабатывании (не отправляется, пока правило заглушено)
+	notified bool
+}
+
+// Совпавшие записи, поступившие в течение секунды начиная с at
+type alertHits struct {
+	at    time.Time
+	count int
+}
+
+type alertCase struct {
+	alertRepo repository.AlertInterface
+	notifier  AlertNotifier
+	now       func() time.Time
+
+	mutex  sync.Mutex
+	states map[uint64]*alertState
+}
+
+func NewAlertCase(alertRepo repository.AlertInterface, notifier AlertNotifier) AlertInterface {
+	return &alertCase{
+		alertRepo: alertRepo,
+		notifier:  notifier,
+		now:       time.Now,
+		mutex:     sync.Mutex{},
+		states:    map[uint64]*alertState{},
+	}
+}
+
+// Load Загрузить правила из хранилища. Текущее состояние правил сбрасывается
+func (a *alertCase) Load(ctx context.Context) error {
+	rules, err := a.alertRepo.GetAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, "load alert rules error")
+	}
+
+	a.mutex.Lock()
+	a.states = map[uint64]*alertState{}
+	for i := range *rules {
+		a.states[(*rules)[i].ID] = newAlertState((*rules)[i])
+	}
+	a.updateMetrics()
+	a.mutex.Unlock()
+
+	return nil
+}
+
+// Observe Учесть записи, добавленные в журнал
+func (a *alertCase) Observe(records []model.LogRecord) {
+	now := a.now()
+
+	var events []model.AlertEvent
+
+	a.mutex.Lock()
+	for _, state := range a.states {
+		if !state.rule.Enabled {
+			continue
+		}
+
+		matched := false
+
+		for i := range records {
+			if state.matches(&records[i]) {
+				state.hit(now, records[i].Message1)
+				matched = true
+			}
+		}
+
+		if matched {
+			if event, ok := state.evaluate(now); ok {
+				events = append(events, event)
+			}
+		}
+	}
+	a.updateMetrics()
+	a.mutex.Unlock()
+
+	a.notify(events)
+}
+
+// Evaluate Периодическая проверка правил
+func (a *alertCase) Evaluate(now time.Time) {
+	var events []model.AlertEvent
+
+	a.mutex.Lock()
+	for _, state := range a.states {
+		if event, ok := state.evaluate(now); ok {
+			events = append(events, event)
+		}
+	}
+	a.updateMetrics()
+	a.mutex.Unlock()
+
+	a.notify(events)
+}
+
+// Create Создать правило
+func (a *alertCase) Create(ctx context.Context, currentUser *model.User, rule *model.AlertRule) error {
+	if !currentUser.IsAdmin() {
+		return errNotAdmin
+	}
+
+	rule.ID = 0
+	rule.CreatedAt = time.Time{}
+	rule.Prepare()
+
+	if err := a.alertRepo.Insert(ctx, rule); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	a.replace(rule.ID, rule)
+
+	return nil
+}
+
+// Update Изменить правило. Время создания и заглушка не меняются
+func (a *alertCase) Update(ctx context.Context, currentUser *model.User, rule *model.AlertRule) error {
+	existing, err := a.find(ctx, currentUser, rule.ID)
+	if err != nil {
+		return err
+	}
+
+	rule.Prepare()
+	rule.CreatedAt = existing.CreatedAt
+	rule.SilencedUntil = existing.SilencedUntil
+
+	if err := a.alertRepo.Update(ctx, rule); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	a.replace(rule.ID, rule)
+
+	return nil
+}
+
+// Remove Удалить правило
+func (a *alertCase) Remove(ctx context.Context, currentUser *model.User, ruleID uint64) error {
+	if !currentUser.IsAdmin() {
+		return errNotAdmin
+	}
+
+	if err := a.alertRepo.Remove(ctx, ruleID); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	a.replace(ruleID, nil)
+
+	return nil
+}
+
+// Silence Заглушить уведомления правила. Состояние правила сохраняется: если после снятия заглушки
+// правило все еще сработавшее, уведомление отправляется при следующей проверке
+func (a *alertCase) Silence(ctx context.Context, currentUser *model.User, ruleID uint64, until *time.Time) (
+	*model.AlertRule, error,
+) {
+	rule, err := a.find(ctx, currentUser, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if until != nil {
+		u := until.UTC()
+		until = &u
+	}
+
+	rule.SilencedUntil = until
+
+	if err := a.alertRepo.Update(ctx, rule); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	a.mutex.Lock()
+	if state, ok := a.states[ruleID]; ok {
+		state.rule.SilencedUntil = until
+	}
+	a.mutex.Unlock()
+
+	return rule, nil
+}
+
+// List Все правила
+func (a *alertCase) List(ctx context.Context, currentUser *model.User) (*[]model.AlertRule, error) {
+	if !currentUser.IsAdmin() {
+		return nil, errNotAdmin
+	}
+
+	return a.alertRepo.GetAll(ctx) //nolint:wrapcheck
+}
+
+// Status Текущее состояние правил в порядке ID
+func (a *alertCase) Status(currentUser *model.User) ([]model.AlertStatus, error) {
+	if !currentUser.IsAdmin() {
+		return nil, errNotAdmin
+	}
+
+	now := a.now()
+
+	a.mutex.Lock()
+	statuses := make([]model.AlertStatus, 0, len(a.states))
+	for _, state := range a.states {
+		state.expire(now)
+
+		status := model.AlertStatus{
+			RuleID:   state.rule.ID,
+			State:    model.AlertOK,
+			Count:    state.count,
+			Since:    nil,
+			Silenced: state.rule.Silenced(now),
+		}
+
+		if state.firing {
+			since := state.since
+			status.State = model.AlertFiring
+			status.Since = &since
+		}
+
+		statuses = append(statuses, status)
+	}
+	a.mutex.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].RuleID < statuses[j].RuleID })
+
+	return statuses, nil
+}
+
+// Правило по ID для изменения
+func (a *alertCase) find(ctx context.Context, currentUser *model.User, ruleID uint64) (*model.AlertRule, error) {
+	if !currentUser.IsAdmin() {
+		return nil, errNotAdmin
+	}
+
+	rule, err := a.alertRepo.FindByID(ctx, ruleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find alert rule error")
+	}
+
+	if rule == nil {
+		return nil, repository.ErrAlertRuleNotFound
+	}
+
+	return rule, nil
+}
+
+// Заменить состояние правила новым (nil - удалить). Если старое состояние было сработавшим
+// и о нем было отправлено уведомление, отправляется уведомление о разрешении
+func (a *alertCase) replace(ruleID uint64, rule *model.AlertRule) {
+	var events []model.AlertEvent
+
+	a.mutex.Lock()
+	if old, ok := a.states[ruleID]; ok && old.firing && old.notified {
+		events = append(events, old.event(model.AlertResolved, a.now()))
+	}
+
+	if rule == nil {
+		delete(a.states, ruleID)
+	} else {
+		a.states[ruleID] = newAlertState(*rule)
+	}
+	a.updateMetrics()
+	a.mutex.Unlock()
+
+	a.notify(events)
+}
+
+// Отправка уведомлений. Вызывается без блокировки
+func (a *alertCase) notify(events []model.AlertEvent) {
+	for _, event := range events {
+		metrics.AlertNotifications.WithLabelValues(string(event.State)).Inc()
+		a.notifier.Notify(event)
+	}
+}
+
+// Обновление метрик. Вызывается под блокировкой
+func (a *alertCase) updateMetrics() {
+	firing := 0
+
+	for _, state := range a.states {
+		if state.firing {
+			firing++
+		}
+	}
+
+	metrics.AlertsFiring.Set(float64(firing))
+}
+
+func newAlertState(rule model.AlertRule) *alertState {
+	var pattern *regexp.Regexp
+	if rule.Pattern != "" {
+		// выражение проверено при сохранении правила
+		pattern, _ = regexp.Compile(rule.Pattern)
+	}
+
+	return &alertState{
+		rule:     rule,
+		pattern:  pattern,
+		hits:     nil,
+		count:    0,
+		example:  "",
+		firing:   false,
+		since:    time.Time{},
+		notified: false,
+	}
+}
+
+// Подходит ли запись под правило
+func (s *alertState) matches(record *model.LogRecord) bool {
+	if record.Level < s.rule.MinLevel {
+		return false
+	}
+
+	if s.pattern == nil {
+		return true
+	}
+
+	return s.pattern.MatchString(record.Message1) || s.pattern.MatchString(record.Message2) ||
+		s.pattern.MatchString(record.Message3)
+}
+
+// Учесть совпавшую запись
+func (s *alertState) hit(now time.Time, message string) {
+	if last := len(s.hits) - 1; last >= 0 && now.Sub(s.hits[last].at) < time.Second {
+		s.hits[last].count++
+	} else {
+		s.hits = append(s.hits, alertHits{at: now, count: 1})
+	}
+
+	s.count++
+	s.example = message
+}
+
+// Удалить срабатывания за пределами окна (с точностью до секунды)
+func (s *alertState) expire(now time.Time) {
+	from := now.Add(-s.rule.Window())
+
+	n := 0
+	for n < len(s.hits) && !s.hits[n].at.After(from) {
+		s.count -= s.hits[n].count
+		n++
+	}
+
+	s.hits = s.hits[n:]
+}
+
+// Проверка условия правила. Возвращает уведомление, если оно нужно
+func (s *alertState) evaluate(now time.Time) (model.AlertEvent, bool) {
+	s.expire(now)
+
+	active := s.rule.Enabled && s.count > s.rule.Threshold
+
+	switch {
+	case active && !s.firing:
+		s.firing = true
+		s.since = now
+		s.notified = false
+	case !active && s.firing:
+		s.firing = false
+
+		if s.notified {
+			s.notified = false
+
+			return s.event(model.AlertResolved, now), true
+		}
+
+		return model.AlertEvent{}, false //nolint:exhaustivestruct,exhaustruct
+	}
+
+	// уведомление о срабатывании отправляется один раз и откладывается, пока правило заглушено
+	if s.firing && !s.notified && !s.rule.Silenced(now) {
+		s.notified = true
+
+		return s.event(model.AlertFiring, now), true
+	}
+
+	return model.AlertEvent{}, false //nolint:exhaustivestruct,exhaustruct
+}
+
+func (s *alertState) event(state model.AlertState, now time.Time) model.AlertEvent {
+	return model.AlertEvent{
+		RuleID:    s.rule.ID,
+		RuleName:  s.rule.Name,
+		State:     state,
+		Count:     s.count,
+		Threshold: s.rule.Threshold,
+		WindowSec: s.rule.WindowSec,
+		Since:     s.since,
+		Time:      now,
+		Example:   s.example,
+		Test:      false,
+	}
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository/testrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Уведомления, отправленные движком оповещений
+type testNotifier struct {
+	mutex  sync.Mutex
+	events []model.AlertEvent
+}
+
+func (n *testNotifier) Notify(event model.AlertEvent) {
+	n.mutex.Lock()
+	n.events = append(n.events, event)
+	n.mutex.Unlock()
+}
+
+// Полученные уведомления. Список очищается
+func (n *testNotifier) take() []model.AlertEvent {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	events := n.events
+	n.events = nil
+
+	return events
+}
+
+// Движок оповещений с управляемым временем и правилом: больше 2 ошибок за минуту
+func initAlertTestCase(t *testing.T) (*alertCase, *testNotifier, *time.Time, *model.AlertRule) {
+	t.Helper()
+
+	dbo, err := testrepo.CreateTestlDBO()
+	require.NoError(t, err)
+
+	now := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	notifier := &testNotifier{mutex: sync.Mutex{}, events: nil}
+
+	a, ok := NewAlertCase(testrepo.NewAlert(dbo), notifier).(*alertCase)
+	require.True(t, ok)
+
+	a.now = func() time.Time { return now }
+
+	rule := &model.AlertRule{
+		ID:            0,
+		Name:          "errors",
+		MinLevel:      model.LevelError,
+		Pattern:       "",
+		Threshold:     2,
+		WindowSec:     60,
+		Enabled:       true,
+		SilencedUntil: nil,
+		CreatedAt:     time.Time{},
+	}
+	require.NoError(t, a.Create(context.Background(), testAdmin(), rule))
+
+	return a, notifier, &now, rule
+}
+
+func testAdmin() *model.User {
+	return &model.User{
+		ID:                1,
+		Login:             "admin",
+		Name:              "admin",
+		Role:              model.RoleAdmin,
+		Password:          "",
+		EncryptedPassword: "",
+		Timezone:          "",
+	}
+}
+
+// count записей с уровнем ошибки
+func errorRecords(count int) []model.LogRecord {
+	records := make([]model.LogRecord, count)
+	for i := range records {
+		records[i] = model.LogRecord{
+			ID:       0,
+			LogTime:  time.Time{},
+			RealTime: time.Time{},
+			Level:    model.LevelError,
+			Message1: "failed",
+			Message2: "",
+			Message3: "",
+		}
+	}
+
+	return records
+}
+
+func TestAlert_FiringOncePerEpisode(t *testing.T) {
+	a, notifier, now, rule := initAlertTestCase(t)
+
+	a.Observe(errorRecords(2))
+	assert.Empty(t, notifier.take())
+
+	a.Observe(errorRecords(3))
+	events := notifier.take()
+	require.Len(t, events, 1)
+	assert.Equal(t, model.AlertFiring, events[0].State)
+	assert.Equal(t, rule.ID, events[0].RuleID)
+	assert.Equal(t, 5, events[0].Count)
+	assert.Equal(t, "failed", events[0].Example)
+
+	// пока условие выполняется, повторных уведомлений нет
+	*now = now.Add(10 * time.Second)
+	a.Observe(errorRecords(10))
+	a.Evaluate(*now)
+	assert.Empty(t, notifier.take())
+
+	statuses, err := a.Status(testAdmin())
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, model.AlertFiring, statuses[0].State)
+	assert.Equal(t, 15, statuses[0].Count)
+}
+
+func TestAlert_Resolved(t *testing.T) {
+	a, notifier, now, _ := initAlertTestCase(t)
+
+	a.Observe(errorRecords(3))
+	require.Len(t, notifier.take(), 1)
+
+	// записи выходят из окна
+	*now = now.Add(2 * time.Minute)
+	a.Evaluate(*now)
+
+	events := notifier.take()
+	require.Len(t, events, 1)
+	assert.Equal(t, model.AlertResolved, events[0].State)
+	assert.Equal(t, 0, events[0].Count)
+
+	a.Evaluate(now.Add(time.Minute))
+	assert.Empty(t, notifier.take())
+
+	// новый эпизод снова уведомляет
+	a.Observe(errorRecords(3))
+	events = notifier.take()
+	require.Len(t, events, 1)
+	assert.Equal(t, model.AlertFiring, events[0].State)
+}
+
+func TestAlert_Silenced(t *testing.T) {
+	a, notifier, now, rule := initAlertTestCase(t)
+
+	until := now.Add(time.Minute)
+	_, err := a.Silence(context.Background(), testAdmin(), rule.ID, &until)
+	require.NoError(t, err)
+
+	a.Observe(errorRecords(3))
+	assert.Empty(t, notifier.take())
+
+	// после снятия заглушки уведомление о сработавшем правиле отправляется при следующей проверке
+	*now = now.Add(30 * time.Second)
+	a.Observe(errorRecords(1))
+	assert.Empty(t, notifier.take())
+
+	_, err = a.Silence(context.Background(), testAdmin(), rule.ID, nil)
+	require.NoError(t, err)
+
+	a.Evaluate(*now)
+
+	events := notifier.take()
+	require.Len(t, events, 1)
+	assert.Equal(t, model.AlertFiring, events[0].State)
+	assert.Equal(t, 4, events[0].Count)
+
+	// уведомление о разрешении отправляется, т.к. было уведомление о срабатывании
+	a.Evaluate(now.Add(2 * time.Minute))
+
+	events = notifier.take()
+	require.Len(t, events, 1)
+	assert.Equal(t, model.AlertResolved, events[0].State)
+}
+
+func TestAlert_SilencedEpisodeNotResolved(t *testing.T) {
+	a, notifier, now, rule := initAlertTestCase(t)
+
+	until := now.Add(time.Hour)
+	_, err := a.Silence(context.Background(), testAdmin(), rule.ID, &until)
+	require.NoError(t, err)
+
+	// эпизод целиком прошел под заглушкой: ни срабатывания, ни разрешения
+	a.Observe(errorRecords(3))
+	a.Evaluate(now.Add(2 * time.Minute))
+	assert.Empty(t, notifier.take())
+}
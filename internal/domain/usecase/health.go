@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+type healthCase struct {
+	dbo     repository.DBOInterface
+	logCase LogInterface
+}
+
+func NewHealthCase(dbo repository.DBOInterface, logCase LogInterface) HealthInterface {
+	return &healthCase{
+		dbo:     dbo,
+		logCase: logCase,
+	}
+}
+
+func (h *healthCase) Ready(ctx context.Context) (ready bool, checks []model.HealthCheck) {
+	ready = true
+
+	check := func(name string, err error) {
+		c := model.HealthCheck{
+			Name:  name,
+			OK:    err == nil,
+			Error: "",
+		}
+		if err != nil {
+			c.Error = err.Error()
+			ready = false
+		}
+
+		checks = append(checks, c)
+	}
+
+	// хранилище
+	check("database", h.dbo.Ping(ctx))
+
+	// очередь записи в журнал
+	var ingestErr error
+	if inFlight, limit := h.logCase.IngestLoad(); limit > 0 && inFlight >= limit {
+		ingestErr = fmt.Errorf("ingest queue saturated: %d of %d", inFlight, limit)
+	}
+
+	check("ingest", ingestErr)
+
+	return ready, checks
+}
+
+func (h *healthCase) DBStats() model.DBStats {
+	return h.dbo.Stats()
+}
@@ -3,8 +3,15 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
 	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/metrics"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/repository"
 	"github.com/pkg/errors"
@@ -12,19 +19,218 @@ import (
 
 type logCase struct {
 	RepoLog repository.LogInterface
+
+	// количество выполняемых операций записи
+	ingestInFlight int64
+	// максимальное количество одновременных операций записи
+	ingestLimit int64
+	// получатели добавленных записей (правила оповещений и т.п.)
+	observers []LogObserver
 }
 
-func NewLogCase(r repository.LogInterface) LogInterface {
+// NewLogCase Сценарии журнала. observers получают записи после их успешного добавления
+func NewLogCase(r repository.LogInterface, cfg *config.Config, observers ...LogObserver) LogInterface {
 	return &logCase{
-		RepoLog: r,
+		RepoLog:        r,
+		ingestInFlight: 0,
+		ingestLimit:    int64(cfg.MaxIngestQueue),
+		observers:      observers,
 	}
 }
 
-func (l *logCase) Insert(logs *[]model.LogRecord) error {
-	return errors.Wrap(l.RepoLog.Insert(logs), "insert error")
+func (l *logCase) Insert(ctx context.Context, logs *[]model.LogRecord) error {
+	metrics.BatchSize.Observe(float64(len(*logs)))
+
+	if !l.acquireIngest() {
+		metrics.RecordsRejected.WithLabelValues(metrics.RejectOverload).Add(float64(len(*logs)))
+
+		return errIngestQueueFull
+	}
+	defer atomic.AddInt64(&l.ingestInFlight, -1)
+
+	if err := validateRecords(logs); err != nil {
+		// пачка записывается целиком, поэтому отклоняются все записи
+		metrics.RecordsRejected.WithLabelValues(metrics.RejectValidation).Add(float64(len(*logs)))
+
+		return err
+	}
+
+	start := time.Now()
+	err := l.RepoLog.Insert(ctx, logs)
+	metrics.ObserveQuery("log_insert", start, err)
+
+	if err != nil {
+		metrics.RecordsRejected.WithLabelValues(metrics.RejectStorage).Add(float64(len(*logs)))
+
+		return errors.Wrap(err, "insert error")
+	}
+
+	metrics.RecordsIngested.Add(float64(len(*logs)))
+
+	for _, o := range l.observers {
+		o.Observe(*logs)
+	}
+
+	return nil
 }
 
-func (l *logCase) Find(dateFrom time.Time, dateTo time.Time, limit int) (records *[]model.LogRecord, limited bool, err error) {
-	r, lim, e := l.RepoLog.Find(dateFrom, dateTo, limit)
+func (l *logCase) Import(ctx context.Context, logs *[]model.LogRecord) error {
+	if err := validateRecords(logs); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := l.RepoLog.Insert(ctx, logs)
+	metrics.ObserveQuery("log_import", start, err)
+
+	if err != nil {
+		return errors.Wrap(err, "import error")
+	}
+
+	metrics.RecordsImported.Add(float64(len(*logs)))
+
+	return nil
+}
+
+// Проверка всей пачки до записи, чтобы вернуть ошибки по всем некорректным записям сразу
+func validateRecords(logs *[]model.LogRecord) error {
+	var errs []error
+
+	for i := range *logs {
+		if err := (*logs)[i].Validate(); err != nil {
+			errs = append(errs, apperr.ValidationPrefix(err, fmt.Sprintf("[%d].", i)))
+		} else if (*logs)[i].Message2 == model.SelfLogSource {
+			// источник зарезервирован для записей самого сервера
+			errs = append(errs, apperr.ValidationPrefix(validation.Errors{"message2": errReservedSource}, fmt.Sprintf("[%d].", i)))
+		}
+	}
+
+	return apperr.Join(errs)
+}
+
+func (l *logCase) IngestLoad() (inFlight int, limit int) {
+	return int(atomic.LoadInt64(&l.ingestInFlight)), int(l.ingestLimit)
+}
+
+// Занять место в очереди записи. Возвращает false, если очередь заполнена
+func (l *logCase) acquireIngest() bool {
+	n := atomic.AddInt64(&l.ingestInFlight, 1)
+	if l.ingestLimit > 0 && n > l.ingestLimit {
+		atomic.AddInt64(&l.ingestInFlight, -1)
+
+		return false
+	}
+
+	return true
+}
+
+func (l *logCase) Find(ctx context.Context, dateFrom time.Time, dateTo time.Time, limit int) (
+	records *[]model.LogRecord, limited bool, err error,
+) {
+	start := time.Now()
+	r, lim, e := l.RepoLog.Find(ctx, dateFrom, dateTo, limit)
+	metrics.ObserveQuery("log_find", start, e)
+
 	return r, lim, errors.Wrap(e, "find error")
 }
+
+func (l *logCase) FindPage(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string, limit int) (
+	*model.LogPage, error,
+) {
+	var after *model.LogCursor
+
+	if cursor != "" {
+		var err error
+		if after, err = model.ParseLogCursor(cursor); err != nil {
+			return nil, apperr.Validation(validation.Errors{"cursor": err})
+		}
+	}
+
+	if err := validation.Validate(limit, validation.Min(1)); err != nil {
+		return nil, apperr.Validation(validation.Errors{"limit": err})
+	}
+
+	start := time.Now()
+	records, more, err := l.RepoLog.FindPage(ctx, dateFrom, dateTo, after, limit)
+	metrics.ObserveQuery("log_find_page", start, err)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "find page error")
+	}
+
+	page := &model.LogPage{
+		Records:    *records,
+		NextCursor: "",
+	}
+
+	if more && len(*records) > 0 {
+		last := (*records)[len(*records)-1]
+		page.NextCursor = model.LogCursor{LogTime: last.LogTime, ID: last.ID}.String()
+	}
+
+	return page, nil
+}
+
+func (l *logCase) FindByID(ctx context.Context, id uint64) (*model.LogRecord, error) {
+	start := time.Now()
+	record, err := l.RepoLog.FindByID(ctx, id)
+	metrics.ObserveQuery("log_find_by_id", start, err)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "find by id error")
+	}
+
+	if record == nil {
+		return nil, repository.ErrLogRecordNotFound
+	}
+
+	return record, nil
+}
+
+func (l *logCase) FindContext(ctx context.Context, id uint64, count int) (*model.LogRecordContext, error) {
+	if err := validation.Validate(count, validation.Min(0)); err != nil {
+		return nil, apperr.Validation(validation.Errors{"count": err})
+	}
+
+	record, err := l.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &model.LogRecordContext{
+		Record: *record,
+		Newer:  []model.LogRecord{},
+		Older:  []model.LogRecord{},
+	}
+
+	if count == 0 {
+		return res, nil
+	}
+
+	cursor := model.LogCursor{LogTime: record.LogTime, ID: record.ID}
+
+	start := time.Now()
+	newer, err := l.RepoLog.FindNewer(ctx, cursor, count)
+	metrics.ObserveQuery("log_find_newer", start, err)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "find newer error")
+	}
+
+	// более новые записи приходят по возрастанию времени, а показываются по убыванию
+	for i := len(*newer) - 1; i >= 0; i-- {
+		res.Newer = append(res.Newer, (*newer)[i])
+	}
+
+	start = time.Now()
+	older, _, err := l.RepoLog.FindPage(ctx, time.Time{}, time.Time{}, &cursor, count)
+	metrics.ObserveQuery("log_find_page", start, err)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "find older error")
+	}
+
+	res.Older = *older
+
+	return res, nil
+}
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/metrics"
+	"github.com/n-r-w/log-server/internal/domain/model"
+)
+
+const (
+	// размер очереди уведомлений. При ее заполнении новые уведомления отбрасываются
+	notifyQueueSize = 100
+	// количество хранимых результатов отправки
+	maxNotifyDeliveries = 1000
+	// максимальная пауза между попытками
+	maxNotifyBackoff = time.Minute
+)
+
+type notifyCase struct {
+	senders []NotifySender
+	retries int
+	backoff time.Duration
+	timeout time.Duration
+	queue   chan model.AlertEvent
+
+	mutex      sync.Mutex
+	deliveryID uint64
+	deliveries []model.NotifyDelivery
+}
+
+func NewNotifyCase(senders []NotifySender, cfg *config.Config) NotifyInterface {
+	return &notifyCase{
+		senders:    senders,
+		retries:    cfg.NotifyRetries,
+		backoff:    time.Duration(cfg.NotifyRetryBackoffMs) * time.Millisecond,
+		timeout:    time.Duration(cfg.NotifyTimeoutSec) * time.Second,
+		queue:      make(chan model.AlertEvent, notifyQueueSize),
+		mutex:      sync.Mutex{},
+		deliveryID: 0,
+		deliveries: nil,
+	}
+}
+
+// Notify Поставить уведомление в очередь. Не блокируется
+func (n *notifyCase) Notify(event model.AlertEvent) {
+	if len(n.senders) == 0 {
+		return
+	}
+
+	select {
+	case n.queue <- event:
+	default:
+		for _, sender := range n.senders {
+			metrics.NotifyDeliveries.WithLabelValues(sender.Channel().Name, metrics.NotifyDropped).Inc()
+			n.record(newDelivery(sender, event, 0, errNotifyQueueFull))
+		}
+	}
+}
+
+// Run Отправка уведомлений из очереди. Каналы обрабатываются параллельно,
+// следующее уведомление отправляется после завершения отправки предыдущего во все каналы
+func (n *notifyCase) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-n.queue:
+			var wg sync.WaitGroup
+
+			for _, sender := range n.senders {
+				wg.Add(1)
+
+				go func(sender NotifySender) {
+					defer wg.Done()
+
+					n.deliver(ctx, sender, event)
+				}(sender)
+			}
+
+			wg.Wait()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Channels Настроенные каналы
+func (n *notifyCase) Channels(currentUser *model.User) ([]model.NotifyChannel, error) {
+	if !currentUser.IsAdmin() {
+		return nil, errNotAdmin
+	}
+
+	channels := make([]model.NotifyChannel, 0, len(n.senders))
+	for _, sender := range n.senders {
+		channels = append(channels, sender.Channel())
+	}
+
+	return channels, nil
+}
+
+// Deliveries Журнал доставки, последние отправки первыми
+func (n *notifyCase) Deliveries(currentUser *model.User) ([]model.NotifyDelivery, error) {
+	if !currentUser.IsAdmin() {
+		return nil, errNotAdmin
+	}
+
+	n.mutex.Lock()
+	deliveries := make([]model.NotifyDelivery, 0, len(n.deliveries))
+	for i := len(n.deliveries) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, n.deliveries[i])
+	}
+	n.mutex.Unlock()
+
+	return deliveries, nil
+}
+
+// Test Отправить тестовое уведомление в канал
+func (n *notifyCase) Test(ctx context.Context, currentUser *model.User, channel string) (*model.NotifyDelivery, error) {
+	if !currentUser.IsAdmin() {
+		return nil, errNotAdmin
+	}
+
+	for _, sender := range n.senders {
+		if sender.Channel().Name != channel {
+			continue
+		}
+
+		now := time.Now().UTC()
+		event := model.AlertEvent{
+			RuleID:    0,
+			RuleName:  "test",
+			State:     model.AlertFiring,
+			Count:     1,
+			Threshold: 0,
+			WindowSec: 0,
+			Since:     now,
+			Time:      now,
+			Example:   "test notification from " + currentUser.Login,
+			Test:      true,
+		}
+
+		delivery := n.finish(sender, event, 1, n.send(ctx, sender, event))
+
+		return &delivery, nil
+	}
+
+	return nil, errChannelNotFound
+}
+
+// Отправка уведомления в канал с повторными попытками
+func (n *notifyCase) deliver(ctx context.Context, sender NotifySender, event model.AlertEvent) {
+	backoff := n.backoff
+
+	var err error
+
+	for attempt := 1; attempt <= n.retries+1; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				n.finish(sender, event, attempt-1, err)
+
+				return
+			}
+
+			if backoff *= 2; backoff > maxNotifyBackoff {
+				backoff = maxNotifyBackoff
+			}
+		}
+
+		if err = n.send(ctx, sender, event); err == nil {
+			n.finish(sender, event, attempt, nil)
+
+			return
+		}
+	}
+
+	n.finish(sender, event, n.retries+1, err)
+}
+
+// Завершение отправки: метрика и запись в журнале доставки. err - ошибка последней попытки
+func (n *notifyCase) finish(sender NotifySender, event model.AlertEvent, attempts int, err error) model.NotifyDelivery {
+	result := metrics.NotifyDelivered
+	if err != nil {
+		result = metrics.NotifyFailed
+	}
+
+	metrics.NotifyDeliveries.WithLabelValues(sender.Channel().Name, result).Inc()
+
+	return n.record(newDelivery(sender, event, attempts, err))
+}
+
+// Одна попытка отправки с таймаутом
+func (n *notifyCase) send(ctx context.Context, sender NotifySender, event model.AlertEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	return sender.Send(ctx, event) //nolint:wrapcheck
+}
+
+// Сохранение результата отправки в журнале доставки
+func (n *notifyCase) record(delivery model.NotifyDelivery) model.NotifyDelivery {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.deliveryID++
+	delivery.ID = n.deliveryID
+
+	n.deliveries = append(n.deliveries, delivery)
+	if len(n.deliveries) > maxNotifyDeliveries {
+		n.deliveries = n.deliveries[len(n.deliveries)-maxNotifyDeliveries:]
+	}
+
+	return delivery
+}
+
+func newDelivery(sender NotifySender, event model.AlertEvent, attempts int, err error) model.NotifyDelivery {
+	delivery := model.NotifyDelivery{
+		ID:        0,
+		Channel:   sender.Channel().Name,
+		RuleID:    event.RuleID,
+		RuleName:  event.RuleName,
+		State:     event.State,
+		Test:      event.Test,
+		Delivered: err == nil,
+		Attempts:  attempts,
+		Error:     "",
+		Time:      time.Now().UTC(),
+	}
+
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	return delivery
+}
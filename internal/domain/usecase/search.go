@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+	"github.com/pkg/errors"
+)
+
+type searchCase struct {
+	searchRepo repository.SearchInterface
+}
+
+func NewSearchCase(searchRepo repository.SearchInterface) SearchInterface {
+	return &searchCase{
+		searchRepo: searchRepo,
+	}
+}
+
+// Create Сохранить поиск текущего пользователя
+func (s *searchCase) Create(ctx context.Context, currentUser *model.User, search *model.SavedSearch) error {
+	search.ID = 0
+	search.UserID = currentUser.ID
+	search.CreatedAt = time.Time{}
+	search.Prepare()
+
+	return s.searchRepo.Insert(ctx, search) //nolint:wrapcheck
+}
+
+// Update Изменить поиск. Владелец поиска не меняется
+func (s *searchCase) Update(ctx context.Context, currentUser *model.User, search *model.SavedSearch,
+	shared *bool,
+) error {
+	existing, err := s.editable(ctx, currentUser, search.ID)
+	if err != nil {
+		return err
+	}
+
+	search.Prepare()
+
+	if search.Name != "" {
+		existing.Name = search.Name
+	}
+
+	if search.Query != "" {
+		existing.Query = search.Query
+	}
+
+	if shared != nil {
+		existing.Shared = *shared
+	}
+
+	if err := s.searchRepo.Update(ctx, existing); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	*search = *existing
+
+	return nil
+}
+
+// Remove Удалить поиск
+func (s *searchCase) Remove(ctx context.Context, currentUser *model.User, searchID uint64) error {
+	if _, err := s.editable(ctx, currentUser, searchID); err != nil {
+		return err
+	}
+
+	return s.searchRepo.Remove(ctx, searchID) //nolint:wrapcheck
+}
+
+// Get Свой или общий поиск по ID
+func (s *searchCase) Get(ctx context.Context, currentUser *model.User, searchID uint64) (*model.SavedSearch, error) {
+	search, err := s.searchRepo.FindByID(ctx, searchID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find search error")
+	}
+
+	// чужой личный поиск выглядит как несуществующий
+	if search == nil || (search.UserID != currentUser.ID && !search.Shared) {
+		return nil, repository.ErrSearchNotFound
+	}
+
+	return search, nil
+}
+
+// List Свои и общие поиски
+func (s *searchCase) List(ctx context.Context, currentUser *model.User) (*[]model.SavedSearch, error) {
+	return s.searchRepo.GetByUser(ctx, currentUser.ID) //nolint:wrapcheck
+}
+
+// Поиск, который текущий пользователь может менять: свой или, для админа, общий
+func (s *searchCase) editable(ctx context.Context, currentUser *model.User, searchID uint64) (
+	*model.SavedSearch, error,
+) {
+	search, err := s.Get(ctx, currentUser, searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if search.UserID != currentUser.ID && !currentUser.IsAdmin() {
+		return nil, errNotOwner
+	}
+
+	return search, nil
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// Количество случайных байт в секрете (токен API, идентификатор сессии)
+const secretBytes = 32
+
+// Новый случайный секрет с префиксом
+func newSecret(prefix string) (string, error) {
+	random := make([]byte, secretBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.Wrap(err, "secret generation error")
+	}
+
+	return prefix + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// Хэш секрета для хранения в БД. Секрет случайный и длинный, поэтому соль и медленный хэш не нужны
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+	"github.com/pkg/errors"
+)
+
+// Максимальная длина сохраняемого User-Agent
+const maxUserAgentLength = 256
+
+type sessionCase struct {
+	sessionRepo repository.SessionInterface
+	userRepo    repository.UserInterface
+	config      *config.Config
+}
+
+func NewSessionCase(sessionRepo repository.SessionInterface, userRepo repository.UserInterface,
+	cfg *config.Config,
+) SessionInterface {
+	return &sessionCase{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		config:      cfg,
+	}
+}
+
+// Create Создать сессию. Время жизни берется из SESSION_AGE
+func (s *sessionCase) Create(ctx context.Context, userID uint64, remoteAddr string, userAgent string) (
+	secret string, err error,
+) {
+	if secret, err = newSecret(""); err != nil {
+		return "", err
+	}
+
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session := &model.Session{
+		ID:         0,
+		UserID:     userID,
+		CreatedAt:  time.Time{},
+		LastSeenAt: time.Time{},
+		ExpiresAt:  time.Now().Add(time.Duration(s.config.Runtime().SessionAge) * time.Second),
+		RemoteAddr: remoteAddr,
+		UserAgent:  userAgent,
+		Current:    false,
+	}
+
+	if err := s.sessionRepo.Insert(ctx, session, hashSecret(secret)); err != nil {
+		return "", errors.Wrap(err, "insert session error")
+	}
+
+	return secret, nil
+}
+
+// Authenticate Пользователь действующей сессии
+func (s *sessionCase) Authenticate(ctx context.Context, secret string) (*model.User, error) {
+	if secret == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	session, err := s.sessionRepo.FindByHash(ctx, hashSecret(secret))
+	if err != nil || session == nil {
+		return nil, errors.Wrap(err, "find session error")
+	}
+
+	return s.userRepo.FindByID(ctx, session.UserID) //nolint:wrapcheck
+}
+
+// Close Завершить сессию. Несуществующая сессия ошибкой не считается
+func (s *sessionCase) Close(ctx context.Context, secret string) error {
+	session, err := s.current(ctx, secret)
+	if err != nil || session == nil {
+		return err
+	}
+
+	if err := s.sessionRepo.Remove(ctx, session.ID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		return errors.Wrap(err, "remove session error")
+	}
+
+	return nil
+}
+
+// List Сессии текущего пользователя
+func (s *sessionCase) List(ctx context.Context, currentUser *model.User, currentSecret string) (
+	*[]model.Session, error,
+) {
+	sessions, err := s.sessionRepo.GetByUser(ctx, currentUser.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get sessions error")
+	}
+
+	current, err := s.current(ctx, currentSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		for i := range *sessions {
+			(*sessions)[i].Current = (*sessions)[i].ID == current.ID
+		}
+	}
+
+	return sessions, nil
+}
+
+// Revoke Завершить сессию текущего пользователя. Чужая сессия выглядит как несуществующая
+func (s *sessionCase) Revoke(ctx context.Context, currentUser *model.User, sessionID uint64) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, "find session error")
+	}
+
+	if session == nil || session.UserID != currentUser.ID {
+		return repository.ErrSessionNotFound
+	}
+
+	return s.sessionRepo.Remove(ctx, sessionID) //nolint:wrapcheck
+}
+
+// RevokeOthers Завершить все сессии текущего пользователя, кроме сессии currentSecret
+func (s *sessionCase) RevokeOthers(ctx context.Context, currentUser *model.User, currentSecret string) error {
+	current, err := s.current(ctx, currentSecret)
+	if err != nil {
+		return err
+	}
+
+	var exceptID uint64
+	if current != nil {
+		exceptID = current.ID
+	}
+
+	return s.sessionRepo.RemoveByUser(ctx, currentUser.ID, exceptID) //nolint:wrapcheck
+}
+
+// Сессия по секрету. nil, если секрет пустой или сессия не найдена
+func (s *sessionCase) current(ctx context.Context, secret string) (*model.Session, error) {
+	if secret == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	session, err := s.sessionRepo.FindByHash(ctx, hashSecret(secret))
+
+	return session, errors.Wrap(err, "find session error")
+}
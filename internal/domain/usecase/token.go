@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Префикс токена, чтобы его можно было опознать (например, при поиске утечек в исходниках)
+	tokenPrefix = "lst_"
+	// Максимальная длина названия токена
+	maxTokenNameLength = 100
+)
+
+type tokenCase struct {
+	tokenRepo repository.TokenInterface
+	userRepo  repository.UserInterface
+	admin     model.SuperAdmin
+}
+
+func NewTokenCase(tokenRepo repository.TokenInterface, userRepo repository.UserInterface,
+	cfg *config.Config,
+) TokenInterface {
+	return &tokenCase{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		admin:     cfg.SuperAdmin(),
+	}
+}
+
+// Create Создать токен
+func (t *tokenCase) Create(ctx context.Context, currentUser *model.User, userID uint64, name string) (
+	secret string, token *model.APIToken, err error,
+) {
+	if currentUser.ID != userID && !currentUser.IsAdmin() {
+		return "", nil, errNotAdmin
+	}
+
+	if t.admin.IsAdmin(userID) {
+		// встроенного админа нет в БД
+		return "", nil, errAdminToken
+	}
+
+	name = strings.TrimSpace(name)
+	if err := validation.Validate(name, validation.Required, validation.Length(1, maxTokenNameLength)); err != nil {
+		return "", nil, apperr.Validation(validation.Errors{"name": err})
+	}
+
+	user, err := t.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "find user error")
+	}
+
+	if user == nil {
+		return "", nil, errUserNotFound
+	}
+
+	if secret, err = newSecret(tokenPrefix); err != nil {
+		return "", nil, err
+	}
+
+	token = &model.APIToken{
+		ID:         0,
+		UserID:     userID,
+		Name:       name,
+		CreatedAt:  time.Time{},
+		LastUsedAt: nil,
+	}
+
+	if err := t.tokenRepo.Insert(ctx, token, hashSecret(secret)); err != nil {
+		return "", nil, errors.Wrap(err, "insert token error")
+	}
+
+	return secret, token, nil
+}
+
+// Revoke Отозвать токен. Свои токены может отозвать любой пользователь, чужие - только админ
+func (t *tokenCase) Revoke(ctx context.Context, currentUser *model.User, tokenID uint64) error {
+	token, err := t.tokenRepo.FindByID(ctx, tokenID)
+	if err != nil {
+		return errors.Wrap(err, "find token error")
+	}
+
+	// чужой токен для не админа выглядит как несуществующий
+	if token == nil || (token.UserID != currentUser.ID && !currentUser.IsAdmin()) {
+		return repository.ErrTokenNotFound
+	}
+
+	return t.tokenRepo.Remove(ctx, tokenID) //nolint:wrapcheck
+}
+
+// List Токены пользователя
+func (t *tokenCase) List(ctx context.Context, currentUser *model.User, userID uint64) (*[]model.APIToken, error) {
+	if currentUser.ID != userID && !currentUser.IsAdmin() {
+		return nil, errNotAdmin
+	}
+
+	return t.tokenRepo.GetByUser(ctx, userID) //nolint:wrapcheck
+}
+
+// Authenticate Пользователь, которому принадлежит токен
+func (t *tokenCase) Authenticate(ctx context.Context, secret string) (*model.User, error) {
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return nil, nil //nolint:nilnil
+	}
+
+	token, err := t.tokenRepo.FindByHash(ctx, hashSecret(secret))
+	if err != nil || token == nil {
+		return nil, errors.Wrap(err, "find token error")
+	}
+
+	return t.userRepo.FindByID(ctx, token.UserID) //nolint:wrapcheck
+}
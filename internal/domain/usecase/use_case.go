@@ -5,38 +5,186 @@
 package usecase
 
 import (
-	"errors"
+	"context"
 	"time"
 
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
 )
 
 // Сейчас операции совпадают с аналогичными интерфейсами в репозитории, но это от того, что такие задачи
 // В репозитории должны быть только базовые операции работы с БД, а тут они должны комбинироваться для
 // решения составных задач
 
+// Контекст ctx передается из слоя presentation (например, контекст HTTP запроса) вплоть до репозитория.
+// Он несет в себе таймаут, признак отмены запроса клиентом и номер запроса для журналирования
+
 type UserInterface interface {
 	// CheckPassword Проверить пароль
-	CheckPassword(login string, password string) (ID uint64, err error)
-	// ChangePassword Сменить пароль
-	ChangePassword(currentUser *model.User, login string, password string) (ID uint64, err error)
+	CheckPassword(ctx context.Context, login string, password string) (ID uint64, err error)
+	// ChangePassword Сменить пароль другого пользователя (только для администратора).
+	// Собственный пароль меняется через ChangeOwnPassword с проверкой текущего
+	ChangePassword(ctx context.Context, currentUser *model.User, login string, password string) (ID uint64, err error)
+	// ChangeOwnPassword Сменить собственный пароль с проверкой текущего
+	ChangeOwnPassword(ctx context.Context, currentUser *model.User, currentPassword string, password string) error
+	// SetTimezone Изменить собственный часовой пояс (IANA). Пустая строка - часовой пояс браузера
+	SetTimezone(ctx context.Context, currentUser *model.User, timezone string) error
+
+	Insert(ctx context.Context, user *model.User) error
+	Remove(ctx context.Context, id uint64) error
+	Update(ctx context.Context, user *model.User) error
+
+	FindByID(ctx context.Context, id uint64) (*model.User, error)
+	FindByLogin(ctx context.Context, login string) (*model.User, error)
+	GetUsers(ctx context.Context) (*[]model.User, error)
+}
+
+// TokenInterface Токены доступа к API (заголовок Authorization: Bearer <токен>)
+type TokenInterface interface {
+	// Create Создать токен для пользователя userID. Возвращает сам токен, который больше нигде не сохраняется.
+	// Себе токен может создать любой пользователь, другим - только админ
+	Create(ctx context.Context, currentUser *model.User, userID uint64, name string) (
+		secret string, token *model.APIToken, err error)
+	// Revoke Отозвать токен
+	Revoke(ctx context.Context, currentUser *model.User, tokenID uint64) error
+	// List Токены пользователя userID
+	List(ctx context.Context, currentUser *model.User, userID uint64) (*[]model.APIToken, error)
+	// Authenticate Пользователь, которому принадлежит токен. nil, если токен не найден
+	Authenticate(ctx context.Context, secret string) (*model.User, error)
+}
+
+// SessionInterface Сессии пользователей, открытые логином по паролю. Идентификатор сессии (секрет)
+// передается клиенту в куки
+type SessionInterface interface {
+	// Create Создать сессию. Возвращает секрет, который больше нигде не сохраняется
+	Create(ctx context.Context, userID uint64, remoteAddr string, userAgent string) (secret string, err error)
+	// Authenticate Пользователь действующей сессии. nil, если сессия не найдена или истекла
+	Authenticate(ctx context.Context, secret string) (*model.User, error)
+	// Close Завершить сессию (выход)
+	Close(ctx context.Context, secret string) error
+	// List Действующие сессии текущего пользователя. Сессия currentSecret отмечается признаком Current
+	List(ctx context.Context, currentUser *model.User, currentSecret string) (*[]model.Session, error)
+	// Revoke Завершить сессию текущего пользователя
+	Revoke(ctx context.Context, currentUser *model.User, sessionID uint64) error
+	// RevokeOthers Завершить все сессии текущего пользователя, кроме сессии currentSecret
+	RevokeOthers(ctx context.Context, currentUser *model.User, currentSecret string) error
+}
+
+// SearchInterface Сохраненные поиски по журналу. Общие поиски видят все пользователи,
+// менять и удалять поиск может его владелец или админ
+type SearchInterface interface {
+	// Create Сохранить поиск текущего пользователя. ID и время создания прописываются в модель
+	Create(ctx context.Context, currentUser *model.User, search *model.SavedSearch) error
+	// Update Изменить поиск. Незаполненные название и запрос не меняются, shared - признак общего поиска (nil - не меняется).
+	// В модель прописывается поиск после изменения
+	Update(ctx context.Context, currentUser *model.User, search *model.SavedSearch, shared *bool) error
+	// Remove Удалить поиск
+	Remove(ctx context.Context, currentUser *model.User, searchID uint64) error
+	// Get Свой или общий поиск по ID
+	Get(ctx context.Context, currentUser *model.User, searchID uint64) (*model.SavedSearch, error)
+	// List Свои и общие поиски
+	List(ctx context.Context, currentUser *model.User) (*[]model.SavedSearch, error)
+}
+
+// LogObserver Получатель записей, успешно добавленных в журнал через LogInterface.Insert.
+// Вызывается в горутине запроса на запись, поэтому не должен блокироваться
+type LogObserver interface {
+	Observe(records []model.LogRecord)
+}
+
+// AlertNotifier Отправка уведомлений правил оповещений. Не должен блокироваться надолго:
+// вызывается из потока записи в журнал и периодической проверки правил
+type AlertNotifier interface {
+	Notify(event model.AlertEvent)
+}
 
-	Insert(user *model.User) error
-	Remove(id uint64) error
-	Update(user *model.User) error
+// AlertInterface Правила оповещений. Правила проверяются на потоке записей, добавляемых в журнал,
+// состояние правил (окна, срабатывания) хранится в памяти. Управлять правилами может только админ
+type AlertInterface interface {
+	LogObserver
+
+	// Load Загрузить правила из хранилища (при запуске сервера)
+	Load(ctx context.Context) error
+	// Evaluate Проверка правил в момент now: истечение окон, разрешение оповещений и уведомления
+	// о срабатываниях, которые были заглушены
+	Evaluate(now time.Time)
+
+	// Create Создать правило. ID и время создания прописываются в модель
+	Create(ctx context.Context, currentUser *model.User, rule *model.AlertRule) error
+	// Update Изменить правило. Состояние правила сбрасывается
+	Update(ctx context.Context, currentUser *model.User, rule *model.AlertRule) error
+	// Remove Удалить правило
+	Remove(ctx context.Context, currentUser *model.User, ruleID uint64) error
+	// Silence Заглушить уведомления правила до until (nil - снять заглушку)
+	Silence(ctx context.Context, currentUser *model.User, ruleID uint64, until *time.Time) (*model.AlertRule, error)
+	// List Все правила
+	List(ctx context.Context, currentUser *model.User) (*[]model.AlertRule, error)
+	// Status Текущее состояние правил
+	Status(currentUser *model.User) ([]model.AlertStatus, error)
+}
 
-	FindByID(id uint64) (*model.User, error)
-	FindByLogin(login string) (*model.User, error)
-	GetUsers() (*[]model.User, error)
+// NotifySender Канал уведомлений (webhook, email и т.п.). Реализации находятся в слое app
+type NotifySender interface {
+	// Channel Описание канала
+	Channel() model.NotifyChannel
+	// Send Однократная отправка уведомления. Повторные попытки выполняет NotifyInterface
+	Send(ctx context.Context, event model.AlertEvent) error
+}
+
+// NotifyInterface Отправка уведомлений правил оповещений во внешние каналы. Уведомления ставятся в очередь
+// и отправляются в фоне с повторными попытками, результаты отправки сохраняются в журнале доставки в памяти
+type NotifyInterface interface {
+	AlertNotifier
+
+	// Run Отправка уведомлений из очереди до отмены ctx
+	Run(ctx context.Context)
+	// Channels Настроенные каналы
+	Channels(currentUser *model.User) ([]model.NotifyChannel, error)
+	// Deliveries Журнал доставки, последние отправки первыми
+	Deliveries(currentUser *model.User) ([]model.NotifyDelivery, error)
+	// Test Отправить тестовое уведомление в канал (одна попытка, без очереди)
+	Test(ctx context.Context, currentUser *model.User, channel string) (*model.NotifyDelivery, error)
 }
 
 type LogInterface interface {
-	Insert(logs *[]model.LogRecord) error
+	Insert(ctx context.Context, logs *[]model.LogRecord) error
+	// Import Добавить пачку исторических записей при загрузке из файла. Записи проверяются так же, как в Insert,
+	// но не ограничиваются очередью записи и не передаются получателям (правила оповещений, пересылка)
+	Import(ctx context.Context, logs *[]model.LogRecord) error
+	// IngestLoad Количество выполняемых в данный момент операций записи и их допустимый максимум (0 - без ограничения)
+	IngestLoad() (inFlight int, limit int)
+
+	Find(ctx context.Context, dateFrom time.Time, dateTo time.Time, limit int) (
+		records *[]model.LogRecord, limited bool, err error)
+	// FindPage Страница записей в порядке убывания времени. cursor - NextCursor предыдущей страницы (пустой - с начала)
+	FindPage(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string, limit int) (
+		*model.LogPage, error)
+	// FindByID Запись журнала по ID
+	FindByID(ctx context.Context, id uint64) (*model.LogRecord, error)
+	// FindContext Запись журнала по ID и до count соседних записей с каждой стороны
+	FindContext(ctx context.Context, id uint64, count int) (*model.LogRecordContext, error)
+}
 
-	Find(dateFrom time.Time, dateTo time.Time, limit int) (records *[]model.LogRecord, limited bool, err error)
+// HealthInterface Проверка состояния сервиса
+type HealthInterface interface {
+	// Ready Готов ли сервис к обработке запросов. Возвращает результаты отдельных проверок
+	Ready(ctx context.Context) (ready bool, checks []model.HealthCheck)
+	// DBStats Состояние пула соединений хранилища
+	DBStats() model.DBStats
 }
 
 var (
-	errNotAdmin     = errors.New("not admin user")
-	errUserNotFound = errors.New("user not found")
+	errNotAdmin          = apperr.New(apperr.KindForbidden, "not admin user")
+	errUserNotFound      = apperr.New(apperr.KindNotFound, "user not found")
+	errIncorrectPassword = apperr.New(apperr.KindForbidden, "incorrect login or password")
+	errIngestQueueFull   = apperr.New(apperr.KindUnavailable, "ingest queue is full")
+	errReservedSource    = errors.New("source is reserved for server records")
+	errAdminToken        = apperr.New(apperr.KindForbidden, "tokens can't be issued to the built-in admin")
+	errWrongPassword     = errors.New("incorrect password")
+	errNotOwner          = apperr.New(apperr.KindForbidden, "not owner")
+	errOwnPassword       = apperr.New(apperr.KindForbidden,
+		"own password is changed with the current password via /api/private/account/password")
+	errChannelNotFound = apperr.New(apperr.KindNotFound, "notification channel not found")
+	errNotifyQueueFull = errors.New("notification queue is full")
 )
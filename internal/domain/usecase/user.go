@@ -5,8 +5,11 @@
 package usecase
 
 import (
+	"context"
 	"strings"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/repository"
@@ -14,81 +17,160 @@ import (
 )
 
 type userCase struct {
-	UserRepo repository.UserInterface
+	UserRepo    repository.UserInterface
+	sessionRepo repository.SessionInterface
+	admin       model.SuperAdmin
 }
 
-func NewUserCase(r repository.UserInterface) UserInterface {
+func NewUserCase(r repository.UserInterface, sessionRepo repository.SessionInterface,
+	cfg *config.Config,
+) UserInterface {
 	return &userCase{
-		UserRepo: r,
+		UserRepo:    r,
+		sessionRepo: sessionRepo,
+		admin:       cfg.SuperAdmin(),
 	}
 }
 
 // CheckPassword Проверить пароль
-func (u *userCase) CheckPassword(login string, password string) (ID uint64, err error) {
+func (u *userCase) CheckPassword(ctx context.Context, login string, password string) (ID uint64, err error) {
 	// ищем в БД по логину
-	user, err := u.UserRepo.FindByLogin(login)
+	user, err := u.UserRepo.FindByLogin(ctx, login)
 	if err != nil {
 		return 0, err
 	}
 	// проверяем наличие пользователя в БД и пароль
-	if u == nil || !user.ComparePassword(password) {
-		return 0, errors.New("incorrect email or password")
+	if user == nil || !user.ComparePassword(password) {
+		return 0, errIncorrectPassword
 	}
 
 	return user.ID, nil
 }
 
-// ChangePassword Проверить пароль
-func (u *userCase) ChangePassword(currentUser *model.User, login string, password string) (ID uint64, err error) {
+// ChangePassword Сменить пароль другого пользователя. Все сессии пользователя завершаются
+func (u *userCase) ChangePassword(ctx context.Context, currentUser *model.User, login string, password string) (
+	ID uint64, err error,
+) {
 	login = strings.TrimSpace(login)
 	password = strings.TrimSpace(password)
-	changeSelf := currentUser.Login == login
 
-	var id uint64
+	// без проверки текущего пароля собственный пароль мог бы сменить любой, получивший сессию или токен
+	if currentUser.Login == login {
+		return 0, errOwnPassword
+	}
 
-	if !changeSelf {
-		if currentUser.ID != config.AppConfig.SuperAdminID {
-			// если не админ, то менять можно только себе
-			return 0, errNotAdmin
-		}
+	if !currentUser.IsAdmin() {
+		return 0, errNotAdmin
+	}
 
-		user, err := u.FindByLogin(login)
-		if err != nil {
-			return 0, err
-		}
+	user, err := u.FindByLogin(ctx, login)
+	if err != nil {
+		return 0, err
+	}
 
-		if user == nil {
-			return 0, errUserNotFound
-		}
+	if user == nil {
+		return 0, errUserNotFound
+	}
 
-		id = user.ID
-	} else {
-		id = currentUser.ID
+	if err := u.UserRepo.ChangePassword(ctx, user.ID, password); err != nil {
+		return user.ID, errors.Wrap(err, "change password error")
 	}
 
-	return id, errors.Wrap(u.UserRepo.ChangePassword(id, password), "change password error")
+	// пароль, измененный админом, мог быть скомпрометирован: завершаем все сессии пользователя
+	if err := u.sessionRepo.RemoveByUser(ctx, user.ID, 0); err != nil {
+		return user.ID, errors.Wrap(err, "remove sessions error")
+	}
+
+	return user.ID, nil
 }
 
-func (u *userCase) Insert(user *model.User) error {
-	return u.UserRepo.Insert(user) //nolint:wrapcheck
+// ChangeOwnPassword Сменить собственный пароль с проверкой текущего
+func (u *userCase) ChangeOwnPassword(ctx context.Context, currentUser *model.User, currentPassword string,
+	password string,
+) error {
+	if u.admin.IsAdmin(currentUser.ID) {
+		return repository.ErrCantChangeAdminPassword
+	}
+
+	// в модели из контекста запроса может не быть хэша пароля, поэтому берем из хранилища
+	user, err := u.UserRepo.FindByID(ctx, currentUser.ID)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if user == nil {
+		return errUserNotFound
+	}
+
+	if !user.ComparePassword(currentPassword) {
+		return apperr.Validation(validation.Errors{"currentPassword": errWrongPassword})
+	}
+
+	return errors.Wrap(u.UserRepo.ChangePassword(ctx, user.ID, password), "change password error")
 }
 
-func (u *userCase) Remove(id uint64) error {
-	return u.UserRepo.Remove(id) //nolint:wrapcheck
+// SetTimezone Изменить собственный часовой пояс. Пустая строка - часовой пояс браузера
+func (u *userCase) SetTimezone(ctx context.Context, currentUser *model.User, timezone string) error {
+	if u.admin.IsAdmin(currentUser.ID) {
+		return repository.ErrCantChangeAdminUser
+	}
+
+	user, err := u.UserRepo.FindByID(ctx, currentUser.ID)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if user == nil {
+		return errUserNotFound
+	}
+
+	user.Timezone = strings.TrimSpace(timezone)
+
+	return u.UserRepo.Update(ctx, user) //nolint:wrapcheck
 }
 
-func (u *userCase) Update(user *model.User) error {
-	return u.UserRepo.Update(user) //nolint:wrapcheck
+func (u *userCase) Insert(ctx context.Context, user *model.User) error {
+	return u.UserRepo.Insert(ctx, user) //nolint:wrapcheck
+}
+
+func (u *userCase) Remove(ctx context.Context, id uint64) error {
+	return u.UserRepo.Remove(ctx, id) //nolint:wrapcheck
+}
+
+// Update Изменить логин, имя и роль пользователя. Незаполненные поля не меняются
+func (u *userCase) Update(ctx context.Context, user *model.User) error {
+	existing, err := u.UserRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if existing == nil {
+		return errUserNotFound
+	}
+
+	if login := strings.TrimSpace(user.Login); login != "" {
+		existing.Login = login
+	}
+
+	if name := strings.TrimSpace(user.Name); name != "" {
+		existing.Name = name
+	}
+
+	if user.Role != "" {
+		existing.Role = user.Role
+	}
+
+	return u.UserRepo.Update(ctx, existing) //nolint:wrapcheck
 }
 
-func (u *userCase) FindByID(id uint64) (*model.User, error) {
-	return u.UserRepo.FindByID(id) //nolint:wrapcheck
+func (u *userCase) FindByID(ctx context.Context, id uint64) (*model.User, error) {
+	return u.UserRepo.FindByID(ctx, id) //nolint:wrapcheck
 }
 
-func (u *userCase) FindByLogin(login string) (*model.User, error) {
-	return u.UserRepo.FindByLogin(login) //nolint:wrapcheck
+func (u *userCase) FindByLogin(ctx context.Context, login string) (*model.User, error) {
+	return u.UserRepo.FindByLogin(ctx, login) //nolint:wrapcheck
 }
 
-func (u *userCase) GetUsers() (*[]model.User, error) {
-	return u.UserRepo.GetUsers() //nolint:wrapcheck
+func (u *userCase) GetUsers(ctx context.Context) (*[]model.User, error) {
+	return u.UserRepo.GetUsers(ctx) //nolint:wrapcheck
 }
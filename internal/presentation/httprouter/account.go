@@ -0,0 +1,119 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Идентификатор сессии из куки запроса. Пустой, если пользователь аутентифицирован иначе (токен, сертификат)
+func (router *HTTPRouter) requestSessionSecret(r *http.Request) string {
+	session, err := router.sessionStore.Get(r, SessionName)
+	if err != nil {
+		return ""
+	}
+
+	return sessionSecret(session)
+}
+
+// Сменить собственный пароль с проверкой текущего. Остальные сессии пользователя завершаются
+func (router *HTTPRouter) changeOwnPassword() http.HandlerFunc {
+	type request struct {
+		CurrentPassword string `json:"currentPassword"`
+		Password        string `json:"password"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{
+			CurrentPassword: "",
+			Password:        "",
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		user := currentUser(r)
+
+		if err := router.domain.UserUsecase.ChangeOwnPassword(r.Context(), user, req.CurrentPassword,
+			req.Password); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		if err := router.domain.SessionUsecase.RevokeOthers(r.Context(), user,
+			router.requestSessionSecret(r)); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Пароль изменен, остальные сессии завершены"))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// Изменить собственный часовой пояс веб интерфейса
+func (router *HTTPRouter) setOwnTimezone() http.HandlerFunc {
+	type request struct {
+		Timezone string `json:"timezone"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{
+			Timezone: "",
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := router.domain.UserUsecase.SetTimezone(r.Context(), currentUser(r), req.Timezone); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Часовой пояс изменен"))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// Действующие сессии текущего пользователя
+func (router *HTTPRouter) getAccountSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessions, err := router.domain.SessionUsecase.List(r.Context(), currentUser(r), router.requestSessionSecret(r))
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, sessions)
+	}
+}
+
+// Завершить сессию текущего пользователя
+func (router *HTTPRouter) revokeSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := router.domain.SessionUsecase.Revoke(r.Context(), currentUser(r), sessionID); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Сессия завершена"))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
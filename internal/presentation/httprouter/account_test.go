@@ -0,0 +1,105 @@
+package httprouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_Account(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	password := u.Password
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	// две сессии одного пользователя
+	current := srv.SessionCookie(t, u.ID)
+	other := srv.SessionCookie(t, u.ID)
+
+	request := func(method, path, body string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.AddCookie(cookie)
+
+		return srv.Serve(req)
+	}
+
+	rec := request(http.MethodGet, "/api/private/account/sessions", "", current)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var sessions []model.Session
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&sessions))
+	require.Len(t, sessions, 2)
+	assert.True(t, sessions[0].Current)
+	assert.False(t, sessions[1].Current)
+
+	// неверный текущий пароль
+	rec = request(http.MethodPut, "/api/private/account/password",
+		`{"currentPassword": "wrong", "password": "new password"}`, current)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "currentPassword")
+
+	// смена пароля завершает остальные сессии
+	rec = request(http.MethodPut, "/api/private/account/password",
+		fmt.Sprintf(`{"currentPassword": %q, "password": "new password"}`, password), current)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/private/whoami", "", other).Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/private/whoami", "", current).Code)
+
+	_, err := srv.Domain.UserUsecase.CheckPassword(context.Background(), u.Login, "new password")
+	assert.NoError(t, err)
+
+	// завершение своей сессии
+	other = srv.SessionCookie(t, u.ID)
+	rec = request(http.MethodGet, "/api/private/account/sessions", "", current)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&sessions))
+	require.Len(t, sessions, 2)
+
+	path := fmt.Sprintf("/api/private/account/sessions/%d", sessions[1].ID)
+	assert.Equal(t, http.StatusNotFound,
+		request(http.MethodDelete, path, "", srv.SessionCookie(t, srv.Config.SuperAdminID)).Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, path, "", current).Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/private/whoami", "", other).Code)
+
+	// страница личного кабинета
+	rec = request(http.MethodGet, "/login", "", current)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Смена пароля")
+	assert.Contains(t, rec.Body.String(), "Текущая")
+
+	// часовой пояс пользователя
+	rec = request(http.MethodPut, "/api/private/account/timezone", `{"timezone": "Mars/Olympus"}`, current)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(http.MethodPut, "/api/private/account/timezone", `{"timezone": "Europe/Moscow"}`, current)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	updated, err := srv.UserRepo.FindByID(context.Background(), u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Moscow", updated.Timezone)
+
+	rec = request(http.MethodGet, "/search?from=2022-04-23T09:00&to=2022-04-23T12:00", "", current)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Europe/Moscow")
+
+	// свой пароль без текущего через старый маршрут не меняется
+	rec = request(http.MethodPut, "/api/private/change-password",
+		fmt.Sprintf(`{"login": %q, "password": "stolen password"}`, u.Login), current)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	_, err = srv.Domain.UserUsecase.CheckPassword(context.Background(), u.Login, "stolen password")
+	assert.Error(t, err)
+
+	// пароль, измененный админом, завершает все сессии пользователя
+	rec = request(http.MethodPut, "/api/private/change-password",
+		fmt.Sprintf(`{"login": %q, "password": "admin password"}`, u.Login), srv.SessionCookie(t, srv.Config.SuperAdminID))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/private/whoami", "", current).Code)
+}
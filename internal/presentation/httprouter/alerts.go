@@ -0,0 +1,213 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
+)
+
+var errBadSilence = errors.New("minutes must not be negative")
+
+// Запрос на создание или изменение правила оповещения
+type alertRuleRequest struct {
+	Name      string `json:"name"`
+	MinLevel  uint   `json:"minLevel"`
+	Pattern   string `json:"pattern"`
+	Threshold int    `json:"threshold"`
+	WindowSec int    `json:"windowSec"`
+	Enabled   *bool  `json:"enabled"`
+}
+
+// Правило из запроса. Если enabled не задан, правило включено
+func (req *alertRuleRequest) rule(id uint64) *model.AlertRule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return &model.AlertRule{
+		ID:            id,
+		Name:          req.Name,
+		MinLevel:      req.MinLevel,
+		Pattern:       req.Pattern,
+		Threshold:     req.Threshold,
+		WindowSec:     req.WindowSec,
+		Enabled:       enabled,
+		SilencedUntil: nil,
+		CreatedAt:     time.Time{},
+	}
+}
+
+// Все правила оповещений
+func (router *HTTPRouter) getAlertRules() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rules, err := router.domain.AlertUsecase.List(r.Context(), currentUser(r))
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, rules)
+	}
+}
+
+// Текущее состояние правил оповещений
+func (router *HTTPRouter) getAlerts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := router.domain.AlertUsecase.Status(currentUser(r))
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, statuses)
+	}
+}
+
+// Создать правило оповещения
+func (router *HTTPRouter) createAlertRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &alertRuleRequest{
+			Name:      "",
+			MinLevel:  0,
+			Pattern:   "",
+			Threshold: 0,
+			WindowSec: 0,
+			Enabled:   nil,
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		rule := req.rule(0)
+		if err := router.domain.AlertUsecase.Create(r.Context(), currentUser(r), rule); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).Tf("Правило %s добавлено", rule.Name))
+		router.respond(w, r, http.StatusCreated, rule)
+	}
+}
+
+// Изменить правило оповещения
+func (router *HTTPRouter) updateAlertRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ruleID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		req := &alertRuleRequest{
+			Name:      "",
+			MinLevel:  0,
+			Pattern:   "",
+			Threshold: 0,
+			WindowSec: 0,
+			Enabled:   nil,
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		rule := req.rule(ruleID)
+		if err := router.domain.AlertUsecase.Update(r.Context(), currentUser(r), rule); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Правило изменено"))
+		router.respond(w, r, http.StatusOK, rule)
+	}
+}
+
+// Удалить правило оповещения
+func (router *HTTPRouter) removeAlertRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ruleID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := router.domain.AlertUsecase.Remove(r.Context(), currentUser(r), ruleID); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Правило удалено"))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// Заглушить уведомления правила на minutes минут (0 - снять заглушку)
+func (router *HTTPRouter) silenceAlertRule() http.HandlerFunc {
+	type request struct {
+		Minutes int `json:"minutes"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ruleID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		req := &request{
+			Minutes: 0,
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if req.Minutes < 0 {
+			router.respondError(w, r, http.StatusBadRequest, errBadSilence)
+
+			return
+		}
+
+		var until *time.Time
+
+		if req.Minutes > 0 {
+			t := time.Now().Add(time.Duration(req.Minutes) * time.Minute)
+			until = &t
+		}
+
+		rule, err := router.domain.AlertUsecase.Silence(r.Context(), currentUser(r), ruleID, until)
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		tr := requestPrinter(r)
+		if until != nil {
+			router.addFlash(w, r, flashInfo, tr.Tf("Правило %s заглушено на %d мин.", rule.Name, req.Minutes))
+		} else {
+			router.addFlash(w, r, flashInfo, tr.Tf("Заглушка правила %s снята", rule.Name))
+		}
+
+		router.respond(w, r, http.StatusOK, rule)
+	}
+}
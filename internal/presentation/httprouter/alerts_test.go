@@ -0,0 +1,92 @@
+package httprouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_Alerts(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	adminCookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+	userCookie := srv.SessionCookie(t, u.ID)
+
+	request := func(method, path, body string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.AddCookie(cookie)
+
+		return srv.Serve(req)
+	}
+
+	statuses := func() []model.AlertStatus {
+		rec := request(http.MethodGet, "/api/private/alerts", "", adminCookie)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var s []model.AlertStatus
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&s))
+		require.Len(t, s, 1)
+
+		return s
+	}
+
+	// правила доступны только администраторам
+	body := `{"name": "errors", "minLevel": 4, "pattern": "^fail", "threshold": 1, "windowSec": 300}`
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/api/private/alerts/rules", body, userCookie).Code)
+	assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api/private/alerts", "", userCookie).Code)
+
+	rec := request(http.MethodPost, "/api/private/alerts/rules", `{"name": "bad", "pattern": "(", "windowSec": 60}`,
+		adminCookie)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = request(http.MethodPost, "/api/private/alerts/rules", body, adminCookie)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var rule model.AlertRule
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&rule))
+	assert.True(t, rule.Enabled)
+
+	// записи с меньшим уровнем или без совпадения не учитываются
+	addLog := func(level int, message string) {
+		rec := request(http.MethodPost, "/api/private/add-log",
+			fmt.Sprintf(`[{"logTime": "2020-04-23T18:25:43.511Z", "level": %d, "message1": %q}]`, level, message),
+			userCookie)
+		require.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	addLog(4, "failed")
+	addLog(3, "failed")
+	addLog(4, "ok")
+	assert.Equal(t, model.AlertOK, statuses()[0].State)
+
+	addLog(5, "failure")
+	assert.Equal(t, model.AlertFiring, statuses()[0].State)
+
+	rec = request(http.MethodGet, "/admin", "", adminCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "removeAlertRule(")
+
+	path := fmt.Sprintf("/api/private/alerts/rules/%d", rule.ID)
+	rec = request(http.MethodPost, path+"/silence", `{"minutes": 30}`, adminCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, statuses()[0].Silenced)
+
+	// за пределами окна правило возвращается в норму
+	srv.Domain.AlertUsecase.Evaluate(time.Now().Add(10 * time.Minute))
+	assert.Equal(t, model.AlertOK, statuses()[0].State)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, path, "", adminCookie).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, path, "", adminCookie).Code)
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/sessions"
-	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/app/logger"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/pkg/errors"
@@ -15,6 +15,7 @@ import (
 var (
 	errNotAuthenticated = errors.New("not authenticated")
 	errNotAdmin         = errors.New("not admin")
+	errBadToken         = errors.New("invalid api token")
 )
 
 // Логин (создание сессии)
@@ -36,9 +37,9 @@ func (router *HTTPRouter) handleSessionsCreate() http.HandlerFunc {
 			return
 		}
 		// ищем в БД по логину
-		ID, err := router.domain.UserUsecase.CheckPassword(loginData.Login, loginData.Password)
+		ID, err := router.domain.UserUsecase.CheckPassword(r.Context(), loginData.Login, loginData.Password)
 		if err != nil {
-			router.respondError(w, r, http.StatusForbidden, err)
+			router.respondDomainError(w, r, err)
 
 			return
 		}
@@ -49,19 +50,19 @@ func (router *HTTPRouter) handleSessionsCreate() http.HandlerFunc {
 
 			return
 		}
-		// записываем информацию о том, что пользователь с таким ID залогинился
-		session.Values[UserIDKeyName] = ID
-		session.Options = &sessions.Options{
-			Path:   "/",
-			Domain: "",
-			MaxAge: config.AppConfig.SessionAge,
-			Secure: false,
-			// HttpOnly: true, // прячем содержимое сессии от доступа через JavaSript в браузере
-			HttpOnly: false,
-			SameSite: 0,
+		// создаем сессию на сервере, в куки хранится только ее идентификатор
+		secret, err := router.domain.SessionUsecase.Create(r.Context(), ID, r.RemoteAddr, r.UserAgent())
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
 		}
 
-		if err := router.sessionStore.Save(r, w, session); err != nil {
+		session.Values[SessionKeyName] = secret
+		// CSRF токен выдается заново при следующей отрисовке страницы
+		delete(session.Values, CSRFKeyName)
+
+		if err := router.saveSession(w, r, session); err != nil {
 			router.respondError(w, r, http.StatusInternalServerError, err)
 
 			return
@@ -104,11 +105,16 @@ func (router *HTTPRouter) closeSession() http.HandlerFunc {
 
 			return
 		}
-		// удаляем из нее данные о логине
-		delete(session.Values, UserIDKeyName)
+		// завершаем сессию на сервере и удаляем из куки данные о логине
+		if err := router.domain.SessionUsecase.Close(r.Context(), sessionSecret(session)); err != nil {
+			logger.FromContext(r.Context()).Errorf("session close error: %v", err)
+		}
+
+		delete(session.Values, SessionKeyName)
+		delete(session.Values, CSRFKeyName)
 		// сохраняем
-		if err := router.sessionStore.Save(r, w, session); err != nil {
-			logger.Logger().Errorln("session save error")
+		if err := router.saveSession(w, r, session); err != nil {
+			logger.FromContext(r.Context()).Error(err)
 		}
 
 		router.respond(w, r, http.StatusOK, nil)
@@ -118,7 +124,7 @@ func (router *HTTPRouter) closeSession() http.HandlerFunc {
 // Добавить пользователя
 func (router *HTTPRouter) addUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if currentUser(r).ID != config.AppConfig.SuperAdminID {
+		if !currentUser(r).IsAdmin() {
 			router.respondError(w, r, http.StatusForbidden, errNotAdmin)
 
 			return
@@ -128,8 +134,10 @@ func (router *HTTPRouter) addUser() http.HandlerFunc {
 			ID:                0,
 			Login:             "",
 			Name:              "",
+			Role:              "",
 			Password:          "",
 			EncryptedPassword: "",
+			Timezone:          "",
 		}
 		// парсим входящий json
 		if err := json.NewDecoder(r.Body).Decode(u); err != nil {
@@ -138,10 +146,13 @@ func (router *HTTPRouter) addUser() http.HandlerFunc {
 			return
 		}
 
-		if err := router.domain.UserUsecase.Insert(u); err != nil {
-			router.respondError(w, r, http.StatusForbidden, err)
+		if err := router.domain.UserUsecase.Insert(r.Context(), u); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
 		}
 
+		router.addFlash(w, r, flashInfo, requestPrinter(r).Tf("Пользователь %s добавлен", u.Login))
 		router.respond(w, r, http.StatusCreated, nil)
 	}
 }
@@ -149,15 +160,15 @@ func (router *HTTPRouter) addUser() http.HandlerFunc {
 // Список пользователей
 func (router *HTTPRouter) getUsers() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if currentUser(r).ID != config.AppConfig.SuperAdminID {
+		if !currentUser(r).IsAdmin() {
 			router.respondError(w, r, http.StatusForbidden, errNotAdmin)
 
 			return
 		}
 
-		users, err := router.domain.UserUsecase.GetUsers()
+		users, err := router.domain.UserUsecase.GetUsers(r.Context())
 		if err != nil {
-			router.respondError(w, r, http.StatusInternalServerError, err)
+			router.respondDomainError(w, r, err)
 
 			return
 		}
@@ -166,7 +177,7 @@ func (router *HTTPRouter) getUsers() http.HandlerFunc {
 	}
 }
 
-// Изменить пароль пользователя
+// Изменить пароль другого пользователя (только для администратора). Свой пароль меняется через account/password
 func (router *HTTPRouter) changePassword() http.HandlerFunc {
 	type request struct {
 		Login    string `json:"login"`
@@ -192,9 +203,11 @@ func (router *HTTPRouter) changePassword() http.HandlerFunc {
 			return
 		}
 
-		_, err := router.domain.UserUsecase.ChangePassword(currentUser, req.Login, req.Password)
+		_, err := router.domain.UserUsecase.ChangePassword(r.Context(), currentUser, req.Login, req.Password)
 		if err != nil {
-			router.respondError(w, r, http.StatusForbidden, err)
+			router.respondDomainError(w, r, err)
+
+			return
 		}
 
 		router.respond(w, r, http.StatusOK, nil)
@@ -202,6 +215,29 @@ func (router *HTTPRouter) changePassword() http.HandlerFunc {
 }
 
 func (router *HTTPRouter) isAuthenticated(r *http.Request) (user *model.User, httpCode int, err error) {
+	// клиентский сертификат имеет приоритет над сессией
+	user, err = router.certificateUser(r)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if user != nil {
+		return user, http.StatusOK, nil
+	}
+
+	// затем токен доступа к API
+	if secret, ok := bearerToken(r); ok {
+		user, err = router.domain.TokenUsecase.Authenticate(r.Context(), secret)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		if user == nil {
+			return nil, http.StatusUnauthorized, errBadToken
+		}
+
+		return user, http.StatusOK, nil
+	}
 
 	// извлекаем из запроса пользователя куки с инфорамацией о сессии
 	session, err := router.sessionStore.Get(r, SessionName)
@@ -209,21 +245,39 @@ func (router *HTTPRouter) isAuthenticated(r *http.Request) (user *model.User, ht
 		return nil, http.StatusUnauthorized, err
 	}
 
-	// ищем в информацию о пользователе в сессиях
-	id, ok := session.Values[UserIDKeyName]
-	if !ok || session.Options.MaxAge < 0 {
+	secret := sessionSecret(session)
+	if secret == "" || session.Options.MaxAge < 0 {
 		return nil, http.StatusUnauthorized, errNotAuthenticated
 	}
 
-	// берем инфу о пользователе из БД
-	user, err = router.domain.UserUsecase.FindByID(id.(uint64))
+	// сессия могла истечь или быть завершена с другого устройства
+	user, err = router.domain.SessionUsecase.Authenticate(r.Context(), secret)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
 
 	if user == nil {
-		return nil, http.StatusNotFound, errNotAuthenticated
+		return nil, http.StatusUnauthorized, errNotAuthenticated
 	}
 
 	return user, http.StatusOK, nil
 }
+
+// Токен из заголовка Authorization: Bearer <токен>
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
+
+// Идентификатор сессии из куки
+func sessionSecret(session *sessions.Session) string {
+	secret, _ := session.Values[SessionKeyName].(string)
+
+	return secret
+}
@@ -1,54 +1,40 @@
 package httprouter_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gorilla/securecookie"
-	"github.com/gorilla/sessions"
 	"github.com/n-r-w/log-server/internal/app/config"
-	"github.com/n-r-w/log-server/internal/domain"
 	"github.com/n-r-w/log-server/internal/domain/model"
-	"github.com/n-r-w/log-server/internal/domain/usecase"
 	"github.com/n-r-w/log-server/internal/presentation/httprouter"
-	"github.com/n-r-w/log-server/internal/repository"
-	"github.com/n-r-w/log-server/internal/repository/testrepo"
+	"github.com/n-r-w/log-server/internal/testserver"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func initAuthTestCase(t *testing.T) (*httprouter.HTTPRouter, repository.UserInterface, repository.LogInterface) {
+func initAuthTestCase(t *testing.T) *testserver.Server {
 	t.Helper()
-	assert.NoError(t, config.Load(""))
 
-	// фейковая БД
-	dbo, err := testrepo.CreateTestlDBO()
-	assert.NoError(t, err)
-
-	// репозитории
-	userRepo := testrepo.NewUser(dbo)
-	logRepo := testrepo.NewLog(dbo)
-
-	// сценарии
-	userUsecase := usecase.NewUserCase(userRepo)
-	logCase := usecase.NewLogCase(logRepo)
-
-	// инициализируем домен
-	dom := domain.NewDomain(logCase, userUsecase)
-
-	// создаем роутер
-	return httprouter.NewRouter(dom, sessions.NewCookieStore([]byte(config.AppConfig.SessionEncriptionKey))), userRepo, logRepo
+	return testserver.New(t)
 }
 
 func TestHTTPRouter_AuthenticateUser(t *testing.T) {
 	// инициализируем все что надо
-	router, userRepo, _ := initAuthTestCase(t)
+	srv := initAuthTestCase(t)
+	router, userRepo := srv.Router, srv.UserRepo
 
 	// тестовый юзер
 	u := model.TestUser(t)
 	// заносим его в фейковую БД
-	assert.NoError(t, userRepo.Insert(u))
+	assert.NoError(t, userRepo.Insert(context.Background(), u))
+
+	// сессия на сервере
+	secret, err := srv.Domain.SessionUsecase.Create(context.Background(), u.ID, "", "")
+	assert.NoError(t, err)
 
 	testCases := []struct {
 		name         string
@@ -58,10 +44,17 @@ func TestHTTPRouter_AuthenticateUser(t *testing.T) {
 		{
 			name: "authenticated",
 			cookieValue: map[interface{}]interface{}{
-				httprouter.UserIDKeyName: u.ID,
+				httprouter.SessionKeyName: secret,
 			},
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "unknown session",
+			cookieValue: map[interface{}]interface{}{
+				httprouter.SessionKeyName: secret + "x",
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
 		{
 			name:         "not authenticated",
 			cookieValue:  nil,
@@ -70,7 +63,7 @@ func TestHTTPRouter_AuthenticateUser(t *testing.T) {
 	}
 
 	// создаем новый куки
-	sc := securecookie.New([]byte(config.AppConfig.SessionEncriptionKey), nil)
+	sc := securecookie.New([]byte(srv.Config.SessionEncriptionKey), nil)
 	// создаем функцию аутентификации
 	mw := router.AuthenticateUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -93,3 +86,23 @@ func TestHTTPRouter_AuthenticateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPRouter_CloseSession(t *testing.T) {
+	srv := testserver.New(t, func(cfg *config.Config) { cfg.SessionAge = 600 })
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/auth/close", nil)
+	req.AddCookie(srv.SessionCookie(t, srv.Config.SuperAdminID))
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// куки сохраняется с теми же параметрами, что и при логине
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, httprouter.SessionName, cookies[0].Name)
+	assert.Equal(t, 600, cookies[0].MaxAge)
+	assert.Equal(t, "/", cookies[0].Path)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/private/whoami", nil)
+	req.AddCookie(cookies[0])
+	assert.Equal(t, http.StatusUnauthorized, srv.Serve(req).Code)
+}
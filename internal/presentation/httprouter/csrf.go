@@ -0,0 +1,113 @@
+package httprouter
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CSRFHeaderName Хедер, в котором веб интерфейс передает CSRF токен
+	CSRFHeaderName = "X-CSRF-Token"
+	// CSRFKeyName Ключ для хранения CSRF токена в сессии
+	CSRFKeyName = "csrf_token"
+
+	// Количество случайных байт в CSRF токене
+	csrfTokenBytes = 32
+)
+
+var errBadCSRFToken = errors.New("invalid csrf token")
+
+// Параметры куки сессии
+func (router *HTTPRouter) sessionOptions() *sessions.Options {
+	return &sessions.Options{
+		Path:   "/",
+		Domain: "",
+		MaxAge: router.config.Runtime().SessionAge,
+		// при работе по HTTPS куки не передаются по незащищенному соединению
+		Secure: router.config.TLSEnabled(),
+		// HttpOnly: true, // прячем содержимое сессии от доступа через JavaSript в браузере
+		HttpOnly: false,
+		SameSite: 0,
+	}
+}
+
+// Сохранить сессию с текущими параметрами куки. Должно вызываться до записи тела ответа
+func (router *HTTPRouter) saveSession(w http.ResponseWriter, r *http.Request, session *sessions.Session) error {
+	session.Options = router.sessionOptions()
+
+	return errors.Wrap(router.sessionStore.Save(r, w, session), "session save error")
+}
+
+// Новый CSRF токен
+func newCSRFToken() (string, error) {
+	random := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.Wrap(err, "csrf token generation error")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// CSRF токен сессии. Если его нет, создается новый. Возвращает признак того, что сессию надо сохранить
+func sessionCSRFToken(session *sessions.Session) (token string, changed bool) {
+	if token, ok := session.Values[CSRFKeyName].(string); ok && token != "" {
+		return token, false
+	}
+
+	token, err := newCSRFToken()
+	if err != nil {
+		logger.Logger().Error(err)
+
+		return "", false
+	}
+
+	session.Values[CSRFKeyName] = token
+
+	return token, true
+}
+
+// Запрос отправлен браузером. Такие хедеры браузер выставляет сам и их нельзя подделать из JS
+func isBrowserRequest(r *http.Request) bool {
+	return r.Header.Get("Origin") != "" || r.Header.Get("Sec-Fetch-Site") != ""
+}
+
+// Защита от CSRF для изменяющих запросов браузера, аутентифицированных по куки сессии.
+// Токен выдается при отрисовке страницы веб интерфейса и передается в хедере X-CSRF-Token.
+// Запросы с токеном API и запросы не из браузера (curl, сервисы) не проверяются, т.к. браузер
+// не может отправить их от имени пользователя
+func (router *HTTPRouter) checkCSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if _, ok := bearerToken(r); ok || !isBrowserRequest(r) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		var expected string
+		if session, err := router.sessionStore.Get(r, SessionName); err == nil {
+			expected, _ = session.Values[CSRFKeyName].(string)
+		}
+
+		got := r.Header.Get(CSRFHeaderName)
+		if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(got)) != 1 {
+			router.respondError(w, r, http.StatusForbidden, errBadCSRFToken)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,27 @@
+//go:build go1.20
+
+package httprouter
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Таймауты чтения и записи соединения текущего запроса. Нулевое время - без ограничения
+func setConnDeadlines(w http.ResponseWriter, deadline time.Time) error {
+	rc := http.NewResponseController(w)
+
+	err := rc.SetReadDeadline(deadline)
+	if errors.Is(err, http.ErrNotSupported) {
+		// ответ без соединения, например httptest.ResponseRecorder
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "read deadline")
+	}
+
+	return errors.Wrap(rc.SetWriteDeadline(deadline), "write deadline")
+}
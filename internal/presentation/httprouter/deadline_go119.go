@@ -0,0 +1,17 @@
+//go:build !go1.20
+
+package httprouter
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var errDeadlinesUnsupported = errors.New("connection deadlines require go1.20")
+
+// До go1.20 таймауты соединения нельзя изменить из обработчика, действуют таймауты сервера
+func setConnDeadlines(http.ResponseWriter, time.Time) error {
+	return errDeadlinesUnsupported
+}
@@ -0,0 +1,98 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/app/logger"
+)
+
+// Тело ответа с ошибкой
+type errorBody struct {
+	// Code Текстовый код ошибки (validation, not_found и т.п.)
+	Code string `json:"code"`
+	// Message Описание ошибки
+	Message string `json:"message"`
+	// Fields Ошибки по отдельным полям
+	Fields map[string]string `json:"fields,omitempty"`
+	// RequestID Номер запроса (совпадает с хедером X-Request-ID)
+	RequestID string `json:"requestId,omitempty"`
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+// HTTP статус, соответствующий типу ошибки предметной области
+func httpStatus(err error) int {
+	switch apperr.KindOf(err) {
+	case apperr.KindValidation:
+		return http.StatusBadRequest
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindForbidden:
+		return http.StatusForbidden
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case apperr.KindInternal:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Текстовый код ошибки. Если тип ошибки не задан, код определяется по HTTP статусу
+func errorCode(code int, err error) string {
+	if kind := apperr.KindOf(err); kind != apperr.KindInternal {
+		return kind.String()
+	}
+
+	switch code {
+	case http.StatusBadRequest:
+		return apperr.KindValidation.String()
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return apperr.KindForbidden.String()
+	case http.StatusNotFound:
+		return apperr.KindNotFound.String()
+	case http.StatusConflict:
+		return apperr.KindConflict.String()
+	case http.StatusServiceUnavailable:
+		return apperr.KindUnavailable.String()
+	default:
+		return apperr.KindInternal.String()
+	}
+}
+
+// Ответ с ошибкой предметной области. HTTP статус определяется по типу ошибки
+func (router *HTTPRouter) respondDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	router.respondError(w, r, httpStatus(err), err)
+}
+
+// Ответ с ошибкой
+func (router *HTTPRouter) respondError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	// сохраняем ошибку для журналирования в logRequest
+	if rw, ok := w.(*responseWriter); ok {
+		rw.err = err
+	}
+
+	// веб интерфейс покажет ошибку на обновленной странице
+	router.addFlash(w, r, flashError, err.Error())
+
+	body := errorResponse{
+		Error: errorBody{
+			Code:      errorCode(code, err),
+			Message:   err.Error(),
+			Fields:    apperr.FieldsOf(err),
+			RequestID: logger.RequestID(r.Context()),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,145 @@
+package httprouter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPRouter_ErrorResponse(t *testing.T) {
+	srv := initAuthTestCase(t)
+	router, userRepo := srv.Router, srv.UserRepo
+
+	u := model.TestUser(t)
+	assert.NoError(t, userRepo.Insert(context.Background(), u))
+
+	// куки администратора
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		errorCode    string
+		errorField   string
+	}{
+		{
+			name:         "valid log record",
+			method:       http.MethodPost,
+			path:         "/api/private/add-log",
+			body:         `[{"logTime": "2020-04-23T18:25:43.511Z", "level": 4, "message1": "error"}]`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "invalid log record",
+			method:       http.MethodPost,
+			path:         "/api/private/add-log",
+			body:         `[{"logTime": "2020-04-23T18:25:43.511Z", "level": 4, "message1": "ok"}, {"level": 4}]`,
+			expectedCode: http.StatusBadRequest,
+			errorCode:    "validation",
+			errorField:   "[1].message1",
+		},
+		{
+			name:         "reserved log source",
+			method:       http.MethodPost,
+			path:         "/api/private/add-log",
+			body:         `[{"logTime": "2020-04-23T18:25:43.511Z", "level": 4, "message1": "ok", "message2": "logserver"}]`,
+			expectedCode: http.StatusBadRequest,
+			errorCode:    "validation",
+			errorField:   "[0].message2",
+		},
+		{
+			name:         "bad json",
+			method:       http.MethodPost,
+			path:         "/api/private/add-log",
+			body:         `{`,
+			expectedCode: http.StatusBadRequest,
+			errorCode:    "validation",
+		},
+		{
+			name:         "login exists",
+			method:       http.MethodPost,
+			path:         "/api/private/add-user",
+			body:         fmt.Sprintf(`{"login": "%s", "name": "name", "password": "12345"}`, u.Login),
+			expectedCode: http.StatusConflict,
+			errorCode:    "conflict",
+		},
+		{
+			name:         "user not found",
+			method:       http.MethodPut,
+			path:         "/api/private/change-password",
+			body:         `{"login": "unknown", "password": "12345"}`,
+			expectedCode: http.StatusNotFound,
+			errorCode:    "not_found",
+		},
+	}
+
+	for _, tc := range testCases { //nolint:paralleltest
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			req.AddCookie(cookie)
+
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			if tc.errorCode == "" {
+				return
+			}
+
+			var resp struct {
+				Error struct {
+					Code      string            `json:"code"`
+					Message   string            `json:"message"`
+					Fields    map[string]string `json:"fields"`
+					RequestID string            `json:"requestId"`
+				} `json:"error"`
+			}
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, tc.errorCode, resp.Error.Code)
+			assert.NotEmpty(t, resp.Error.Message)
+			assert.Equal(t, rec.Header().Get("X-Request-ID"), resp.Error.RequestID)
+
+			if tc.errorField != "" {
+				assert.Contains(t, resp.Error.Fields, tc.errorField)
+			}
+		})
+	}
+}
+
+func TestHTTPRouter_ErrorLogged(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	hook := logtest.NewLocal(logger.Logger())
+	t.Cleanup(func() { logger.Logger().ReplaceHooks(make(logrus.LevelHooks)) })
+
+	req := httptest.NewRequest(http.MethodPost, "/api/private/add-log", bytes.NewBufferString(`[{"level": 4}]`))
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var line string
+
+	for _, e := range hook.AllEntries() {
+		if strings.HasPrefix(e.Message, "completed with 400") {
+			line = e.Message
+		}
+	}
+
+	assert.Contains(t, line, "message1")
+	assert.NotContains(t, line, "info: -")
+}
@@ -0,0 +1,328 @@
+package httprouter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	schemalog "github.com/n-r-w/log-server/api/schema/schema.log"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/app/metrics"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/tool"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Форматы выгрузки
+const (
+	exportCSV            = "csv"
+	exportNDJSON         = "ndjson"
+	exportProtoDelimited = "proto-delimited"
+)
+
+// Форматы выгрузки в порядке отображения в веб интерфейсе
+var exportFormats = []string{exportCSV, exportNDJSON, exportProtoDelimited}
+
+const (
+	// количество записей, читаемых из хранилища за один раз
+	exportPageSize = 1000
+	// хедер с ограничением количества записей в выгрузке
+	exportLimitHeader = "X-Export-Limit"
+)
+
+var errBadExportFormat = errors.New("format must be csv, ndjson or proto-delimited")
+
+// Запись выгрузки в одном из форматов
+type exportWriter interface {
+	write(record *model.LogRecord) error
+	// flush Отправка буферизованных данных
+	flush() error
+}
+
+// Выгрузка записей журнала за интервал в CSV, NDJSON или в виде последовательности Protobuf сообщений
+// LogRecord с префиксом длины (varint). Параметры строки запроса: format, from и to (как у records/page,
+// а также относительное время now-1h), tz. Записи читаются из хранилища страницами и сразу отправляются клиенту
+func (router *HTTPRouter) exportLogRecords() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		metrics.StreamsInFlight.Inc()
+		defer metrics.StreamsInFlight.Dec()
+
+		query := r.URL.Query()
+
+		loc, err := requestLocation(r)
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		now := time.Now()
+
+		var timeFrom, timeTo time.Time
+
+		if v := query.Get("from"); v != "" {
+			if timeFrom, err = parseExportTime(v, loc, now); err != nil {
+				router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "bad from"))
+
+				return
+			}
+		}
+
+		if v := query.Get("to"); v != "" {
+			if timeTo, err = parseExportTime(v, loc, now); err != nil {
+				router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "bad to"))
+
+				return
+			}
+		}
+
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		format := query.Get("format")
+		if format == "" {
+			format = exportCSV
+		}
+
+		buf := bufio.NewWriter(w)
+
+		var out exportWriter
+
+		switch format {
+		case exportCSV:
+			out = newCSVExport(buf, loc)
+		case exportNDJSON:
+			out = &ndjsonExport{buf: buf, enc: json.NewEncoder(buf), loc: loc}
+		case exportProtoDelimited:
+			out = &protoExport{buf: buf, data: nil}
+		default:
+			router.respondError(w, r, http.StatusBadRequest, errBadExportFormat)
+
+			return
+		}
+
+		// первая страница читается до отправки заголовков, чтобы вернуть ошибку хранилища кодом ответа
+		limit := router.config.MaxExportRecords
+
+		page, err := router.domain.LogUsecase.FindPage(r.Context(), timeFrom, timeTo, "", exportPageLimit(limit, 0))
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", exportContentType(format))
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="logs-%s.%s"`, now.In(loc).Format("20060102-150405"), exportExt(format)))
+
+		if limit > 0 {
+			w.Header().Set(exportLimitHeader, strconv.Itoa(limit))
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		if err := router.streamExport(r, w, out, page, timeFrom, timeTo, limit); err != nil {
+			// заголовки уже отправлены, поэтому соединение обрывается, чтобы клиент не принял неполную выгрузку
+			logger.FromContext(r.Context()).WithError(err).Warnln("export aborted")
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// Отправка записей страница за страницей
+func (router *HTTPRouter) streamExport(r *http.Request, w http.ResponseWriter, out exportWriter, page *model.LogPage,
+	timeFrom, timeTo time.Time, limit int,
+) error {
+	written := 0
+
+	for {
+		for i := range page.Records {
+			if err := out.write(&page.Records[i]); err != nil {
+				return err
+			}
+		}
+
+		written += len(page.Records)
+
+		if err := out.flush(); err != nil {
+			return err
+		}
+
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+
+		if page.NextCursor == "" || (limit > 0 && written >= limit) {
+			return nil
+		}
+
+		var err error
+
+		page, err = router.domain.LogUsecase.FindPage(r.Context(), timeFrom, timeTo, page.NextCursor,
+			exportPageLimit(limit, written))
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+}
+
+// Размер следующей страницы с учетом ограничения выгрузки
+func exportPageLimit(limit, written int) int {
+	if limit > 0 && limit-written < exportPageSize {
+		return limit - written
+	}
+
+	return exportPageSize
+}
+
+// Время из параметра выгрузки: как в records/page или относительно текущего момента (now-1h)
+func parseExportTime(v string, loc *time.Location, now time.Time) (time.Time, error) {
+	if strings.HasPrefix(v, "now") {
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		return parseSearchTime(v, loc, now)
+	}
+
+	return parseRequestTime(v, loc)
+}
+
+func exportContentType(format string) string {
+	switch format {
+	case exportNDJSON:
+		return "application/x-ndjson"
+	case exportProtoDelimited:
+		return "application/x-protobuf; delimited=true"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+func exportExt(format string) string {
+	if format == exportProtoDelimited {
+		return "pb"
+	}
+
+	return format
+}
+
+// CSV с заголовком. Время в формате RFC3339 в часовом поясе запроса
+type csvExport struct {
+	w   *csv.Writer
+	buf *bufio.Writer
+	loc *time.Location
+	// заголовок еще не записан
+	header bool
+}
+
+func newCSVExport(buf *bufio.Writer, loc *time.Location) *csvExport {
+	return &csvExport{
+		w:      csv.NewWriter(buf),
+		buf:    buf,
+		loc:    loc,
+		header: true,
+	}
+}
+
+// Заголовок записывается перед первой записью или при первой отправке, если записей нет
+func (c *csvExport) writeHeader() error {
+	if !c.header {
+		return nil
+	}
+
+	c.header = false
+
+	return errors.Wrap(c.w.Write([]string{"id", "logTime", "realTime", "level", "message1", "message2", "message3"}),
+		"csv")
+}
+
+func (c *csvExport) write(record *model.LogRecord) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return errors.Wrap(c.w.Write([]string{
+		strconv.FormatUint(record.ID, 10),
+		record.LogTime.In(c.loc).Format(time.RFC3339Nano),
+		record.RealTime.In(c.loc).Format(time.RFC3339Nano),
+		strconv.FormatUint(uint64(record.Level), 10),
+		// сообщения пишут клиенты, поэтому формулы в них экранируются
+		tool.EscapeCSVFormula(record.Message1),
+		tool.EscapeCSVFormula(record.Message2),
+		tool.EscapeCSVFormula(record.Message3),
+	}), "csv")
+}
+
+func (c *csvExport) flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return errors.Wrap(err, "csv")
+	}
+
+	return errors.Wrap(c.buf.Flush(), "csv")
+}
+
+// JSON запись в каждой строке
+type ndjsonExport struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+	loc *time.Location
+}
+
+func (n *ndjsonExport) write(record *model.LogRecord) error {
+	r := *record
+	r.LogTime = r.LogTime.In(n.loc)
+	r.RealTime = r.RealTime.In(n.loc)
+
+	return errors.Wrap(n.enc.Encode(&r), "ndjson")
+}
+
+func (n *ndjsonExport) flush() error {
+	return errors.Wrap(n.buf.Flush(), "ndjson")
+}
+
+// Сообщения schemalog.LogRecord с префиксом длины, как у writeDelimitedTo в Java и protodelim в Go
+type protoExport struct {
+	buf  *bufio.Writer
+	data []byte
+}
+
+func (p *protoExport) write(record *model.LogRecord) error {
+	msg, err := proto.Marshal(&schemalog.LogRecord{
+		Id:       record.ID,
+		LogTime:  timestamppb.New(record.LogTime),
+		RealTime: timestamppb.New(record.RealTime),
+		Level:    uint32(record.Level),
+		Message1: record.Message1,
+		Message2: record.Message2,
+		Message3: record.Message3,
+	})
+	if err != nil {
+		return errors.Wrap(err, "protobuf")
+	}
+
+	p.data = protowire.AppendVarint(p.data[:0], uint64(len(msg)))
+	p.data = append(p.data, msg...)
+
+	_, err = p.buf.Write(p.data)
+
+	return errors.Wrap(err, "protobuf")
+}
+
+func (p *protoExport) flush() error {
+	return errors.Wrap(p.buf.Flush(), "protobuf")
+}
@@ -0,0 +1,142 @@
+package httprouter_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	schemalog "github.com/n-r-w/log-server/api/schema/schema.log"
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/testserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHTTPRouter_Export(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	insertTestRecords(t, srv.LogRepo, start, 5)
+
+	export := func(format string) *httptest.ResponseRecorder {
+		query := url.Values{}
+		query.Set("format", format)
+		query.Set("from", start.Format(time.RFC3339))
+		query.Set("to", start.Add(time.Hour).Format(time.RFC3339))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/private/export?"+query.Encode(), nil)
+		req.AddCookie(cookie)
+
+		return srv.Serve(req)
+	}
+
+	rec := export("csv")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), ".csv")
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+	assert.Equal(t, []string{"id", "logTime", "realTime", "level", "message1", "message2", "message3"}, rows[0])
+
+	rec = export("ndjson")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	lines := 0
+	scanner := bufio.NewScanner(rec.Body)
+
+	for scanner.Scan() {
+		var record model.LogRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		assert.Contains(t, record.Message1, "record ")
+		lines++
+	}
+
+	assert.Equal(t, 5, lines)
+
+	rec = export("proto-delimited")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	data, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	messages := 0
+
+	for len(data) > 0 {
+		size, n := protowire.ConsumeVarint(data)
+		require.Greater(t, n, 0)
+		data = data[n:]
+
+		var record schemalog.LogRecord
+		require.NoError(t, proto.Unmarshal(data[:size], &record))
+		assert.Contains(t, record.Message1, "record ")
+
+		data = data[size:]
+		messages++
+	}
+
+	assert.Equal(t, 5, messages)
+
+	assert.Equal(t, http.StatusBadRequest, export("parquet").Code)
+}
+
+func TestHTTPRouter_ExportLimit(t *testing.T) {
+	srv := testserver.New(t, func(cfg *config.Config) {
+		cfg.MaxExportRecords = 3
+	})
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	insertTestRecords(t, srv.LogRepo, time.Now().Add(-time.Hour), 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/private/export?format=ndjson&from=now-2h", nil)
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "3", rec.Header().Get("X-Export-Limit"))
+
+	lines := 0
+	for scanner := bufio.NewScanner(rec.Body); scanner.Scan(); {
+		lines++
+	}
+
+	assert.Equal(t, 3, lines)
+}
+
+func TestHTTPRouter_ExportCSVFormula(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	records := []model.LogRecord{{
+		ID:       0,
+		LogTime:  time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC),
+		RealTime: time.Time{},
+		Level:    model.LevelInfo,
+		Message1: `=HYPERLINK("http://example.com","open")`,
+		Message2: "@source",
+		Message3: "plain",
+	}}
+	require.NoError(t, srv.LogRepo.Insert(context.Background(), &records))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/private/export?format=csv", nil)
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, `'=HYPERLINK("http://example.com","open")`, rows[1][4])
+	assert.Equal(t, "'@source", rows[1][5])
+	assert.Equal(t, "plain", rows[1][6])
+}
@@ -0,0 +1,82 @@
+package httprouter
+
+import (
+	"net/http"
+
+	"github.com/n-r-w/log-server/internal/app/logger"
+)
+
+// Тип flash сообщения. Используется как ключ для хранения сообщений в сессии
+type flashKind string
+
+const (
+	flashInfo  flashKind = "flash_info"
+	flashError flashKind = "flash_error"
+)
+
+// Сообщение, показываемое на следующей отрисованной странице веб интерфейса
+type flashMessage struct {
+	kind flashKind
+	text string
+}
+
+// Запрос отправлен веб интерфейсом (JS передает CSRF токен)
+func isWebRequest(r *http.Request) bool {
+	return r.Header.Get(CSRFHeaderName) != ""
+}
+
+// Добавить flash сообщение в сессию. Только для запросов веб интерфейса,
+// вызывается до записи ответа, т.к. сессия хранится в куки
+func (router *HTTPRouter) addFlash(w http.ResponseWriter, r *http.Request, kind flashKind, text string) {
+	if !isWebRequest(r) {
+		return
+	}
+
+	session, err := router.sessionStore.Get(r, SessionName)
+	if err != nil {
+		return
+	}
+
+	session.AddFlash(text, string(kind))
+
+	if err := router.saveSession(w, r, session); err != nil {
+		logger.FromContext(r.Context()).Error(err)
+	}
+}
+
+// Состояние сессии для отрисовки страницы: CSRF токен и накопленные flash сообщения.
+// Сессия сохраняется только если изменилась
+func (router *HTTPRouter) webPageState(w http.ResponseWriter, r *http.Request) pageState {
+	state := pageState{
+		csrfToken: "",
+		flashes:   nil,
+		searches:  nil,
+	}
+
+	// при испорченном куки возвращается новая сессия, которая заменит его при сохранении
+	session, _ := router.sessionStore.Get(r, SessionName)
+	if session == nil {
+		return state
+	}
+
+	var changed bool
+	state.csrfToken, changed = sessionCSRFToken(session)
+
+	for _, kind := range []flashKind{flashError, flashInfo} {
+		for _, f := range session.Flashes(string(kind)) {
+			changed = true
+
+			if text, ok := f.(string); ok {
+				state.flashes = append(state.flashes, flashMessage{kind: kind, text: text})
+			}
+		}
+	}
+
+	if changed {
+		if err := router.saveSession(w, r, session); err != nil {
+			logger.FromContext(r.Context()).Error(err)
+		}
+	}
+
+	return state
+}
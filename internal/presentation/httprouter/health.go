@@ -0,0 +1,63 @@
+package httprouter
+
+import (
+	"net/http"
+
+	"github.com/n-r-w/log-server/internal/app/buildinfo"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
+)
+
+// Проверка того, что процесс жив. Внешние зависимости не проверяются
+func (router *HTTPRouter) healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		router.respond(w, r, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// Ошибка непройденной проверки готовности в ответе /readyz
+const checkUnavailable = "unavailable"
+
+// Проверка готовности к обработке запросов: доступность БД и состояние очереди записи
+func (router *HTTPRouter) readyz() http.HandlerFunc {
+	type response struct {
+		Status string              `json:"status"`
+		Checks []model.HealthCheck `json:"checks"`
+		DB     model.DBStats       `json:"db"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ready, checks := router.domain.HealthUsecase.Ready(r.Context())
+
+		// проверка доступна без аутентификации, поэтому текст ошибок (адреса, ошибки БД) пишется только в журнал сервера
+		for i := range checks {
+			if checks[i].OK {
+				continue
+			}
+
+			logger.FromContext(r.Context()).Warnf("readiness check %s failed: %s", checks[i].Name, checks[i].Error)
+			checks[i].Error = checkUnavailable
+		}
+
+		resp := response{
+			Status: "ok",
+			Checks: checks,
+			DB:     router.domain.HealthUsecase.DBStats(),
+		}
+
+		code := http.StatusOK
+		if !ready {
+			resp.Status = "fail"
+			code = http.StatusServiceUnavailable
+		}
+
+		router.respond(w, r, code, resp)
+	}
+}
+
+// Информация о сборке
+func (router *HTTPRouter) version() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		router.respond(w, r, http.StatusOK, buildinfo.Get())
+	}
+}
@@ -0,0 +1,80 @@
+package httprouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_Health(t *testing.T) {
+	router := initAuthTestCase(t).Router
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+		expectedKey  string
+	}{
+		{
+			name:         "healthz",
+			path:         "/healthz",
+			expectedCode: http.StatusOK,
+			expectedKey:  "status",
+		},
+		{
+			name:         "readyz",
+			path:         "/readyz",
+			expectedCode: http.StatusOK,
+			expectedKey:  "checks",
+		},
+		{
+			name:         "version",
+			path:         "/version",
+			expectedCode: http.StatusOK,
+			expectedKey:  "goVersion",
+		},
+	}
+
+	for _, tc := range testCases { //nolint:paralleltest
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			assert.Contains(t, body, tc.expectedKey)
+		})
+	}
+}
+
+func TestHTTPRouter_ReadyzHidesErrors(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	// хранилище в памяти недоступно только при отмененном контексте
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := srv.Serve(httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(ctx))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var body struct {
+		Status string              `json:"status"`
+		Checks []model.HealthCheck `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "fail", body.Status)
+
+	require.NotEmpty(t, body.Checks)
+	assert.Equal(t, "database", body.Checks[0].Name)
+	assert.False(t, body.Checks[0].OK)
+	assert.Equal(t, "unavailable", body.Checks[0].Error)
+	assert.NotContains(t, rec.Body.String(), "context canceled")
+}
@@ -0,0 +1,93 @@
+package httprouter
+
+import (
+	"net/http"
+	"time"
+
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LangCookieName Куки с языком веб интерфейса, выбранным пользователем
+	LangCookieName = "logserver_lang"
+	// TimezoneCookieName Куки с часовым поясом браузера
+	TimezoneCookieName = "logserver_tz"
+)
+
+// JS для выбора языка. Выбор сохраняется в куки на год.
+// Заодно в куки запоминается часовой пояс браузера
+const langJS = `
+function setLang(lang)
+{
+	document.cookie = "` + LangCookieName + `=" + lang + "; path=/; max-age=31536000; samesite=lax"
+	location.reload()
+}
+
+(function () {
+	var tz = Intl.DateTimeFormat().resolvedOptions().timeZone
+	if (tz && document.cookie.indexOf("` + TimezoneCookieName + `=" + tz) < 0) {
+		document.cookie = "` + TimezoneCookieName + `=" + tz + "; path=/; max-age=31536000; samesite=lax"
+	}
+})()`
+
+// Перевод строк веб интерфейса на язык запроса: выбранный пользователем или по заголовку Accept-Language
+func requestPrinter(r *http.Request) *i18n.Printer {
+	if cookie, err := r.Cookie(LangCookieName); err == nil {
+		if lang, ok := i18n.Parse(cookie.Value); ok {
+			return i18n.NewPrinter(lang)
+		}
+	}
+
+	return i18n.NewPrinter(i18n.Negotiate(r.Header.Get("Accept-Language")))
+}
+
+// Перевод и часовой пояс веб интерфейса для пользователя. Часовой пояс берется из параметра запроса tz,
+// настроек пользователя или куки с часовым поясом браузера. Если ни один не задан, время показывается в UTC
+func userPrinter(r *http.Request, user *model.User) *i18n.Printer {
+	tr := requestPrinter(r)
+
+	if loc, err := requestLocation(r); err == nil && loc != nil {
+		return tr.In(loc)
+	}
+
+	if user != nil {
+		if loc := user.Location(); loc != nil {
+			return tr.In(loc)
+		}
+	}
+
+	if cookie, err := r.Cookie(TimezoneCookieName); err == nil && cookie.Value != "" {
+		if loc, err := time.LoadLocation(cookie.Value); err == nil {
+			return tr.In(loc)
+		}
+	}
+
+	return tr
+}
+
+// Часовой пояс из параметра запроса tz (IANA, например Europe/Moscow). nil, если не задан
+func requestLocation(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	loc, err := time.LoadLocation(tz)
+
+	return loc, errors.Wrap(err, "bad tz")
+}
+
+// Выбор языка в навбаре
+func renderLangSelect(tr *i18n.Printer) g.Node {
+	return Select(Class("ml-2 px-2 py-1 text-sm rounded-md bg-gray-800 text-gray-100 border-gray-600"),
+		ID("lang"), g.Attr("onchange", "setLang(this.value)"),
+		g.Group(g.Map(len(i18n.Languages), func(i int) g.Node {
+			lang := i18n.Languages[i]
+
+			return Option(Value(string(lang)), g.If(lang == tr.Lang(), Selected()), g.Text(string(lang)))
+		})))
+}
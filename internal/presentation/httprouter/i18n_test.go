@@ -0,0 +1,47 @@
+package httprouter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/presentation/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_WebLanguage(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		cookie         string
+		expected       string
+		expectedLang   string
+	}{
+		{name: "default", acceptLanguage: "", cookie: "", expected: "Необходимо войти", expectedLang: `lang="ru"`},
+		{name: "accept-language", acceptLanguage: "en-US,en;q=0.9", cookie: "", expected: "Please log in",
+			expectedLang: `lang="en"`},
+		{name: "cookie", acceptLanguage: "en-US", cookie: "ru", expected: "Необходимо войти", expectedLang: `lang="ru"`},
+		{name: "bad cookie", acceptLanguage: "en-US", cookie: "xx", expected: "Please log in", expectedLang: `lang="en"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: httprouter.LangCookieName, Value: tc.cookie}) //nolint:exhaustivestruct,exhaustruct
+			}
+
+			rec := srv.Serve(req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expected)
+			assert.Contains(t, rec.Body.String(), tc.expectedLang)
+		})
+	}
+}
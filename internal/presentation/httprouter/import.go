@@ -0,0 +1,98 @@
+package httprouter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/importer"
+	"github.com/n-r-w/log-server/internal/app/metrics"
+	"github.com/pkg/errors"
+)
+
+// максимальное количество ошибок в записях, возвращаемых в ответе на загрузку
+const maxImportErrors = 100
+
+// Результат загрузки файла
+type importResult struct {
+	importer.Progress
+	// Errors Первые maxImportErrors ошибок в записях
+	Errors []importer.LineError `json:"errors"`
+	// Error Ошибка, прервавшая загрузку. Продолжить можно с параметром skip=line
+	Error string `json:"error,omitempty"`
+}
+
+// Загрузка исторических записей из тела запроса (только админ). Параметры строки запроса: format
+// (ndjson, csv или proto-delimited), skip - номер строки, после которой продолжить прерванную загрузку, tz.
+// Записи не проверяются правилами оповещений и не пересылаются
+func (router *HTTPRouter) importLogRecords() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !currentUser(r).IsAdmin() {
+			router.respondError(w, r, http.StatusForbidden, errNotAdmin)
+
+			return
+		}
+
+		metrics.StreamsInFlight.Inc()
+		defer metrics.StreamsInFlight.Dec()
+
+		query := r.URL.Query()
+
+		format, err := importer.ParseFormat(query.Get("format"))
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		skip := 0
+		if v := query.Get("skip"); v != "" {
+			if skip, err = strconv.Atoi(v); err != nil || skip < 0 {
+				router.respondError(w, r, http.StatusBadRequest, errors.New("bad skip"))
+
+				return
+			}
+		}
+
+		loc, err := requestLocation(r)
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		res := importResult{
+			Progress: importer.Progress{Line: 0, Imported: 0, Failed: 0},
+			Errors:   []importer.LineError{},
+			Error:    "",
+		}
+
+		opts := importer.Options{
+			Format:    format,
+			Location:  loc,
+			BatchSize: importer.DefaultBatchSize,
+			Skip:      skip,
+			Progress:  nil,
+			Error: func(e importer.LineError) {
+				if len(res.Errors) < maxImportErrors {
+					res.Errors = append(res.Errors, e)
+				}
+			},
+		}
+
+		res.Progress, err = importer.Run(r.Context(), r.Body, router.domain.LogUsecase.Import, opts)
+		if err != nil {
+			// в ответе остается прогресс, чтобы клиент мог продолжить загрузку
+			res.Error = err.Error()
+			router.respond(w, r, httpStatus(err), res)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, res)
+	}
+}
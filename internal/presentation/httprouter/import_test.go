@@ -0,0 +1,118 @@
+package httprouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_Import(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	type result struct {
+		Line     int    `json:"line"`
+		Imported int    `json:"imported"`
+		Failed   int    `json:"failed"`
+		Error    string `json:"error"`
+		Errors   []struct {
+			Line int `json:"line"`
+		} `json:"errors"`
+	}
+
+	upload := func(userID uint64, query, body string) (int, result) {
+		req := httptest.NewRequest(http.MethodPost, "/api/private/import?"+query, strings.NewReader(body))
+		req.AddCookie(srv.SessionCookie(t, userID))
+		rec := srv.Serve(req)
+
+		var res result
+		_ = json.NewDecoder(rec.Body).Decode(&res)
+
+		return rec.Code, res
+	}
+
+	// выгрузка одного сервера загружается в другой
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	insertTestRecords(t, srv.LogRepo, start, 3)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/private/export?format=csv", nil)
+	req.AddCookie(srv.SessionCookie(t, srv.Config.SuperAdminID))
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	code, res := upload(srv.Config.SuperAdminID, "format=csv", rec.Body.String()+"2,bad time,,,x,,\n")
+	require.Equal(t, http.StatusOK, code, res.Error)
+	assert.Equal(t, 3, res.Imported)
+	assert.Equal(t, 1, res.Failed)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, 5, res.Errors[0].Line)
+
+	records, _, err := srv.LogRepo.Find(context.Background(), time.Time{}, time.Time{}, 100)
+	require.NoError(t, err)
+	assert.Len(t, *records, 6)
+
+	code, _ = upload(u.ID, "format=csv", "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = upload(srv.Config.SuperAdminID, "format=xml", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// оборванное сообщение: в ответе строка, после которой продолжить
+	code, res = upload(srv.Config.SuperAdminID, "format=proto-delimited&skip=2", "\x05ab")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 2, res.Line)
+	assert.NotEmpty(t, res.Error)
+}
+
+func TestHTTPRouter_ImportCSVRoundTrip(t *testing.T) {
+	src := initAuthTestCase(t)
+	dst := initAuthTestCase(t)
+
+	// сообщения, похожие на формулы и на уже экранированные формулы
+	messages := []string{`=HYPERLINK("http://example.com")`, "'=1+2", "'plain", "''", "'", "-", "plain"}
+
+	records := make([]model.LogRecord, 0, len(messages))
+	for i, m := range messages {
+		records = append(records, model.LogRecord{
+			ID:       0,
+			LogTime:  time.Date(2022, 4, 23, 10, i, 0, 0, time.UTC),
+			RealTime: time.Time{},
+			Level:    model.LevelInfo,
+			Message1: m,
+			Message2: "",
+			Message3: m,
+		})
+	}
+	require.NoError(t, src.LogRepo.Insert(context.Background(), &records))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/private/export?format=csv", nil)
+	req.AddCookie(src.SessionCookie(t, src.Config.SuperAdminID))
+	rec := src.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/private/import?format=csv", rec.Body)
+	req.AddCookie(dst.SessionCookie(t, dst.Config.SuperAdminID))
+	rec = dst.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	imported, _, err := dst.LogRepo.Find(context.Background(), time.Time{}, time.Time{}, 100)
+	require.NoError(t, err)
+
+	got := make([]string, 0, len(*imported))
+	for _, r := range *imported {
+		assert.Equal(t, r.Message1, r.Message3)
+		got = append(got, r.Message1)
+	}
+
+	assert.ElementsMatch(t, messages, got)
+}
@@ -2,42 +2,102 @@ package httprouter
 
 import (
 	"github.com/gorilla/handlers"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // инициализация маршрутов
 func (router *HTTPRouter) initRestRoutes() {
 	// установка middleware
-	router.router.Use(router.setRequestID) // подмешивание номера сессии
-	router.router.Use(router.logRequest)   // журналирование запросов
+	router.router.Use(router.setRequestID)          // подмешивание номера сессии
+	router.router.Use(router.logRequest)            // журналирование запросов
+	router.router.Use(router.collectMetrics)        // метрики запросов
+	router.router.Use(router.setTimeout)            // ограничение времени выполнения запроса
+	router.router.Use(router.extendStreamDeadlines) // таймауты соединения для выгрузки и загрузки
+	router.router.Use(router.decompressRequest)     // распаковка тела запроса в gzip
 
 	// разрешаем запросы к серверу c любых доменов (cross-origin resource sharing)
 	router.router.Use(handlers.CORS(handlers.AllowedOrigins([]string{"*"})))
 
+	// метрики Prometheus
+	router.router.Handle("/metrics", promhttp.Handler()).Methods("GET").Name("metrics")
+
+	// проверки состояния для оркестратора и информация о сборке
+	router.router.HandleFunc("/healthz", router.healthz()).Methods("GET").Name("healthz")
+	router.router.HandleFunc("/readyz", router.readyz()).Methods("GET").Name("readyz")
+	router.router.HandleFunc("/version", router.version()).Methods("GET").Name("version")
+
 	// создаем подчиненный роутер для запросов аутентификации
 	authSubrout := router.router.PathPrefix("/api/auth").Subrouter()
 	// логин
-	authSubrout.HandleFunc("/login", router.handleSessionsCreate()).Methods("POST")
+	authSubrout.HandleFunc("/login", router.handleSessionsCreate()).Methods("POST").Name("login")
 	// закрытие сессии
-	authSubrout.HandleFunc("/close", router.closeSession()).Methods("DELETE")
+	authSubrout.HandleFunc("/close", router.closeSession()).Methods("DELETE").Name("close")
 
 	// ========== запросы, которые возможны только после логина ============
 	// создаем подчиненный роутер
 	private := router.router.PathPrefix("/api/private").Subrouter()
 	// устанавливаем middleware для проверки валидности сессии
 	private.Use(router.AuthenticateUser)
+	// защита от CSRF для запросов из браузера
+	private.Use(router.checkCSRF)
 
 	// запрос с информацией о текущей сессии
-	private.HandleFunc("/whoami", router.handleWhoami())
+	private.HandleFunc("/whoami", router.handleWhoami()).Name("whoami")
 
 	// добавить пользователя
-	private.HandleFunc("/add-user", router.addUser()).Methods("POST")
+	private.HandleFunc("/add-user", router.addUser()).Methods("POST").Name("add-user")
 	// сменить пароль
-	private.HandleFunc("/change-password", router.changePassword()).Methods("PUT")
+	private.HandleFunc("/change-password", router.changePassword()).Methods("PUT").Name("change-password")
 	// получить список пользователей
-	private.HandleFunc("/users", router.getUsers()).Methods("GET")
+	private.HandleFunc("/users", router.getUsers()).Methods("GET").Name("users")
+	// изменить пользователя
+	private.HandleFunc("/users/{id:[0-9]+}", router.updateUser()).Methods("PUT").Name("update-user")
+	// удалить пользователя
+	private.HandleFunc("/users/{id:[0-9]+}", router.removeUser()).Methods("DELETE").Name("remove-user")
+	// токены доступа к API
+	private.HandleFunc("/users/{id:[0-9]+}/tokens", router.getUserTokens()).Methods("GET").Name("user-tokens")
+	private.HandleFunc("/users/{id:[0-9]+}/tokens", router.createToken()).Methods("POST").Name("create-token")
+	private.HandleFunc("/tokens/{id:[0-9]+}", router.revokeToken()).Methods("DELETE").Name("revoke-token")
+	// личный кабинет: смена своего пароля и управление своими сессиями
+	private.HandleFunc("/account/password", router.changeOwnPassword()).Methods("PUT").Name("account-password")
+	private.HandleFunc("/account/timezone", router.setOwnTimezone()).Methods("PUT").Name("account-timezone")
+	private.HandleFunc("/account/sessions", router.getAccountSessions()).Methods("GET").Name("account-sessions")
+	private.HandleFunc("/account/sessions/{id:[0-9]+}", router.revokeSession()).Methods("DELETE").
+		Name("revoke-session")
+	// сохраненные поиски
+	private.HandleFunc("/searches", router.getSearches()).Methods("GET").Name("searches")
+	private.HandleFunc("/searches", router.createSearch()).Methods("POST").Name("create-search")
+	private.HandleFunc("/searches/{id:[0-9]+}", router.updateSearch()).Methods("PUT").Name("update-search")
+	private.HandleFunc("/searches/{id:[0-9]+}", router.removeSearch()).Methods("DELETE").Name("remove-search")
+	// правила оповещений и их состояние
+	private.HandleFunc("/alerts", router.getAlerts()).Methods("GET").Name("alerts")
+	private.HandleFunc("/alerts/rules", router.getAlertRules()).Methods("GET").Name("alert-rules")
+	private.HandleFunc("/alerts/rules", router.createAlertRule()).Methods("POST").Name("create-alert-rule")
+	private.HandleFunc("/alerts/rules/{id:[0-9]+}", router.updateAlertRule()).Methods("PUT").Name("update-alert-rule")
+	private.HandleFunc("/alerts/rules/{id:[0-9]+}", router.removeAlertRule()).Methods("DELETE").
+		Name("remove-alert-rule")
+	private.HandleFunc("/alerts/rules/{id:[0-9]+}/silence", router.silenceAlertRule()).Methods("POST").
+		Name("silence-alert-rule")
+	private.HandleFunc("/notifications/channels", router.getNotifyChannels()).Methods("GET").Name("notify-channels")
+	private.HandleFunc("/notifications/channels/{name}/test", router.testNotifyChannel()).Methods("POST").
+		Name("test-notify-channel")
+	private.HandleFunc("/notifications/deliveries", router.getNotifyDeliveries()).Methods("GET").
+		Name("notify-deliveries")
+	// перезагрузить конфигурацию (аналог SIGHUP)
+	private.HandleFunc("/reload-config", router.reloadConfig()).Methods("POST").Name("reload-config")
 
 	// добавить запись в лог
-	private.HandleFunc("/add-log", router.addLogRecord()).Methods("POST")
+	private.HandleFunc("/add-log", router.addLogRecord()).Methods("POST").Name("add-log")
+	// загрузить исторические записи из файла (только админ)
+	private.HandleFunc("/import", router.importLogRecords()).Methods("POST").Name("import")
+	// выгрузить записи в CSV, NDJSON или Protobuf
+	private.HandleFunc("/export", router.exportLogRecords()).Methods("GET").Name("export")
 	// получить список записей из лога. Ответ в gzip формате
-	private.HandleFunc("/records", router.getLogRecords()).Methods("GET")
+	private.HandleFunc("/records", router.getLogRecords()).Methods("GET").Name("records")
+	// получить страницу записей из лога
+	private.HandleFunc("/records/page", router.getLogRecordsPage()).Methods("GET").Name("records-page")
+	// получить запись по ID и запись вместе с соседними записями
+	private.HandleFunc("/records/{id:[0-9]+}", router.getLogRecord()).Methods("GET").Name("record")
+	private.HandleFunc("/records/{id:[0-9]+}/context", router.getLogRecordContext()).Methods("GET").
+		Name("record-context")
 }
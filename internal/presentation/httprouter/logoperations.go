@@ -4,17 +4,27 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	schemalog "github.com/n-r-w/log-server/api/schema/schema.log"
 	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+var errReadOnly = errors.New("user has read-only access")
+
 // Добавить в лог
 func (router *HTTPRouter) addLogRecord() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if !currentUser(r).CanWrite() {
+			router.respondError(w, r, http.StatusForbidden, errReadOnly)
+
+			return
+		}
+
 		req := &[]model.LogRecord{}
 		// парсим входящий json
 		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
@@ -23,8 +33,8 @@ func (router *HTTPRouter) addLogRecord() http.HandlerFunc {
 			return
 		}
 
-		if err := router.domain.LogUsecase.Insert(req); err != nil {
-			router.respondError(w, r, http.StatusForbidden, err)
+		if err := router.domain.LogUsecase.Insert(r.Context(), req); err != nil {
+			router.respondDomainError(w, r, err)
 
 			return
 		}
@@ -33,17 +43,20 @@ func (router *HTTPRouter) addLogRecord() http.HandlerFunc {
 	}
 }
 
-// Получить записи из лога
+// Получить записи из лога. Интервал передается в теле запроса: timeFrom и timeTo в формате RFC3339.
+// Если в строке запроса задан часовой пояс tz, timeFrom и timeTo можно указать без смещения в этом часовом поясе,
+// а время записей в JSON ответе переводится в него.
+// Возвращается не больше MAX_LOG_RECORDS_RESULT записей, об усечении результата сообщает хедер RecordsLimitedHeaderName
 func (router *HTTPRouter) getLogRecords() http.HandlerFunc {
 	type requestParams struct {
-		TimeFrom time.Time `json:"timeFrom"`
-		TimeTo   time.Time `json:"timeTo"`
+		TimeFrom string `json:"timeFrom"`
+		TimeTo   string `json:"timeTo"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		req := &requestParams{
-			TimeFrom: time.Time{},
-			TimeTo:   time.Time{},
+			TimeFrom: "",
+			TimeTo:   "",
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
@@ -52,14 +65,44 @@ func (router *HTTPRouter) getLogRecords() http.HandlerFunc {
 			return
 		}
 
-		records, _, err := router.domain.LogUsecase.Find(req.TimeFrom, req.TimeTo, 1)
-		if err != nil || records == nil {
-			router.respondError(w, r, http.StatusInternalServerError, err)
+		loc, err := requestLocation(r)
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		var timeFrom, timeTo time.Time
+
+		if req.TimeFrom != "" {
+			if timeFrom, err = parseRequestTime(req.TimeFrom, loc); err != nil {
+				router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "bad timeFrom"))
+
+				return
+			}
+		}
+
+		if req.TimeTo != "" {
+			if timeTo, err = parseRequestTime(req.TimeTo, loc); err != nil {
+				router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "bad timeTo"))
+
+				return
+			}
+		}
+
+		records, limited, err := router.domain.LogUsecase.Find(r.Context(), timeFrom, timeTo,
+			router.config.MaxLogRecordsResult)
+		if err != nil {
+			router.respondDomainError(w, r, err)
 
 			return
 		}
 
-		if len(*records) == 0 {
+		if limited {
+			w.Header().Set(RecordsLimitedHeaderName, "true")
+		}
+
+		if records == nil || len(*records) == 0 {
 			router.respond(w, r, http.StatusOK, nil)
 
 			return
@@ -94,7 +137,167 @@ func (router *HTTPRouter) getLogRecords() http.HandlerFunc {
 			return
 		}
 
+		if loc != nil {
+			for i := range *records {
+				(*records)[i].LogTime = (*records)[i].LogTime.In(loc)
+				(*records)[i].RealTime = (*records)[i].RealTime.In(loc)
+			}
+		}
+
 		// отдаем с gzip сжатием если клиент это желает
 		router.respondCompressed(w, r, http.StatusOK, records)
 	}
 }
+
+// Получить страницу записей из лога. Параметры передаются в строке запроса:
+// from и to в формате RFC3339 (не заданы - без ограничения), cursor - nextCursor предыдущей страницы, limit - размер страницы.
+// Если задан часовой пояс tz, from и to можно указать без смещения в этом часовом поясе,
+// а время записей в ответе переводится в него
+func (router *HTTPRouter) getLogRecordsPage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		loc, err := requestLocation(r)
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		var timeFrom, timeTo time.Time
+
+		if v := query.Get("from"); v != "" {
+			if timeFrom, err = parseRequestTime(v, loc); err != nil {
+				router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "bad from"))
+
+				return
+			}
+		}
+
+		if v := query.Get("to"); v != "" {
+			if timeTo, err = parseRequestTime(v, loc); err != nil {
+				router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "bad to"))
+
+				return
+			}
+		}
+
+		limit := router.config.Runtime().WebPageSize
+		if v := query.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil {
+				router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "bad limit"))
+
+				return
+			}
+		}
+
+		if limit > router.config.MaxLogRecordsResult {
+			limit = router.config.MaxLogRecordsResult
+		}
+
+		page, err := router.domain.LogUsecase.FindPage(r.Context(), timeFrom, timeTo, query.Get("cursor"), limit)
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		if loc != nil {
+			for i := range page.Records {
+				page.Records[i].LogTime = page.Records[i].LogTime.In(loc)
+				page.Records[i].RealTime = page.Records[i].RealTime.In(loc)
+			}
+		}
+
+		router.respondCompressed(w, r, http.StatusOK, page)
+	}
+}
+
+// Форматы времени без смещения, допустимые при заданном часовом поясе
+var localTimeFormats = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02"}
+
+// Время из параметра запроса в RFC3339. Если задан часовой пояс loc, время можно указать без смещения
+func parseRequestTime(v string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, v)
+	if err == nil || loc == nil {
+		return t, errors.Wrap(err, "parse error")
+	}
+
+	for _, layout := range localTimeFormats {
+		if t, err = time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.Wrap(err, "parse error")
+}
+
+// Количество соседних записей с каждой стороны по умолчанию
+const defaultRecordContext = 10
+
+func (router *HTTPRouter) getLogRecord() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		record, err := router.domain.LogUsecase.FindByID(r.Context(), id)
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, record)
+	}
+}
+
+// Запись вместе с соседними записями. Количество записей с каждой стороны задается параметром count
+func (router *HTTPRouter) getLogRecordContext() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		count, err := router.recordContextCount(r, "count")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		res, err := router.domain.LogUsecase.FindContext(r.Context(), id, count)
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respondCompressed(w, r, http.StatusOK, res)
+	}
+}
+
+// Количество соседних записей из параметра запроса. Ограничено размером страницы веб интерфейса
+func (router *HTTPRouter) recordContextCount(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return defaultRecordContext, nil
+	}
+
+	count, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "bad %s", name)
+	}
+
+	if pageSize := router.config.Runtime().WebPageSize; count > pageSize {
+		count = pageSize
+	}
+
+	return count, nil
+}
@@ -0,0 +1,352 @@
+package httprouter_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/httprouter"
+	"github.com/n-r-w/log-server/internal/repository"
+	"github.com/n-r-w/log-server/internal/testserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Добавить count записей с интервалом в минуту, начиная с start. Две последние записи имеют одинаковое время
+func insertTestRecords(t *testing.T, repo repository.LogInterface, start time.Time, count int) {
+	t.Helper()
+
+	records := make([]model.LogRecord, 0, count)
+	for i := 0; i < count; i++ {
+		logTime := start.Add(time.Duration(i) * time.Minute)
+		if i == count-1 {
+			logTime = start.Add(time.Duration(i-1) * time.Minute)
+		}
+
+		records = append(records, model.LogRecord{
+			ID:       0,
+			LogTime:  logTime,
+			RealTime: time.Time{},
+			Level:    model.LevelInfo,
+			Message1: fmt.Sprintf("record %d", i),
+			Message2: "",
+			Message3: "",
+		})
+	}
+
+	require.NoError(t, repo.Insert(context.Background(), &records))
+}
+
+func TestHTTPRouter_RecordsPage(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	insertTestRecords(t, srv.LogRepo, start, 5)
+
+	seen := map[uint64]bool{}
+	cursor := ""
+	pages := 0
+
+	var last *model.LogRecord
+
+	for {
+		query := url.Values{}
+		query.Set("from", start.Format(time.RFC3339))
+		query.Set("to", start.Add(time.Hour).Format(time.RFC3339))
+		query.Set("limit", "2")
+		query.Set("cursor", cursor)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/private/records/page?"+query.Encode(), nil)
+		req.AddCookie(cookie)
+		rec := srv.Serve(req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var page model.LogPage
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+		pages++
+
+		for i := range page.Records {
+			r := page.Records[i]
+			assert.False(t, seen[r.ID], "record %d returned twice", r.ID)
+			seen[r.ID] = true
+
+			// по убыванию времени
+			if last != nil {
+				assert.False(t, r.LogTime.After(last.LogTime))
+			}
+
+			last = &r
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor = page.NextCursor
+	}
+
+	assert.Len(t, seen, 5)
+	assert.Equal(t, 3, pages)
+
+	// старый запрос возвращает все записи интервала
+	req := httptest.NewRequest(http.MethodGet, "/api/private/records",
+		strings.NewReader(`{"timeFrom": "2022-04-23T00:00:00Z", "timeTo": "2022-04-24T00:00:00Z"}`))
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Empty(t, rec.Header().Get(httprouter.RecordsLimitedHeaderName))
+
+	var records []model.LogRecord
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&records))
+	assert.Len(t, records, 5)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/private/records/page?cursor=bad", nil)
+	req.AddCookie(cookie)
+	assert.Equal(t, http.StatusBadRequest, srv.Serve(req).Code)
+}
+
+func TestHTTPRouter_RecordsLimited(t *testing.T) {
+	srv := testserver.New(t, func(cfg *config.Config) { cfg.MaxLogRecordsResult = 3 })
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	insertTestRecords(t, srv.LogRepo, time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC), 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/private/records",
+		strings.NewReader(`{"timeFrom": "2022-04-23T00:00:00Z", "timeTo": "2022-04-24T00:00:00Z"}`))
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// результат усечен, и клиент об этом знает
+	assert.Equal(t, "true", rec.Header().Get(httprouter.RecordsLimitedHeaderName))
+
+	var records []model.LogRecord
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&records))
+	assert.Len(t, records, 3)
+}
+
+func TestHTTPRouter_WebSearchPage(t *testing.T) {
+	srv := testserver.New(t, func(cfg *config.Config) {
+		cfg.WebPageSize = 2
+		cfg.MaxLogRecordsResultWeb = 3
+	})
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	insertTestRecords(t, srv.LogRepo, start, 5)
+
+	search := "from=2022-04-23T09:00&to=2022-04-23T12:00"
+
+	// первая страница отрисовывается вместе с таблицей
+	req := httptest.NewRequest(http.MethodGet, "/search?"+search, nil)
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, strings.Count(rec.Body.String(), "record "))
+	assert.Contains(t, rec.Body.String(), `id="loadMore"`)
+
+	// следующая страница урезается до MAX_LOG_RECORDS_RESULT_WEB
+	page, err := srv.Domain.LogUsecase.FindPage(context.Background(), start, start.Add(time.Hour), "", 2)
+	require.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet,
+		"/search/page?"+search+"&loaded=2&cursor="+url.QueryEscape(page.NextCursor), nil)
+	req.AddCookie(cookie)
+	rec = srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, strings.Count(rec.Body.String(), "<tr"))
+	assert.NotEmpty(t, rec.Header().Get("X-Next-Cursor"))
+
+	req = httptest.NewRequest(http.MethodGet,
+		"/search/page?"+search+"&loaded=3&cursor="+url.QueryEscape(page.NextCursor), nil)
+	req.AddCookie(cookie)
+	rec = srv.Serve(req)
+	assert.NotEmpty(t, rec.Header().Get("X-Records-Limited"))
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestHTTPRouter_Record(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	insertTestRecords(t, srv.LogRepo, start, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/private/records/3", nil)
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var record model.LogRecord
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&record))
+	assert.Equal(t, uint64(3), record.ID)
+	assert.Equal(t, "record 2", record.Message1)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/private/records/100", nil)
+	req.AddCookie(cookie)
+	assert.Equal(t, http.StatusNotFound, srv.Serve(req).Code)
+
+	// по одной соседней записи с каждой стороны
+	req = httptest.NewRequest(http.MethodGet, "/api/private/records/3/context?count=1", nil)
+	req.AddCookie(cookie)
+	rec = srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var recordContext model.LogRecordContext
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&recordContext))
+	assert.Equal(t, uint64(3), recordContext.Record.ID)
+	require.Len(t, recordContext.Newer, 1)
+	assert.Equal(t, "record 3", recordContext.Newer[0].Message1)
+	require.Len(t, recordContext.Older, 1)
+	assert.Equal(t, "record 1", recordContext.Older[0].Message1)
+
+	// страница записи с соседними записями
+	req = httptest.NewRequest(http.MethodGet, "/record/3?context=1", nil)
+	req.AddCookie(cookie)
+	rec = srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `href="/record/3"`)
+	assert.Contains(t, rec.Body.String(), "Скрыть соседние записи")
+	assert.Contains(t, rec.Body.String(), `href="/record/4"`)
+	assert.Contains(t, rec.Body.String(), `href="/record/2"`)
+}
+
+func TestHTTPRouter_RecordsPageTimezone(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	insertTestRecords(t, srv.LogRepo, start, 5)
+
+	request := func(query url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/private/records/page?"+query.Encode(), nil)
+		req.AddCookie(cookie)
+
+		return srv.Serve(req)
+	}
+
+	// локальное время интервала в часовом поясе tz: 13:02:30 по Москве = 10:02:30 UTC
+	query := url.Values{}
+	query.Set("from", "2022-04-23T13:02:30")
+	query.Set("to", "2022-04-23T14:00")
+	query.Set("tz", "Europe/Moscow")
+
+	rec := request(query)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var page model.LogPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Records, 2)
+
+	// время записей в часовом поясе запроса
+	_, offset := page.Records[0].LogTime.Zone()
+	assert.Equal(t, 3*60*60, offset)
+	assert.True(t, page.Records[0].LogTime.Equal(start.Add(3*time.Minute)))
+
+	// без tz локальное время не принимается
+	query.Del("tz")
+	assert.Equal(t, http.StatusBadRequest, request(query).Code)
+
+	query.Set("tz", "Mars/Olympus")
+	assert.Equal(t, http.StatusBadRequest, request(query).Code)
+}
+
+func TestHTTPRouter_RecordsTimezone(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	insertTestRecords(t, srv.LogRepo, start, 5)
+
+	request := func(tz, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/private/records?tz="+url.QueryEscape(tz), strings.NewReader(body))
+		req.AddCookie(cookie)
+
+		return srv.Serve(req)
+	}
+
+	// 13:02:30 по Москве = 10:02:30 UTC
+	rec := request("Europe/Moscow", `{"timeFrom": "2022-04-23T13:02:30", "timeTo": "2022-04-23T14:00"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var records []model.LogRecord
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&records))
+	require.Len(t, records, 2)
+
+	for _, r := range records {
+		_, offset := r.LogTime.Zone()
+		assert.Equal(t, 3*60*60, offset)
+		assert.False(t, r.LogTime.Before(start.Add(3*time.Minute)))
+	}
+
+	// время со смещением принимается как раньше
+	rec = request("", `{"timeFrom": "2022-04-23T10:02:30Z", "timeTo": "2022-04-23T11:00:00.000Z"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&records))
+	assert.Len(t, records, 2)
+
+	// без tz локальное время не принимается
+	assert.Equal(t, http.StatusBadRequest, request("", `{"timeFrom": "2022-04-23T13:02:30"}`).Code)
+}
+
+func TestHTTPRouter_AddLogGzip(t *testing.T) {
+	srv := initAuthTestCase(t)
+	cookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	_, err := zw.Write([]byte(`[{"logTime":"2022-04-23T10:00:00Z","level":2,"message1":"compressed"}]`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/private/add-log", &body)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.AddCookie(cookie)
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	records, _, err := srv.LogRepo.Find(context.Background(), time.Time{}, time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, *records, 1)
+	assert.Equal(t, "compressed", (*records)[0].Message1)
+
+	// тело, не являющееся gzip
+	req = httptest.NewRequest(http.MethodPost, "/api/private/add-log", strings.NewReader("[]"))
+	req.Header.Set("Content-Encoding", "gzip")
+	req.AddCookie(cookie)
+	assert.Equal(t, http.StatusBadRequest, srv.Serve(req).Code)
+}
+
+func TestHTTPRouter_AddLogGzipLimit(t *testing.T) {
+	srv := testserver.New(t, func(cfg *config.Config) { cfg.MaxDecompressedBodyMB = 1 })
+
+	// 2 МБ после распаковки, несколько КБ в архиве
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	_, err := fmt.Fprintf(zw, `[{"logTime":"2022-04-23T10:00:00Z","level":2,"message1":"%s"}]`,
+		strings.Repeat("a", 2<<20))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.Less(t, body.Len(), 1<<20)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/private/add-log", &body)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.AddCookie(srv.SessionCookie(t, srv.Config.SuperAdminID))
+	assert.Equal(t, http.StatusBadRequest, srv.Serve(req).Code)
+
+	records, _, err := srv.LogRepo.Find(context.Background(), time.Time{}, time.Time{}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, *records)
+}
@@ -0,0 +1,28 @@
+package httprouter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPRouter_Metrics(t *testing.T) {
+	router := initAuthTestCase(t).Router
+
+	// запрос, который должен попасть в метрики
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/private/whoami", nil)
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `logserver_http_requests_total{code="401",method="GET",route="whoami"}`)
+	assert.Contains(t, body, "logserver_http_request_duration_seconds_bucket")
+}
@@ -1,22 +1,111 @@
 package httprouter
 
 import (
+	"compress/gzip"
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/app/metrics"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
-// Добавляем к контексту уникальный ID сесии с ключом ctxKeyRequestID
+// Добавляем к контексту уникальный ID запроса. Он попадает в журнал всех слоев через logger.FromContext
 func (router *HTTPRouter) setRequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id := uuid.New().String()
 		w.Header().Set("X-Request-ID", id)
-		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKeyRequestID, id)))
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), id)))
+	})
+}
+
+// Ограничиваем время выполнения запроса. Таймаут берется из настроек маршрута или общий.
+// При отмене контекста (таймаут или разрыв соединения клиентом) прерываются и запросы к БД
+func (router *HTTPRouter) setTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := router.routeTimeout(r)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Таймаут обработки запроса: из настроек маршрута или общий. 0 - без ограничения
+func (router *HTTPRouter) routeTimeout(r *http.Request) time.Duration {
+	timeout := router.config.QueryTimeoutSec
+	if route := mux.CurrentRoute(r); route != nil {
+		if t, ok := router.config.RouteQueryTimeoutSec[route.GetName()]; ok {
+			timeout = t
+		}
+	}
+
+	if timeout <= 0 {
+		return 0
+	}
+
+	return time.Duration(timeout) * time.Second
+}
+
+// Маршруты с потоковой передачей записей, для которых таймауты чтения и записи сервера
+// (HTTP_READ_TIMEOUT_SEC, HTTP_WRITE_TIMEOUT_SEC) продлеваются до таймаута маршрута
+var streamRoutes = map[string]bool{"export": true, "import": true}
+
+// Продление таймаутов соединения для выгрузки и загрузки записей, которые длятся дольше обычных запросов
+func (router *HTTPRouter) extendStreamDeadlines(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && streamRoutes[route.GetName()] {
+			// нулевое время - без ограничения
+			var deadline time.Time
+			if timeout := router.routeTimeout(r); timeout > 0 {
+				deadline = time.Now().Add(timeout)
+			}
+
+			if err := setConnDeadlines(w, deadline); err != nil {
+				logger.FromContext(r.Context()).Warnf("extend deadlines: %v", err)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Распаковка тела запроса, сжатого gzip (Content-Encoding: gzip). Так пакеты записей отправляет pkg/client.
+// Размер распакованного тела ограничен MAX_DECOMPRESSED_BODY_MB, чтобы небольшой архив не занял всю память
+func (router *HTTPRouter) decompressRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, errors.Wrap(err, "gzip"))
+
+			return
+		}
+		defer zr.Close()
+
+		r.Body = http.MaxBytesReader(w, zr, int64(router.config.MaxDecompressedBodyMB)<<20) //nolint:gomnd
+		r.ContentLength = -1
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+
+		next.ServeHTTP(w, r)
 	})
 }
 
@@ -26,16 +115,12 @@ func (router *HTTPRouter) logRequest(next http.Handler) http.Handler {
 		// пишем инфу о начале обработки запроса
 		lg := logger.Logger().WithFields(logrus.Fields{
 			"remote_addr": r.RemoteAddr,
-			"request_id":  r.Context().Value(ctxKeyRequestID),
+			"request_id":  logger.RequestID(r.Context()),
 		})
 		lg.Infof("started %s %s", r.Method, r.RequestURI)
 
 		start := time.Now()
-		rw := &responseWriter{
-			ResponseWriter: w,
-			code:           http.StatusOK,
-			err:            nil,
-		}
+		rw := wrapResponseWriter(w)
 
 		// вызываем обработчик нижнего уровня
 		next.ServeHTTP(rw, r)
@@ -69,3 +154,39 @@ func (router *HTTPRouter) logRequest(next http.Handler) http.Handler {
 		)
 	})
 }
+
+// Сбор метрик запросов: количество, время обработки и число одновременно обрабатываемых запросов
+func (router *HTTPRouter) collectMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		start := time.Now()
+		rw := wrapResponseWriter(w)
+
+		next.ServeHTTP(rw, r)
+
+		labels := []string{routeName(r), r.Method, strconv.Itoa(rw.code)}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Имя маршрута для метрик. Используется имя маршрута, а при его отсутствии шаблон пути,
+// чтобы количество значений метки не зависело от параметров запроса
+func routeName(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unknown"
+	}
+
+	if name := route.GetName(); name != "" {
+		return name
+	}
+
+	if tpl, err := route.GetPathTemplate(); err == nil {
+		return tpl
+	}
+
+	return "unknown"
+}
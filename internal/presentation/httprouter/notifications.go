@@ -0,0 +1,59 @@
+package httprouter
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Каналы уведомлений
+func (router *HTTPRouter) getNotifyChannels() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channels, err := router.domain.NotifyUsecase.Channels(currentUser(r))
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, channels)
+	}
+}
+
+// Журнал доставки уведомлений
+func (router *HTTPRouter) getNotifyDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveries, err := router.domain.NotifyUsecase.Deliveries(currentUser(r))
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, deliveries)
+	}
+}
+
+// Отправить тестовое уведомление в канал. Если отправить не удалось, возвращается 502 с результатом отправки
+func (router *HTTPRouter) testNotifyChannel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		delivery, err := router.domain.NotifyUsecase.Test(r.Context(), currentUser(r), mux.Vars(r)["name"])
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		tr := requestPrinter(r)
+		if !delivery.Delivered {
+			router.addFlash(w, r, flashError, tr.Tf("Не удалось отправить уведомление в канал %s: %s",
+				delivery.Channel, delivery.Error))
+			router.respond(w, r, http.StatusBadGateway, delivery)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, tr.Tf("Тестовое уведомление отправлено в канал %s", delivery.Channel))
+		router.respond(w, r, http.StatusOK, delivery)
+	}
+}
@@ -0,0 +1,71 @@
+package httprouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/testserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_Notifications(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer receiver.Close()
+
+	srv := testserver.New(t, func(cfg *config.Config) {
+		cfg.NotifyChannels = []config.NotifyChannel{
+			{Name: "ok", Type: config.NotifyWebhook, URL: receiver.URL + "/ok"},
+			{Name: "broken", Type: config.NotifyWebhook, URL: receiver.URL + "/broken"},
+		}
+	})
+
+	u := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	adminCookie := srv.SessionCookie(t, srv.Config.SuperAdminID)
+
+	request := func(method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(cookie)
+
+		return srv.Serve(req)
+	}
+
+	assert.Equal(t, http.StatusForbidden,
+		request(http.MethodPost, "/api/private/notifications/channels/ok/test", srv.SessionCookie(t, u.ID)).Code)
+	assert.Equal(t, http.StatusNotFound,
+		request(http.MethodPost, "/api/private/notifications/channels/missing/test", adminCookie).Code)
+
+	rec := request(http.MethodGet, "/api/private/notifications/channels", adminCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"name": "ok", "type": "webhook"}, {"name": "broken", "type": "webhook"}]`, rec.Body.String())
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/api/private/notifications/channels/ok/test", adminCookie).Code)
+	assert.Equal(t, http.StatusBadGateway,
+		request(http.MethodPost, "/api/private/notifications/channels/broken/test", adminCookie).Code)
+
+	rec = request(http.MethodGet, "/api/private/notifications/deliveries", adminCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var deliveries []model.NotifyDelivery
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&deliveries))
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, "broken", deliveries[0].Channel)
+	assert.False(t, deliveries[0].Delivered)
+	assert.Contains(t, deliveries[0].Error, "unexpected status 500")
+	assert.True(t, deliveries[1].Delivered)
+
+	rec = request(http.MethodGet, "/admin", adminCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `data-channel="broken"`)
+}
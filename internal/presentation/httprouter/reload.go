@@ -0,0 +1,40 @@
+package httprouter
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+var errReloadNotConfigured = errors.New("reload is not configured")
+
+// SetReload Установить функцию перезагрузки конфигурации, вызываемую через /api/private/reload-config.
+// Обычно это app.Lifecycle.Reload, т.е. то же, что происходит по SIGHUP
+func (router *HTTPRouter) SetReload(reload func() error) {
+	router.reload = reload
+}
+
+// Перезагрузить конфигурацию (только админ). Если новая конфигурация некорректна, продолжает действовать текущая
+func (router *HTTPRouter) reloadConfig() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !currentUser(r).IsAdmin() {
+			router.respondError(w, r, http.StatusForbidden, errNotAdmin)
+
+			return
+		}
+
+		if router.reload == nil {
+			router.respondError(w, r, http.StatusNotImplemented, errReloadNotConfigured)
+
+			return
+		}
+
+		if err := router.reload(); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,42 @@
+package httprouter_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPRouter_ReloadConfig(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	assert.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	var reloadErr error
+
+	reloads := 0
+	srv.Router.SetReload(func() error {
+		reloads++
+
+		return reloadErr
+	})
+
+	reload := func(userID uint64) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/private/reload-config", nil)
+		req.AddCookie(srv.SessionCookie(t, userID))
+
+		return srv.Serve(req).Code
+	}
+
+	assert.Equal(t, http.StatusOK, reload(srv.Config.SuperAdminID))
+	assert.Equal(t, http.StatusForbidden, reload(u.ID))
+
+	reloadErr = apperr.New(apperr.KindValidation, "bad config")
+	assert.Equal(t, http.StatusBadRequest, reload(srv.Config.SuperAdminID))
+	assert.Equal(t, 2, reloads)
+}
@@ -16,3 +16,29 @@ func (w *responseWriter) WriteHeader(statusCode int) {
 	w.code = statusCode
 	w.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Unwrap Исходный http.ResponseWriter (для http.ResponseController)
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush Отправка буферизованных данных клиенту (для потоковых ответов)
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Обертка ответа. Уже обернутый ответ используется повторно, чтобы ошибку, сохраненную respondError,
+// видели все middleware (logRequest и collectMetrics)
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+
+	return &responseWriter{
+		ResponseWriter: w,
+		code:           http.StatusOK,
+		err:            nil,
+	}
+}
@@ -5,19 +5,18 @@ import (
 	"compress/flate"
 	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
-	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
+	"github.com/n-r-w/log-server/assets"
 	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/app/logger"
 	"github.com/n-r-w/log-server/internal/domain"
@@ -35,20 +34,21 @@ const (
 	AppName = "LogServer"
 	// SessionName Ключ для хранения информации о сессии со стороны пользователя
 	SessionName = "logserver"
-	// UserIDKeyName Ключ для хранения id пользователя в сессии (в куках)
-	UserIDKeyName = "user_id"
+	// SessionKeyName Ключ для хранения идентификатора сессии пользователя в куках
+	SessionKeyName = "session"
 
 	// BinaryFormatHeaderName Имя хедера REST запроса, в котором клиент указывает в каком виде он желает получить ответ
 	BinaryFormatHeaderName = "binary-format"
 	// BinaryFormatHeaderProtobuf Требуется ответ в формате protobuf
 	BinaryFormatHeaderProtobuf = "protobuf"
+	// RecordsLimitedHeaderName Имя хедера ответа, который равен "true", если записей за интервал больше MAX_LOG_RECORDS_RESULT
+	// и ответ содержит только их часть
+	RecordsLimitedHeaderName = "X-Records-Limited"
 )
 
 const (
 	// Ключ для хранения модели пользователя в контексте запроса после успешной аунтетификации
 	ctxKeyUser contextKey = iota
-	// Ключ для хранения в контексте запроса уникального номера сессии
-	ctxKeyRequestID contextKey = iota
 )
 
 // Формат бинарного ответа
@@ -61,17 +61,25 @@ const (
 
 // HTTPRouter Объект роутер
 type HTTPRouter struct {
-	router       *mux.Router    // Управление маршрутами
-	sessionStore sessions.Store // Управление сессиями пользователей
-	domain       *domain.Domain // Унтерфейсы доменной области (сценарии)
+	router       *mux.Router      // Управление маршрутами
+	sessionStore sessions.Store   // Управление сессиями пользователей
+	domain       *domain.Domain   // Унтерфейсы доменной области (сценарии)
+	server       *http.Server     // HTTP сервер, создается при запуске
+	tlsCerts     *tlsCertificates // Сертификаты TLS, если сервер работает по HTTPS
+	config       *config.Config   // Конфигурация
+	reload       func() error     // Перезагрузка конфигурации и сертификатов (см. SetReload)
 }
 
 // NewRouter Создание роутера
-func NewRouter(domain *domain.Domain, sessionStore sessions.Store) *HTTPRouter {
+func NewRouter(domain *domain.Domain, sessionStore sessions.Store, cfg *config.Config) *HTTPRouter {
 	r := HTTPRouter{
 		router:       mux.NewRouter(),
 		sessionStore: sessionStore,
 		domain:       domain,
+		config:       cfg,
+		reload:       nil,
+		server:       nil,
+		tlsCerts:     nil,
 	}
 	// инициализация маршрутов для rest api
 	r.initRestRoutes()
@@ -81,87 +89,118 @@ func NewRouter(domain *domain.Domain, sessionStore sessions.Store) *HTTPRouter {
 	return &r
 }
 
-// Start Запуск на выполнение
-func (router *HTTPRouter) Start() error {
-	l, err := net.Listen("tcp", config.AppConfig.BindAddr)
+// ServeHTTP Обработка запроса. Позволяет использовать роутер как http.Handler (например, в тестах)
+func (router *HTTPRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router.router.ServeHTTP(w, r)
+}
+
+// Name Имя компонента (для app.Lifecycle)
+func (router *HTTPRouter) Name() string {
+	return "http server"
+}
+
+// Start Запуск на выполнение. Не блокируется: сервер работает в отдельной горутине.
+// Если сервер аварийно завершил работу, вызывается fail
+func (router *HTTPRouter) Start(fail func(err error)) error {
+	// без встроенных ресурсов веб интерфейс не работает, поэтому сервер не запускается
+	if missing := assets.Missing(); len(missing) > 0 {
+		return errors.Errorf("%d web assets are not embedded (%s): run make assets and rebuild the server",
+			len(missing), missing[0].Path)
+	}
+
+	l, err := net.Listen("tcp", router.config.BindAddr)
 	if err != nil {
 		return errors.Wrap(err, "listen error")
 	}
 
+	scheme := "http"
+
+	if router.config.TLSEnabled() {
+		router.tlsCerts, err = newTLSCertificates(router.config.TLSCertFile, router.config.TLSKeyFile,
+			router.config.TLSClientCAFile)
+		if err != nil {
+			_ = l.Close()
+
+			return err
+		}
+
+		l = tls.NewListener(l, router.tlsCerts.config(router.config.TLSClientCertRequired))
+		scheme = "https"
+	}
+
 	// headersOk := handlers.AllowedHeaders([]string{"X-Requested-With"})
 	// originsOk := handlers.AllowedOrigins([]string{"*"})
 	// methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
 
 	// таймауты
-	srv := &http.Server{
-		WriteTimeout: time.Second * 15,
-		ReadTimeout:  time.Second * 15,
-		IdleTimeout:  time.Second * 60,
+	router.server = &http.Server{
+		WriteTimeout: time.Duration(router.config.HTTPWriteTimeoutSec) * time.Second,
+		ReadTimeout:  time.Duration(router.config.HTTPReadTimeoutSec) * time.Second,
+		IdleTimeout:  time.Duration(router.config.HTTPIdleTimeoutSec) * time.Second,
 		// Handler:      handlers.CORS(originsOk, methodsOk)(router.router),
 		Handler: router.router,
 	}
 
-	logger.Logger().Infof("%s listening on %s", AppName, config.AppConfig.BindAddr)
+	logger.Logger().Infof("%s listening on %s://%s", AppName, scheme, router.config.BindAddr)
 
 	// Начинаем слушать порт в отдельном потоке
 	go func() {
-		if err := srv.Serve(l); err != nil {
-			log.Println(err)
+		if err := router.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fail(errors.Wrap(err, "serve error"))
 		}
 	}()
 
-	// Обрабатываем сигнал SIGINT (Ctrl+C)
-	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) перехватываться не будут
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-
-	// Ждем получения сигнала
-	<-c
-
-	// Время ожидания закрытия соединений
-	wait := time.Second * 15
+	return nil
+}
 
-	// Создаем контекст для закрытия
-	ctx, cancel := context.WithTimeout(context.Background(), wait)
-	defer cancel()
+// Stop Остановка сервера. Новые соединения не принимаются, текущие запросы обрабатываются
+// до их завершения или истечения ctx
+func (router *HTTPRouter) Stop(ctx context.Context) error {
+	if router.server == nil {
+		return nil
+	}
 
 	// Если нет соединений, то сервер закроется сразу, иначе будет ждать закрытия или истечения времени
-	_ = srv.Shutdown(ctx)
+	if err := router.server.Shutdown(ctx); err != nil {
+		// не дождались - закрываем принудительно
+		_ = router.server.Close()
 
-	// Если нужно ждать завершения других сервисов, то надо запустить srv.Shutdown в горутине
-	// и остановиться на <-ctx.Done()
-
-	log.Println("shutting down")
+		return errors.Wrap(err, "shutdown error")
+	}
 
 	return nil
 }
 
-// Ответ с ошибкой
-func (router *HTTPRouter) respondError(w http.ResponseWriter, r *http.Request, code int, err error) {
-	router.respond(w, r, code, map[string]string{"error": err.Error()})
-}
-
 // Ответ на запрос без сжатия
-func (router *HTTPRouter) respond(w http.ResponseWriter, _ *http.Request, code int, data interface{}) {
-	if code > 0 {
+func (router *HTTPRouter) respond(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
+	if code <= 0 {
+		code = http.StatusOK
+	}
+
+	if data == nil {
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(code)
+		_, _ = w.Write([]byte("{}"))
+
+		return
 	}
-	if data != nil {
-		switch d := data.(type) {
-		case string:
-			_, _ = w.Write([]byte(d))
-		default:
-			if err := json.NewEncoder(w).Encode(data); err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				_, _ = w.Write([]byte(fmt.Sprintf(`{"error": "%v"}`, err)))
-
-				return
-			}
-
-			w.Header().Set("Content-Type", "application/json")
+
+	switch d := data.(type) {
+	case string:
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(d))
+	default:
+		// сериализуем заранее, чтобы при ошибке можно было отдать другой код ответа
+		body, err := json.Marshal(data)
+		if err != nil {
+			router.respondError(w, r, http.StatusInternalServerError, err)
+
+			return
 		}
-	} else {
-		_, _ = w.Write([]byte("{}"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = w.Write(body)
 	}
 }
 
@@ -195,6 +234,8 @@ func (router *HTTPRouter) respondCompressed(w http.ResponseWriter, r *http.Reque
 
 		if errJSON != nil {
 			router.respondError(w, r, http.StatusInternalServerError, errJSON)
+
+			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,128 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+)
+
+// Запрос на сохранение или изменение поиска. Если shared не задан, новый поиск личный, а у изменяемого признак не меняется
+type searchRequest struct {
+	Name   string `json:"name"`
+	Query  string `json:"query"`
+	Shared *bool  `json:"shared"`
+}
+
+// Свои и общие сохраненные поиски
+func (router *HTTPRouter) getSearches() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		searches, err := router.domain.SearchUsecase.List(r.Context(), currentUser(r))
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, searches)
+	}
+}
+
+// Сохранить поиск. query - строка запроса страницы поиска (from, to, tz)
+func (router *HTTPRouter) createSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &searchRequest{
+			Name:   "",
+			Query:  "",
+			Shared: nil,
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		search := &model.SavedSearch{
+			ID:        0,
+			UserID:    0,
+			Name:      req.Name,
+			Query:     req.Query,
+			Shared:    req.Shared != nil && *req.Shared,
+			CreatedAt: time.Time{},
+		}
+
+		if err := router.domain.SearchUsecase.Create(r.Context(), currentUser(r), search); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).Tf("Поиск %s сохранен", search.Name))
+		router.respond(w, r, http.StatusCreated, search)
+	}
+}
+
+// Изменить поиск. Пустые name и query и отсутствующий shared не меняются
+func (router *HTTPRouter) updateSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		searchID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		req := &searchRequest{
+			Name:   "",
+			Query:  "",
+			Shared: nil,
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		search := &model.SavedSearch{
+			ID:        searchID,
+			UserID:    0,
+			Name:      req.Name,
+			Query:     req.Query,
+			Shared:    false,
+			CreatedAt: time.Time{},
+		}
+
+		if err := router.domain.SearchUsecase.Update(r.Context(), currentUser(r), search, req.Shared); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Поиск изменен"))
+		router.respond(w, r, http.StatusOK, search)
+	}
+}
+
+// Удалить поиск
+func (router *HTTPRouter) removeSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		searchID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := router.domain.SearchUsecase.Remove(r.Context(), currentUser(r), searchID); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Поиск удален"))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
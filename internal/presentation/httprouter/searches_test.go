@@ -0,0 +1,94 @@
+package httprouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_SavedSearches(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	owner := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), owner))
+
+	other := model.TestUser(t)
+	other.Login = "other@example.com"
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), other))
+
+	ownerCookie := srv.SessionCookie(t, owner.ID)
+	otherCookie := srv.SessionCookie(t, other.ID)
+
+	request := func(method, path, body string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.AddCookie(cookie)
+
+		return srv.Serve(req)
+	}
+
+	list := func(cookie *http.Cookie) []model.SavedSearch {
+		rec := request(http.MethodGet, "/api/private/searches", "", cookie)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var searches []model.SavedSearch
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&searches))
+
+		return searches
+	}
+
+	// запрос без интервала дат не сохраняется
+	rec := request(http.MethodPost, "/api/private/searches", `{"name": "errors", "query": "tz=UTC"}`, ownerCookie)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// лишние параметры отбрасываются
+	rec = request(http.MethodPost, "/api/private/searches",
+		`{"name": " errors ", "query": "?from=now-1h&to=now&cursor=abc"}`, ownerCookie)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var search model.SavedSearch
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&search))
+	assert.Equal(t, "errors", search.Name)
+	assert.Equal(t, "from=now-1h&to=now", search.Query)
+
+	// личный поиск виден только владельцу
+	assert.Len(t, list(ownerCookie), 1)
+	assert.Empty(t, list(otherCookie))
+
+	path := fmt.Sprintf("/api/private/searches/%d", search.ID)
+	rec = request(http.MethodPut, path, `{"shared": true}`, ownerCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// общий поиск видят все, но менять может только владелец
+	require.Len(t, list(otherCookie), 1)
+	assert.Equal(t, "errors", list(otherCookie)[0].Name)
+	assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, path, "", otherCookie).Code)
+
+	// переименование без shared не делает поиск личным
+	rec = request(http.MethodPut, path, `{"name": "all errors"}`, ownerCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, list(otherCookie), 1)
+	assert.Equal(t, "all errors", list(otherCookie)[0].Name)
+	assert.True(t, list(otherCookie)[0].Shared)
+
+	// поиск в навбаре, относительный интервал и ссылка на поиск с часовым поясом
+	insertTestRecords(t, srv.LogRepo, time.Now().Add(-30*time.Minute), 3)
+
+	rec = request(http.MethodGet, "/search?from=now-1h&to=now", "", otherCookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `id="savedSearches"`)
+	assert.Contains(t, rec.Body.String(), `/search?from=now-1h&amp;to=now`)
+	assert.Contains(t, rec.Body.String(), `/search?from=now-1h&amp;to=now&amp;tz=UTC`)
+	assert.Equal(t, 3, strings.Count(rec.Body.String(), "record "))
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, path, "", ownerCookie).Code)
+	assert.Empty(t, list(otherCookie))
+}
@@ -0,0 +1,128 @@
+package httprouter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
+)
+
+var errBadClientCA = errors.New("no certificates found in client CA file")
+
+// Сертификаты TLS с возможностью перечитать их без перезапуска сервера
+type tlsCertificates struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+func newTLSCertificates(certFile, keyFile, clientCAFile string) (*tlsCertificates, error) {
+	c := &tlsCertificates{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		mu:           sync.RWMutex{},
+		cert:         nil,
+		clientCAs:    nil,
+	}
+
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Перечитать сертификаты с диска. При ошибке остаются ранее загруженные
+func (c *tlsCertificates) reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "load key pair error")
+	}
+
+	var clientCAs *x509.CertPool
+
+	if c.clientCAFile != "" {
+		pem, err := os.ReadFile(c.clientCAFile)
+		if err != nil {
+			return errors.Wrap(err, "read client CA error")
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errBadClientCA
+		}
+	}
+
+	c.mu.Lock()
+	c.cert = &cert
+	c.clientCAs = clientCAs
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Конфигурация TLS. Сертификаты берутся при каждом новом соединении, поэтому reload
+// применяется к новым соединениям без перезапуска сервера
+func (c *tlsCertificates) config(clientCertRequired bool) *tls.Config {
+	clientAuth := tls.NoClientCert
+	if c.clientCAFile != "" {
+		if clientCertRequired {
+			clientAuth = tls.RequireAndVerifyClientCert
+		} else {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return &tls.Config{ //nolint:exhaustivestruct,exhaustruct
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+
+			return &tls.Config{ //nolint:exhaustivestruct,exhaustruct
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*c.cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    c.clientCAs,
+			}, nil
+		},
+	}
+}
+
+// ReloadTLS Перечитать сертификаты TLS (вызывается по SIGHUP). Если TLS не используется, ничего не делает
+func (router *HTTPRouter) ReloadTLS() error {
+	if router.tlsCerts == nil {
+		return nil
+	}
+
+	return router.tlsCerts.reload()
+}
+
+// Аутентификация по клиентскому сертификату (mTLS). CN проверенного сертификата
+// сопоставляется с логином пользователя через TLS_CLIENT_CERT_USERS.
+// Возвращает nil, если сертификата нет или он не сопоставлен с пользователем
+func (router *HTTPRouter) certificateUser(r *http.Request) (*model.User, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+
+	login, ok := router.config.TLSClientCertUsers[cn]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	user, err := router.domain.UserUsecase.FindByLogin(r.Context(), login)
+
+	return user, errors.Wrap(err, "certificate user error")
+}
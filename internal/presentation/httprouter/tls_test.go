@@ -0,0 +1,187 @@
+package httprouter
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/n-r-w/log-server/internal/app/alerting"
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/domain"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/domain/usecase"
+	"github.com/n-r-w/log-server/internal/repository/testrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тестовый сертификат, подписанный parent (или самоподписанный, если parent == nil)
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+	kpem []byte
+}
+
+func newTestCert(t *testing.T, cn string, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tpl := &x509.Certificate{ //nolint:exhaustivestruct,exhaustruct
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn}, //nolint:exhaustivestruct,exhaustruct
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	parentCert, parentKey := tpl, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),       //nolint:exhaustivestruct,exhaustruct
+		kpem: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), //nolint:exhaustivestruct,exhaustruct
+	}
+}
+
+func (c *testCert) write(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, c.pem, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, c.kpem, 0o600))
+
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	cert, err := tls.X509KeyPair(c.pem, c.kpem)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func TestHTTPRouter_MutualTLS(t *testing.T) {
+	cfg := config.Default()
+
+	dbo, err := testrepo.CreateTestlDBO()
+	require.NoError(t, err)
+
+	userRepo := testrepo.NewUser(dbo, cfg)
+	logCase := usecase.NewLogCase(testrepo.NewLog(dbo), cfg)
+	sessionRepo := testrepo.NewSession(dbo)
+	dom := domain.NewDomain(logCase, usecase.NewUserCase(userRepo, sessionRepo, cfg), usecase.NewHealthCase(dbo, logCase),
+		usecase.NewTokenCase(testrepo.NewToken(dbo), userRepo, cfg),
+		usecase.NewSessionCase(sessionRepo, userRepo, cfg),
+		usecase.NewSearchCase(testrepo.NewSearch(dbo)),
+		usecase.NewAlertCase(testrepo.NewAlert(dbo), alerting.LogNotifier{}),
+		usecase.NewNotifyCase(nil, cfg))
+	router := NewRouter(dom, sessions.NewCookieStore([]byte(cfg.SessionEncriptionKey)), cfg)
+
+	u := model.TestUser(t)
+	require.NoError(t, userRepo.Insert(context.Background(), u))
+	cfg.TLSClientCertUsers = map[string]string{"billing": u.Login}
+
+	// УЦ, сертификат сервера и клиента
+	dir := t.TempDir()
+	ca := newTestCert(t, "test ca", true, nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "server", false, ca).write(t, dir, "server")
+
+	router.tlsCerts, err = newTLSCertificates(certFile, keyFile, caFile)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.TLS = router.tlsCerts.config(false)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{ //nolint:exhaustivestruct,exhaustruct
+			Transport: &http.Transport{ //nolint:exhaustivestruct,exhaustruct
+				TLSClientConfig: &tls.Config{ //nolint:exhaustivestruct,exhaustruct,gosec
+					RootCAs:      roots,
+					Certificates: certs,
+				},
+			},
+		}
+	}
+
+	whoami := func(client *http.Client) (int, *model.User) {
+		resp, err := client.Get(srv.URL + "/api/private/whoami") //nolint:noctx
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var user model.User
+		_ = json.NewDecoder(resp.Body).Decode(&user)
+
+		return resp.StatusCode, &user
+	}
+
+	// клиент с сертификатом, сопоставленным с пользователем
+	code, user := whoami(newClient(newTestCert(t, "billing", false, ca).tlsCertificate(t)))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, u.Login, user.Login)
+
+	// сертификат не сопоставлен с пользователем
+	code, _ = whoami(newClient(newTestCert(t, "unknown", false, ca).tlsCertificate(t)))
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// без сертификата
+	code, _ = whoami(newClient())
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// перевыпуск сертификата сервера и перезагрузка без перезапуска
+	newServerCert := newTestCert(t, "server2", false, ca)
+	newServerCert.write(t, dir, "server")
+	require.NoError(t, router.ReloadTLS())
+
+	conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), &tls.Config{RootCAs: roots}) //nolint:exhaustivestruct,exhaustruct,gosec,lll
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, "server2", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+}
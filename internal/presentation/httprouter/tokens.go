@@ -0,0 +1,95 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+)
+
+// Токены пользователя. Свои может получить любой пользователь, чужие - только админ
+func (router *HTTPRouter) getUserTokens() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		tokens, err := router.domain.TokenUsecase.List(r.Context(), currentUser(r), userID)
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.respond(w, r, http.StatusOK, tokens)
+	}
+}
+
+// Создать токен. Сам токен возвращается только в ответе на этот запрос
+func (router *HTTPRouter) createToken() http.HandlerFunc {
+	type request struct {
+		Name string `json:"name"`
+	}
+
+	type response struct {
+		Token string          `json:"token"`
+		Info  *model.APIToken `json:"info"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		req := &request{
+			Name: "",
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		secret, token, err := router.domain.TokenUsecase.Create(r.Context(), currentUser(r), userID, req.Name)
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		// сам токен в сессию не попадает: куки подписаны, но не зашифрованы
+		router.addFlash(w, r, flashInfo, requestPrinter(r).Tf("Токен %s создан", token.Name))
+		router.respond(w, r, http.StatusCreated, &response{
+			Token: secret,
+			Info:  token,
+		})
+	}
+}
+
+// Отозвать токен
+func (router *HTTPRouter) revokeToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenID, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := router.domain.TokenUsecase.Revoke(r.Context(), currentUser(r), tokenID); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Токен отозван"))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
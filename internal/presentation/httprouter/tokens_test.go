@@ -0,0 +1,89 @@
+package httprouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_APIToken(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	// пользователь создает токен себе
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/private/users/%d/tokens", u.ID),
+		strings.NewReader(`{"name": "ci"}`))
+	req.AddCookie(srv.SessionCookie(t, u.ID))
+	rec := srv.Serve(req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created struct {
+		Token string         `json:"token"`
+		Info  model.APIToken `json:"info"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	assert.NotEmpty(t, created.Token)
+	assert.Equal(t, u.ID, created.Info.UserID)
+
+	whoami := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/private/whoami", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		return srv.Serve(req).Code
+	}
+
+	assert.Equal(t, http.StatusOK, whoami(created.Token))
+	assert.Equal(t, http.StatusUnauthorized, whoami(created.Token+"x"))
+
+	// время использования отмечается не на каждый запрос
+	token, err := srv.TokenRepo.FindByID(context.Background(), created.Info.ID)
+	require.NoError(t, err)
+	require.NotNil(t, token.LastUsedAt)
+
+	usedAt := *token.LastUsedAt
+	assert.Equal(t, http.StatusOK, whoami(created.Token))
+
+	token, err = srv.TokenRepo.FindByID(context.Background(), created.Info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, usedAt, *token.LastUsedAt)
+
+	// чужие токены пользователю не видны
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/private/tokens/%d", created.Info.ID), nil)
+	req.AddCookie(srv.SessionCookie(t, u.ID+1))
+	assert.NotEqual(t, http.StatusOK, srv.Serve(req).Code)
+
+	// отзыв токена
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/private/tokens/%d", created.Info.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+created.Token)
+	assert.Equal(t, http.StatusOK, srv.Serve(req).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, whoami(created.Token))
+}
+
+func TestHTTPRouter_ReaderRole(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	u.Role = model.RoleReader
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/private/add-log",
+		strings.NewReader(`[{"logTime": "2020-04-23T18:25:43.511Z", "level": 4, "message1": "error"}]`))
+	req.AddCookie(srv.SessionCookie(t, u.ID))
+	assert.Equal(t, http.StatusForbidden, srv.Serve(req).Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/private/records",
+		strings.NewReader(`{"timeFrom": "2020-01-01T00:00:00Z", "timeTo": "2021-01-01T00:00:00Z"}`))
+	req.AddCookie(srv.SessionCookie(t, u.ID))
+	assert.Equal(t, http.StatusOK, srv.Serve(req).Code)
+}
@@ -0,0 +1,92 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/pkg/errors"
+)
+
+// ID из параметра маршрута
+func pathID(r *http.Request, name string) (uint64, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 64)
+
+	return id, errors.Wrapf(err, "bad %s", name)
+}
+
+// Изменить логин, имя и роль пользователя (только админ). Незаполненные поля не меняются
+func (router *HTTPRouter) updateUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !currentUser(r).IsAdmin() {
+			router.respondError(w, r, http.StatusForbidden, errNotAdmin)
+
+			return
+		}
+
+		id, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		u := &model.User{
+			ID:                0,
+			Login:             "",
+			Name:              "",
+			Role:              "",
+			Password:          "",
+			EncryptedPassword: "",
+			Timezone:          "",
+		}
+		// парсим входящий json
+		if err := json.NewDecoder(r.Body).Decode(u); err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		// пароль меняется через change-password
+		u.ID = id
+		u.Password = ""
+
+		if err := router.domain.UserUsecase.Update(r.Context(), u); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).T("Пользователь изменен"))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+// Удалить пользователя вместе с его токенами (только админ)
+func (router *HTTPRouter) removeUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !currentUser(r).IsAdmin() {
+			router.respondError(w, r, http.StatusForbidden, errNotAdmin)
+
+			return
+		}
+
+		id, err := pathID(r, "id")
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := router.domain.UserUsecase.Remove(r.Context(), id); err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		router.addFlash(w, r, flashInfo, requestPrinter(r).Tf("Пользователь %d удален", id))
+		router.respond(w, r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,42 @@
+package httprouter_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_UpdateRemoveUser(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	request := func(method string, userID uint64, body string) int {
+		req := httptest.NewRequest(method, fmt.Sprintf("/api/private/users/%d", u.ID), strings.NewReader(body))
+		req.AddCookie(srv.SessionCookie(t, userID))
+
+		return srv.Serve(req).Code
+	}
+
+	// менять пользователей может только админ
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPut, u.ID, `{"role": "admin"}`))
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, srv.Config.SuperAdminID, `{"role": "root"}`))
+	assert.Equal(t, http.StatusOK, request(http.MethodPut, srv.Config.SuperAdminID, `{"name": "new name", "role": "reader"}`))
+
+	changed, err := srv.UserRepo.FindByID(context.Background(), u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new name", changed.Name)
+	assert.Equal(t, u.Login, changed.Login)
+	assert.Equal(t, model.RoleReader, changed.Role)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, srv.Config.SuperAdminID, ""))
+	assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, srv.Config.SuperAdminID, ""))
+}
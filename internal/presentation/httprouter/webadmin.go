@@ -1,15 +1,170 @@
 package httprouter
 
 import (
+	"fmt"
 	"net/http"
 
 	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+)
+
+const (
+	// JS для управления пользователями. Строковые параметры и переведенные сообщения берутся
+	// из data- атрибутов, чтобы не подставлять логины и названия в код
+	adminJS = `
+function addUser()
+{
+	apiREST("POST", "/api/private/add-user", {
+		login: document.getElementById("newLogin").value,
+		name: document.getElementById("newName").value,
+		password: document.getElementById("newPassword").value,
+		role: document.getElementById("newRole").value
+	})
+}
+
+function updateUser(id)
+{
+	apiREST("PUT", "/api/private/users/" + id, {
+		login: document.getElementById("login-" + id).value,
+		name: document.getElementById("name-" + id).value,
+		role: document.getElementById("role-" + id).value
+	})
+}
+
+function resetPassword(button)
+{
+	var password = prompt(button.dataset.prompt)
+	if (password === null) {
+		return
+	}
+
+	apiREST("PUT", "/api/private/change-password", {login: button.dataset.login, password: password})
+}
+
+function removeUser(id, button)
+{
+	if (!confirm(button.dataset.confirm)) {
+		return
+	}
+
+	apiREST("DELETE", "/api/private/users/" + id, null)
+}`
 )
 
 func (router *HTTPRouter) webAdmin(w http.ResponseWriter, r *http.Request) g.Node {
-	if u, _, _ := router.isAuthenticated(r); u == nil {
-		return router.renderNotLoginGeneral()
+	user, _, _ := router.isAuthenticated(r)
+	tr := userPrinter(r, user)
+
+	if user == nil {
+		return router.renderNotLoginGeneral(tr)
+	}
+
+	if !user.IsAdmin() {
+		return Div(Class("text-white"), g.Text(tr.T("Раздел доступен только администраторам")))
+	}
+
+	users, err := router.domain.UserUsecase.GetUsers(r.Context())
+	if err != nil {
+		return Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err)))
+	}
+
+	var rows []g.Node
+	for i := range *users {
+		u := &(*users)[i]
+
+		tokens, err := router.domain.TokenUsecase.List(r.Context(), user, u.ID)
+		if err != nil {
+			return Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err)))
+		}
+
+		rows = append(rows, renderUserRow(tr, u, *tokens))
+	}
+
+	rules, err := router.domain.AlertUsecase.List(r.Context(), user)
+	if err != nil {
+		return Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err)))
+	}
+
+	statuses, err := router.domain.AlertUsecase.Status(user)
+	if err != nil {
+		return Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err)))
+	}
+
+	channels, err := router.domain.NotifyUsecase.Channels(user)
+	if err != nil {
+		return Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err)))
+	}
+
+	deliveries, err := router.domain.NotifyUsecase.Deliveries(user)
+	if err != nil {
+		return Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err)))
+	}
+
+	headers := []string{"Логин", "Имя", "Роль", "Токены", ""}
+
+	table := Table(tableClass, colorStyleAttr,
+		THead(tableHeaderClass, tableHeaderColorStyleAttr,
+			Tr(g.Group(g.Map(len(headers), func(i int) g.Node {
+				return Th(Class("px-6 py-3"), tableHeaderColorStyleAttr, g.Text(tr.T(headers[i])))
+			})))),
+		TBody(tableBodyClass, tableColorStyleAttr, g.Group(rows)))
+
+	return Div(
+		renderAddUserForm(tr),
+		Div(tableDivClass, colorStyleAttr, table),
+		Script(g.Raw(adminJS)),
+		Script(g.Raw(tokensJS)),
+		renderAlertRules(tr, *rules, statuses),
+		renderNotifications(tr, channels, deliveries))
+}
+
+// Форма добавления пользователя
+func renderAddUserForm(tr *i18n.Printer) g.Node {
+	return FormEl(Class("flex flex-wrap items-center space-x-0"),
+		Div(textClassRowSameLine, g.Text(tr.T("Логин"))),
+		Div(Input(buttonClassRowSameLine, ID("newLogin"), Type("text"))),
+		Div(textClassRowSameLine, g.Text(tr.T("Имя"))),
+		Div(Input(buttonClassRowSameLine, ID("newName"), Type("text"))),
+		Div(textClassRowSameLine, g.Text(tr.T("Пароль"))),
+		Div(Input(buttonClassRowSameLine, ID("newPassword"), Type("password"))),
+		Div(renderRoleSelect("newRole", model.RoleUser)),
+		Div(Input(buttonClassRowSameLine,
+			ID("addUser"), Type("button"), Value(tr.T("Добавить")), g.Attr("onclick", "addUser()"))),
+	)
+}
+
+// Выбор роли
+func renderRoleSelect(id string, current model.Role) g.Node {
+	return Select(buttonClassRowSameLine, ID(id),
+		g.Group(g.Map(len(model.Roles), func(i int) g.Node {
+			role := model.Roles[i]
+
+			return Option(Value(string(role)), g.If(role == current, Selected()), g.Text(string(role)))
+		})))
+}
+
+// Строка таблицы пользователей
+func renderUserRow(tr *i18n.Printer, u *model.User, tokens []model.APIToken) g.Node {
+	id := fmt.Sprintf("%d", u.ID)
+	cell := func(children ...g.Node) g.Node {
+		return Td(Class(columnClass), tableColorStyleAttr, g.Group(children))
 	}
 
-	return router.renderNotImplemeted()
+	return Tr(Class(`border-b bg-gray-800 border-gray-700`), tableColorStyleAttr,
+		cell(Input(buttonClassRowSameLine, ID("login-"+id), Type("text"), Value(u.Login))),
+		cell(Input(buttonClassRowSameLine, ID("name-"+id), Type("text"), Value(u.Name))),
+		cell(renderRoleSelect("role-"+id, u.Role)),
+		cell(renderTokens(tr, u.ID, tokens)),
+		cell(
+			Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Сохранить")),
+				g.Attr("onclick", fmt.Sprintf("updateUser(%d)", u.ID))),
+			Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Сменить пароль")),
+				g.Attr("data-login", u.Login), g.Attr("data-prompt", tr.Tf("Новый пароль для %s", u.Login)),
+				g.Attr("onclick", "resetPassword(this)")),
+			Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Удалить")),
+				g.Attr("data-confirm", tr.Tf("Удалить пользователя %s?", u.Login)),
+				g.Attr("onclick", fmt.Sprintf("removeUser(%d, this)", u.ID)))),
+	)
 }
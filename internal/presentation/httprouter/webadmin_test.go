@@ -0,0 +1,73 @@
+package httprouter_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var csrfMetaRegexp = regexp.MustCompile(`name="csrf-token" content="([^"]+)"`)
+
+func TestHTTPRouter_WebAdmin(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	u := model.TestUser(t)
+	require.NoError(t, srv.UserRepo.Insert(context.Background(), u))
+
+	// обычный пользователь админку не видит
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.AddCookie(srv.SessionCookie(t, u.ID))
+	rec := srv.Serve(req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "только администраторам")
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.AddCookie(srv.SessionCookie(t, srv.Config.SuperAdminID))
+	rec = srv.Serve(req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), u.Login)
+
+	match := csrfMetaRegexp.FindStringSubmatch(rec.Body.String())
+	require.Len(t, match, 2)
+
+	// сессия с CSRF токеном, выданная при отрисовке страницы
+	cookies := rec.Result().Cookies() //nolint:bodyclose
+	require.NotEmpty(t, cookies)
+
+	addUser := func(login, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/private/add-user",
+			strings.NewReader(`{"login": "`+login+`", "name": "web", "password": "1234"}`))
+		req.Header.Set("Origin", "http://localhost:8080")
+		if token != "" {
+			req.Header.Set(httprouter.CSRFHeaderName, token)
+		}
+
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+
+		return srv.Serve(req)
+	}
+
+	assert.Equal(t, http.StatusForbidden, addUser("web1", "").Code)
+	assert.Equal(t, http.StatusForbidden, addUser("web1", "bad").Code)
+
+	rec = addUser("web1", match[1])
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	// результат показывается на следующей странице
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	for _, c := range rec.Result().Cookies() { //nolint:bodyclose
+		req.AddCookie(c)
+	}
+
+	assert.Contains(t, srv.Serve(req).Body.String(), "Пользователь web1 добавлен")
+}
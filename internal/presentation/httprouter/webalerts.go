@@ -0,0 +1,235 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+)
+
+// JS для управления правилами оповещений. Правило для изменения берется из data-rule строки таблицы
+const alertsJS = `
+function addAlertRule()
+{
+	apiREST("POST", "/api/private/alerts/rules", {
+		name: document.getElementById("alertName").value,
+		minLevel: parseInt(document.getElementById("alertLevel").value, 10),
+		pattern: document.getElementById("alertPattern").value,
+		threshold: parseInt(document.getElementById("alertThreshold").value, 10),
+		windowSec: parseInt(document.getElementById("alertWindow").value, 10) * 60
+	})
+}
+
+function setAlertRuleEnabled(checkbox)
+{
+	var rule = JSON.parse(checkbox.dataset.rule)
+	rule.enabled = checkbox.checked
+	apiREST("PUT", "/api/private/alerts/rules/" + rule.id, rule)
+}
+
+function silenceAlertRule(id, button)
+{
+	var minutes = prompt(button.dataset.prompt, "60")
+	if (minutes === null) {
+		return
+	}
+
+	apiREST("POST", "/api/private/alerts/rules/" + id + "/silence", {minutes: parseInt(minutes, 10) || 0})
+}
+
+function unsilenceAlertRule(id)
+{
+	apiREST("POST", "/api/private/alerts/rules/" + id + "/silence", {minutes: 0})
+}
+
+function removeAlertRule(id, button)
+{
+	if (confirm(button.dataset.confirm)) {
+		apiREST("DELETE", "/api/private/alerts/rules/" + id, null)
+	}
+}
+
+function testNotifyChannel(button)
+{
+	apiREST("POST", "/api/private/notifications/channels/" + encodeURIComponent(button.dataset.channel) + "/test", null)
+}`
+
+// Количество последних отправок уведомлений на странице администрирования
+const adminDeliveries = 20
+
+// Правила оповещений на странице администрирования
+func renderAlertRules(tr *i18n.Printer, rules []model.AlertRule, statuses []model.AlertStatus) g.Node {
+	statusByID := make(map[uint64]model.AlertStatus, len(statuses))
+	for _, s := range statuses {
+		statusByID[s.RuleID] = s
+	}
+
+	headers := []string{"Название", "Условие", "Состояние", "Включено", ""}
+
+	rows := g.Map(len(rules), func(i int) g.Node {
+		rule := rules[i]
+		cell := func(children ...g.Node) g.Node {
+			return Td(Class(columnClass), tableColorStyleAttr, g.Group(children))
+		}
+
+		data, _ := json.Marshal(rule)
+
+		return Tr(Class(`border-b bg-gray-800 border-gray-700`), tableColorStyleAttr,
+			cell(g.Text(rule.Name)),
+			cell(g.Text(alertCondition(tr, &rule))),
+			cell(renderAlertState(tr, &rule, statusByID[rule.ID])),
+			cell(Input(Type("checkbox"), g.If(rule.Enabled, g.Attr("checked")),
+				g.Attr("data-rule", string(data)), g.Attr("onchange", "setAlertRuleEnabled(this)"))),
+			cell(
+				g.If(rule.Silenced(time.Now()),
+					Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Снять заглушку")),
+						g.Attr("onclick", fmt.Sprintf("unsilenceAlertRule(%d)", rule.ID)))),
+				g.If(!rule.Silenced(time.Now()),
+					Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Заглушить")),
+						g.Attr("data-prompt", tr.T("Заглушить уведомления на сколько минут?")),
+						g.Attr("onclick", fmt.Sprintf("silenceAlertRule(%d, this)", rule.ID)))),
+				Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Удалить")),
+					g.Attr("data-confirm", tr.Tf("Удалить правило %s?", rule.Name)),
+					g.Attr("onclick", fmt.Sprintf("removeAlertRule(%d, this)", rule.ID)))))
+	})
+
+	return Div(
+		Div(sectionClass, g.Text(tr.T("Правила оповещений"))),
+		renderAddAlertRuleForm(tr),
+		Div(tableDivClass, colorStyleAttr,
+			Table(tableClass, colorStyleAttr,
+				THead(tableHeaderClass, tableHeaderColorStyleAttr,
+					Tr(g.Group(g.Map(len(headers), func(i int) g.Node {
+						return Th(Class("px-6 py-3"), tableHeaderColorStyleAttr, g.Text(tr.T(headers[i])))
+					})))),
+				TBody(tableBodyClass, tableColorStyleAttr, g.Group(rows)))),
+		Script(g.Raw(alertsJS)))
+}
+
+// Форма добавления правила
+func renderAddAlertRuleForm(tr *i18n.Printer) g.Node {
+	levels := []uint{0, model.LevelDebug, model.LevelInfo, model.LevelWarning, model.LevelError, model.LevelCritical}
+
+	return FormEl(Class("flex flex-wrap items-center space-x-0"),
+		Div(textClassRowSameLine, g.Text(tr.T("Название"))),
+		Div(Input(buttonClassRowSameLine, ID("alertName"), Type("text"))),
+		Div(textClassRowSameLine, g.Text(tr.T("Уровень от"))),
+		Div(Select(buttonClassRowSameLine, ID("alertLevel"),
+			g.Group(g.Map(len(levels), func(i int) g.Node {
+				return Option(Value(fmt.Sprintf("%d", levels[i])), g.If(levels[i] == model.LevelError, Selected()),
+					g.Text(fmt.Sprintf("%d", levels[i])))
+			})))),
+		Div(textClassRowSameLine, g.Text(tr.T("Шаблон"))),
+		Div(Input(buttonClassRowSameLine, ID("alertPattern"), Type("text"), Placeholder(tr.T("регулярное выражение")))),
+		Div(textClassRowSameLine, g.Text(tr.T("Больше"))),
+		Div(Input(buttonClassRowSameLine, ID("alertThreshold"), Type("number"), Value("10"), g.Attr("min", "0"))),
+		Div(textClassRowSameLine, g.Text(tr.T("записей за (мин.)"))),
+		Div(Input(buttonClassRowSameLine, ID("alertWindow"), Type("number"), Value("5"), g.Attr("min", "1"))),
+		Div(Input(buttonClassRowSameLine,
+			ID("addAlertRule"), Type("button"), Value(tr.T("Добавить")), g.Attr("onclick", "addAlertRule()"))),
+	)
+}
+
+// Описание условия правила
+func alertCondition(tr *i18n.Printer, rule *model.AlertRule) string {
+	condition := tr.Tf("больше %d записей за %d мин.", rule.Threshold, rule.WindowSec/60)
+	if rule.WindowSec%60 != 0 {
+		condition = tr.Tf("больше %d записей за %d сек.", rule.Threshold, rule.WindowSec)
+	}
+
+	if rule.MinLevel > 0 {
+		condition += ", " + tr.Tf("уровень от %d", rule.MinLevel)
+	}
+
+	if rule.Pattern != "" {
+		condition += ", /" + rule.Pattern + "/"
+	}
+
+	return condition
+}
+
+// Состояние правила
+func renderAlertState(tr *i18n.Printer, rule *model.AlertRule, status model.AlertStatus) g.Node {
+	var text string
+
+	switch {
+	case !rule.Enabled:
+		text = tr.T("Выключено")
+	case status.State == model.AlertFiring && status.Since != nil:
+		text = tr.Tf("Сработало %s", tr.Time(*status.Since))
+	default:
+		text = tr.Tf("Норма (%d)", status.Count)
+	}
+
+	if rule.Silenced(time.Now()) {
+		text += ", " + tr.Tf("заглушено до %s", tr.Time(*rule.SilencedUntil))
+	}
+
+	return Span(g.If(status.State == model.AlertFiring, Class("text-red-300")), g.Text(text))
+}
+
+// Каналы уведомлений и последние отправки на странице администрирования
+func renderNotifications(tr *i18n.Printer, channels []model.NotifyChannel, deliveries []model.NotifyDelivery) g.Node {
+	if len(deliveries) > adminDeliveries {
+		deliveries = deliveries[:adminDeliveries]
+	}
+
+	cell := func(children ...g.Node) g.Node {
+		return Td(Class(columnClass), tableColorStyleAttr, g.Group(children))
+	}
+
+	table := func(headers []string, rows []g.Node) g.Node {
+		return Div(tableDivClass, colorStyleAttr,
+			Table(tableClass, colorStyleAttr,
+				THead(tableHeaderClass, tableHeaderColorStyleAttr,
+					Tr(g.Group(g.Map(len(headers), func(i int) g.Node {
+						return Th(Class("px-6 py-3"), tableHeaderColorStyleAttr, g.Text(tr.T(headers[i])))
+					})))),
+				TBody(tableBodyClass, tableColorStyleAttr, g.Group(rows))))
+	}
+
+	channelRows := g.Map(len(channels), func(i int) g.Node {
+		return Tr(Class(`border-b bg-gray-800 border-gray-700`), tableColorStyleAttr,
+			cell(g.Text(channels[i].Name)),
+			cell(g.Text(channels[i].Type)),
+			cell(Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Отправить тест")),
+				g.Attr("data-channel", channels[i].Name), g.Attr("onclick", "testNotifyChannel(this)"))))
+	})
+
+	deliveryRows := g.Map(len(deliveries), func(i int) g.Node {
+		d := deliveries[i]
+
+		result := tr.Tf("Доставлено (попыток: %d)", d.Attempts)
+		if !d.Delivered {
+			result = tr.Tf("Ошибка (попыток: %d): %s", d.Attempts, d.Error)
+		}
+
+		rule := d.RuleName
+		if d.Test {
+			rule = tr.T("тест")
+		}
+
+		return Tr(Class(`border-b bg-gray-800 border-gray-700`), tableColorStyleAttr,
+			cell(g.Text(tr.Time(d.Time))),
+			cell(g.Text(d.Channel)),
+			cell(g.Text(rule)),
+			cell(g.Text(string(d.State))),
+			cell(Span(g.If(!d.Delivered, Class("text-red-300")), g.Text(result))))
+	})
+
+	if len(channels) == 0 {
+		return Div(
+			Div(sectionClass, g.Text(tr.T("Каналы уведомлений"))),
+			Div(textClassRowSameLine, g.Text(tr.T("Каналы уведомлений не настроены (NOTIFY_CHANNELS)"))))
+	}
+
+	return Div(
+		Div(sectionClass, g.Text(tr.T("Каналы уведомлений"))),
+		table([]string{"Название", "Тип", ""}, channelRows),
+		Div(sectionClass, g.Text(tr.T("Последние уведомления"))),
+		table([]string{"Время", "Канал", "Правило", "Состояние", "Результат"}, deliveryRows))
+}
@@ -0,0 +1,68 @@
+package httprouter
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/log-server/assets"
+	"github.com/pkg/errors"
+)
+
+// Префикс маршрута встроенных ресурсов веб интерфейса
+const assetsPrefix = "/assets/"
+
+var errAssetNotSeekable = errors.New("asset is not seekable")
+
+// Встроенные ресурсы веб интерфейса. Ссылки на страницах содержат хэш файла в параметре v,
+// поэтому такие ответы кэшируются браузером без ограничения времени. Остальные проверяются по ETag
+func (router *HTTPRouter) serveAssets() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), assetsPrefix)
+
+		etag, ok := assets.ETag(name)
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		f, err := assets.FS().Open(name)
+		if err != nil {
+			http.NotFound(w, r)
+
+			return
+		}
+		defer f.Close()
+
+		// файлы embed.FS поддерживают Seek
+		content, ok := f.(io.ReadSeeker)
+		if !ok {
+			router.respondError(w, r, http.StatusInternalServerError, errAssetNotSeekable)
+
+			return
+		}
+
+		if r.URL.Query().Get("v") == etag {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		w.Header().Set("ETag", `"`+etag+`"`)
+		// ServeContent сам отвечает 304 на If-None-Match и определяет Content-Type по расширению
+		http.ServeContent(w, r, name, time.Time{}, content)
+	}
+}
+
+// Адрес встроенной таблицы стилей. CDN не используется: без make assets сервер отвечает на такую ссылку 404
+func assetHref(a assets.Asset) string {
+	etag, ok := assets.ETag(a.Path)
+	if !ok {
+		return assetsPrefix + a.Path
+	}
+
+	return assetsPrefix + a.Path + "?v=" + etag
+}
@@ -0,0 +1,51 @@
+package httprouter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-r-w/log-server/assets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRouter_Assets(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	etag, ok := assets.ETag("read.me")
+	require.True(t, ok)
+
+	rec := srv.Serve(httptest.NewRequest(http.MethodGet, "/assets/read.me", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"`+etag+`"`, rec.Header().Get("ETag"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, rec.Body.String())
+
+	// ссылка с хэшем кэшируется без ограничения времени
+	rec = srv.Serve(httptest.NewRequest(http.MethodGet, "/assets/read.me?v="+etag, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "immutable")
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/read.me", nil)
+	req.Header.Set("If-None-Match", `"`+etag+`"`)
+	assert.Equal(t, http.StatusNotModified, srv.Serve(req).Code)
+
+	assert.Equal(t, http.StatusNotFound,
+		srv.Serve(httptest.NewRequest(http.MethodGet, "/assets/missing.css", nil)).Code)
+	assert.NotEqual(t, http.StatusOK,
+		srv.Serve(httptest.NewRequest(http.MethodGet, "/assets/../config/server.toml", nil)).Code)
+}
+
+func TestHTTPRouter_AssetsNoCDN(t *testing.T) {
+	srv := initAuthTestCase(t)
+
+	rec := srv.Serve(httptest.NewRequest(http.MethodGet, "/login", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// таблицы стилей только встроенные, даже если они не скачаны
+	for _, a := range assets.Styles {
+		assert.Contains(t, rec.Body.String(), `href="/assets/`+a.Path)
+		assert.NotContains(t, rec.Body.String(), a.URL)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	g "github.com/maragudk/gomponents"
 	. "github.com/maragudk/gomponents/html"
 	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
 )
 
 const (
@@ -57,6 +58,31 @@ function logoutREST()
 	
 	xhr.open("delete", "/api/auth/close", true);	
 	xhr.send();
+}`
+	// JS для личного кабинета
+	accountJS = `
+function changeOwnPassword()
+{
+	var password = document.getElementById("newPassword").value
+	if (password !== document.getElementById("repeatPassword").value) {
+		alert(document.getElementById("changePassword").dataset.mismatch)
+		return
+	}
+
+	apiREST("PUT", "/api/private/account/password", {
+		currentPassword: document.getElementById("currentPassword").value,
+		password: password
+	})
+}
+
+function setTimezone()
+{
+	apiREST("PUT", "/api/private/account/timezone", {timezone: document.getElementById("timezone").value})
+}
+
+function revokeSession(id)
+{
+	apiREST("DELETE", "/api/private/account/sessions/" + id, null)
 }`
 )
 
@@ -68,6 +94,7 @@ var buttonClassRowSameLine = Class(`calender-black flex-wrap items-center py-2.5
 	rounded-lg border focus:outline-none bg-gray-800 text-gray-100 border-gray-600 hover:text-white hover:bg-gray-700`)
 var textClassRowSameLine = Class(`flex-wrap items-center py-2.5 px-0 ml-0 mr-0 mb-2 text-sm font-medium 
 	focus:outline-none text-gray-100`)
+var sectionClass = Class(`py-2.5 mt-3 text-base font-medium text-gray-100`)
 
 func (router *HTTPRouter) webLogin(w http.ResponseWriter, r *http.Request) g.Node {
 	user, httpCode, err := router.isAuthenticated(r)
@@ -79,35 +106,134 @@ func (router *HTTPRouter) webLogin(w http.ResponseWriter, r *http.Request) g.Nod
 }
 
 func (router *HTTPRouter) renderLoginNo(w http.ResponseWriter, r *http.Request, httpCode int, err error) g.Node {
+	tr := requestPrinter(r)
+
 	var message string
 	switch httpCode {
 	case http.StatusUnauthorized:
-		message = "Вход не выполнен"
+		message = tr.T("Вход не выполнен")
 	case http.StatusInternalServerError:
-		message = fmt.Sprintf("Ошибка сервера: %v", err)
+		message = tr.Tf("Ошибка сервера: %v", err)
 	case http.StatusNotFound:
-		message = "Пользователь не существует"
+		message = tr.T("Пользователь не существует")
 	default:
 		message = err.Error()
 	}
 
 	body := FormEl(
-		Div(g.Text("Логин")),
+		Div(g.Text(tr.T("Логин"))),
 		Div(Input(buttonClassRowNewLineSmall, ID("login"), Type("text"))),
-		Div(g.Text("Пароль")),
+		Div(g.Text(tr.T("Пароль"))),
 		Div(Input(buttonClassRowNewLineBig, ID("password"), Type("password"))),
 		Input(buttonClassRowNewLineSmall,
-			ID("loginButton"), Type("button"), Value("Войти"), g.Attr("onclick", "loginREST()")),
+			ID("loginButton"), Type("button"), Value(tr.T("Войти")), g.Attr("onclick", "loginREST()")),
 		Div(g.Text(message)),
 		Script(g.Raw(loginJS)))
 	return body
 }
 
 func (router *HTTPRouter) renderLoginOK(w http.ResponseWriter, r *http.Request, user *model.User) g.Node {
-	body := FormEl(
-		Div(Class("py-2.5 px-0 mr-2 mb-2 text-sm"), g.Text("Привет "), B(g.Text(user.Name)), g.Text("!")),
+	tr := userPrinter(r, user)
+
+	greeting := FormEl(
+		Div(Class("py-2.5 px-0 mr-2 mb-2 text-sm"), g.Text(tr.T("Привет")+" "), B(g.Text(user.Name)), g.Text("!")),
 		Input(buttonClassRowNewLineSmall,
-			ID("logoutButton"), Type("button"), Value("Выйти"), g.Attr("onclick", "logoutREST()")),
+			ID("logoutButton"), Type("button"), Value(tr.T("Выйти")), g.Attr("onclick", "logoutREST()")),
 		Script(g.Raw(logoutJS)))
-	return body
+
+	sessions, err := router.domain.SessionUsecase.List(r.Context(), user, router.requestSessionSecret(r))
+	if err != nil {
+		return Div(greeting, Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err))))
+	}
+
+	searches, err := router.domain.SearchUsecase.List(r.Context(), user)
+	if err != nil {
+		return Div(greeting, Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err))))
+	}
+
+	// пароль встроенного админа задается в конфигурации, токены ему не выдаются
+	if router.config.SuperAdmin().IsAdmin(user.ID) {
+		return Div(greeting, renderSessions(tr, *sessions), renderSearches(tr, user, *searches),
+			Script(g.Raw(accountJS)))
+	}
+
+	tokens, err := router.domain.TokenUsecase.List(r.Context(), user, user.ID)
+	if err != nil {
+		return Div(greeting, Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err))))
+	}
+
+	return Div(greeting,
+		renderChangePassword(tr),
+		renderTimezone(tr, user),
+		renderSessions(tr, *sessions),
+		renderSearches(tr, user, *searches),
+		Div(sectionClass, g.Text(tr.T("Токены API"))),
+		renderTokens(tr, user.ID, *tokens),
+		Script(g.Raw(accountJS)),
+		Script(g.Raw(tokensJS)))
+}
+
+// Форма смены своего пароля
+func renderChangePassword(tr *i18n.Printer) g.Node {
+	return Div(
+		Div(sectionClass, g.Text(tr.T("Смена пароля"))),
+		FormEl(Class("flex flex-wrap items-center space-x-0"),
+			Div(textClassRowSameLine, g.Text(tr.T("Текущий пароль"))),
+			Div(Input(buttonClassRowSameLine, ID("currentPassword"), Type("password"))),
+			Div(textClassRowSameLine, g.Text(tr.T("Новый пароль"))),
+			Div(Input(buttonClassRowSameLine, ID("newPassword"), Type("password"))),
+			Div(textClassRowSameLine, g.Text(tr.T("Повтор"))),
+			Div(Input(buttonClassRowSameLine, ID("repeatPassword"), Type("password"))),
+			Div(Input(buttonClassRowSameLine, ID("changePassword"), Type("button"), Value(tr.T("Сменить")),
+				g.Attr("data-mismatch", tr.T("Пароли не совпадают")),
+				g.Attr("onclick", "changeOwnPassword()")))))
+}
+
+// Выбор часового пояса пользователя
+func renderTimezone(tr *i18n.Printer, user *model.User) g.Node {
+	return Div(
+		Div(sectionClass, g.Text(tr.T("Часовой пояс"))),
+		FormEl(Class("flex flex-wrap items-center space-x-0"),
+			Div(Input(buttonClassRowSameLine, ID("timezone"), Type("text"), Value(user.Timezone),
+				Placeholder(tr.T("Часовой пояс браузера")))),
+			Div(Input(buttonClassRowSameLine, ID("setTimezone"), Type("button"), Value(tr.T("Сохранить")),
+				g.Attr("onclick", "setTimezone()"))),
+			Div(textClassRowSameLine, g.Text(tr.T("Например, Europe/Moscow. Пусто - часовой пояс браузера")))))
+}
+
+// Таблица сессий пользователя
+func renderSessions(tr *i18n.Printer, sessions []model.Session) g.Node {
+	headers := []string{"Вход", "Последнее обращение", "Адрес", "Браузер", ""}
+
+	rows := g.Map(len(sessions), func(i int) g.Node {
+		s := sessions[i]
+
+		var action g.Node
+		if s.Current {
+			action = g.Text(tr.T("Текущая"))
+		} else {
+			action = Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Завершить")),
+				g.Attr("onclick", fmt.Sprintf("revokeSession(%d)", s.ID)))
+		}
+
+		cells := []string{
+			tr.Time(s.CreatedAt), tr.Time(s.LastSeenAt), s.RemoteAddr, s.UserAgent,
+		}
+
+		return Tr(Class(`border-b bg-gray-800 border-gray-700`), tableColorStyleAttr,
+			g.Group(g.Map(len(cells), func(j int) g.Node {
+				return Td(Class(columnClass), tableColorStyleAttr, g.Text(cells[j]))
+			})),
+			Td(Class(columnClass), tableColorStyleAttr, action))
+	})
+
+	return Div(
+		Div(sectionClass, g.Text(tr.T("Сессии"))),
+		Div(tableDivClass, colorStyleAttr,
+			Table(tableClass, colorStyleAttr,
+				THead(tableHeaderClass, tableHeaderColorStyleAttr,
+					Tr(g.Group(g.Map(len(headers), func(i int) g.Node {
+						return Th(Class("px-6 py-3"), tableHeaderColorStyleAttr, g.Text(tr.T(headers[i])))
+					})))),
+				TBody(tableBodyClass, tableColorStyleAttr, g.Group(rows)))))
 }
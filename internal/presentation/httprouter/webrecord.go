@@ -0,0 +1,118 @@
+package httprouter
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+)
+
+// Путь к странице записи журнала (постоянная ссылка)
+func recordPath(id uint64) string {
+	return fmt.Sprintf("/record/%d", id)
+}
+
+// Страница записи журнала со всеми полями. С параметром context показываются соседние записи
+func (router *HTTPRouter) webRecord(w http.ResponseWriter, r *http.Request) g.Node {
+	u, _, _ := router.isAuthenticated(r)
+	tr := userPrinter(r, u)
+
+	if u == nil {
+		return router.renderNotLoginGeneral(tr)
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		return Div(Class("text-red-300"), g.Text(err.Error()))
+	}
+
+	showContext := r.URL.Query().Has("context")
+
+	count := 0
+	if showContext {
+		if count, err = router.recordContextCount(r, "context"); err != nil {
+			return Div(Class("text-red-300"), g.Text(err.Error()))
+		}
+	}
+
+	res, err := router.domain.LogUsecase.FindContext(r.Context(), id, count)
+	if err != nil {
+		if apperr.KindOf(err) == apperr.KindNotFound {
+			return Div(Class("text-white"), g.Text(tr.Tf("Запись %d не найдена", id)))
+		}
+
+		return Div(Class("text-red-300"), g.Text(tr.Tf("Ошибка сервера: %v", err)))
+	}
+
+	record := &res.Record
+
+	// журнал за час до и после записи
+	logTime := record.LogTime.In(tr.Location())
+	searchQuery := url.Values{}
+	searchQuery.Set("from", logTime.Add(-time.Hour).Format(requestTimeFormat))
+	searchQuery.Set("to", logTime.Add(time.Hour).Format(requestTimeFormat))
+
+	links := Div(Class("flex flex-wrap items-center space-x-0"),
+		A(buttonClassRowNewLineSmall, ID("permalink"), Href(recordPath(record.ID)), g.Text(tr.T("Постоянная ссылка"))),
+		g.If(!showContext,
+			A(buttonClassRowNewLineSmall, Href(fmt.Sprintf("%s?context=%d", recordPath(record.ID), defaultRecordContext)),
+				g.Text(tr.T("Показать соседние записи")))),
+		g.If(showContext,
+			A(buttonClassRowNewLineSmall, Href(recordPath(record.ID)), g.Text(tr.T("Скрыть соседние записи")))),
+		A(buttonClassRowNewLineSmall, Href("/search?"+searchQuery.Encode()), g.Text(tr.T("Журнал за этот период"))))
+
+	body := []g.Node{
+		Div(sectionClass, g.Text(tr.Tf("Запись журнала %d", record.ID))),
+		renderRecordFields(tr, record),
+		links,
+	}
+
+	if showContext {
+		rows := make([]model.LogRecord, 0, len(res.Newer)+len(res.Older)+1)
+		rows = append(rows, res.Newer...)
+		rows = append(rows, res.Record)
+		rows = append(rows, res.Older...)
+
+		body = append(body,
+			Div(sectionClass, g.Text(tr.T("Соседние записи"))),
+			Div(tableDivClass, colorStyleAttr,
+				Table(tableClass, colorStyleAttr,
+					THead(tableHeaderClass, tableHeaderColorStyleAttr, Tr(g.Group(renderLogTableHeaders(tr)))),
+					TBody(tableBodyClass, tableColorStyleAttr, g.Group(renderLogRows(tr, rows, record.ID))))))
+	}
+
+	return Div(body...)
+}
+
+// Таблица со всеми полями записи
+func renderRecordFields(tr *i18n.Printer, record *model.LogRecord) g.Node {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{name: "ID", value: fmt.Sprintf("%d", record.ID)},
+		{name: tr.T("Время"), value: tr.Time(record.LogTime)},
+		{name: tr.T("Время получения"), value: tr.Time(record.RealTime)},
+		{name: tr.T("Уровень"), value: fmt.Sprintf("%d", record.Level)},
+		{name: "Message1", value: record.Message1},
+		{name: "Message2", value: record.Message2},
+		{name: "Message3", value: record.Message3},
+	}
+
+	return Div(tableDivClass, colorStyleAttr,
+		Table(tableClass, colorStyleAttr,
+			TBody(tableBodyClass, tableColorStyleAttr,
+				g.Group(g.Map(len(fields), func(i int) g.Node {
+					return Tr(Class(`border-b bg-gray-800 border-gray-700`), tableColorStyleAttr,
+						Th(Class("px-6 py-3"), tableHeaderColorStyleAttr, StyleAttr("width:15%"), g.Text(fields[i].name)),
+						Td(Class("px-6 py-4 font-medium text-white"), tableColorStyleAttr,
+							Pre(StyleAttr("white-space: pre-wrap; word-break: break-all; margin: 0"),
+								g.Text(fields[i].value))))
+				})))))
+}
@@ -1,9 +1,14 @@
 package httprouter
 
 import (
+	"strings"
+
 	g "github.com/maragudk/gomponents"
 	c "github.com/maragudk/gomponents/components"
 	. "github.com/maragudk/gomponents/html"
+	"github.com/n-r-w/log-server/assets"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
 )
 
 // Информация о странице навбара
@@ -17,17 +22,38 @@ type pageInfoGroup struct {
 	pages []pageInfo
 }
 
-// Загружаемые css
-var (
-	stylesExternal = []string{
-		"https://unpkg.com/tailwindcss@2.1.2/dist/base.min.css",
-		"https://unpkg.com/tailwindcss@2.1.2/dist/components.min.css",
-		"https://unpkg.com/@tailwindcss/typography@0.4.0/dist/typography.min.css",
-		"https://unpkg.com/tailwindcss@2.1.2/dist/utilities.min.css",
-		"https://unpkg.com/flowbite@1.4.4/dist/flowbite.min.css",
-		"https://use.fontawesome.com/releases/v5.11.2/css/all.css",
-		"https://fonts.googleapis.com/css2?family=Inter:wght@300;400;500;600;700&display=swap",
+// Состояние сессии пользователя, необходимое для отрисовки страницы
+type pageState struct {
+	csrfToken string              // CSRF токен, передаваемый JS в хедере X-CSRF-Token
+	flashes   []flashMessage      // Сообщения о результатах предыдущих действий
+	searches  []model.SavedSearch // Сохраненные поиски для навбара
+}
+
+const (
+	// JS для запросов к REST API из веб интерфейса. После запроса страница перезагружается
+	// и показывает результат в виде flash сообщения
+	apiJS = `
+function apiREST(method, url, body, onSuccess)
+{
+	var xhr = new XMLHttpRequest()
+	xhr.onreadystatechange = function () {
+	    if (xhr.readyState === 4) {
+			if (xhr.status >= 200 && xhr.status < 300) {
+				if (onSuccess) {
+					onSuccess(JSON.parse(xhr.responseText))
+				}
+			} else {
+				console.log("request ERROR:", xhr.responseText)
+			}
+			location.reload();
+	    }
 	}
+
+	xhr.open(method, url, true);
+	xhr.setRequestHeader("Content-Type", 'application/json');
+	xhr.setRequestHeader("X-CSRF-Token", document.querySelector('meta[name="csrf-token"]').content);
+	xhr.send(body === null ? null : JSON.stringify(body));
+}`
 )
 
 var (
@@ -43,6 +69,10 @@ var (
 					path: "/search",
 					name: "Поиск",
 				},
+				{
+					path: "/record/",
+					name: "Запись журнала",
+				},
 			},
 		},
 		{
@@ -72,10 +102,19 @@ var (
 	}
 )
 
+// Соответствует ли путь странице. Путь страницы, заканчивающийся на "/", задает префикс (например, /record/{id})
+func (p *pageInfo) matches(path string) bool {
+	if strings.HasSuffix(p.path, "/") && p.path != "/" {
+		return strings.HasPrefix(path, p.path)
+	}
+
+	return p.path == path
+}
+
 // Содержится ли такой путь в группе страниц, относящихся к одному пункту навбара
 func (p *pageInfoGroup) contains(path string) bool {
 	for _, page := range p.pages {
-		if page.path == path {
+		if page.matches(path) {
 			return true
 		}
 	}
@@ -86,7 +125,7 @@ func (p *pageInfoGroup) contains(path string) bool {
 func getNavInfoByPath(path string) *pageInfo {
 	for _, info := range navInfo {
 		for _, p := range info.pages {
-			if p.path == path {
+			if p.matches(path) {
 				return &p
 			}
 		}
@@ -95,13 +134,19 @@ func getNavInfoByPath(path string) *pageInfo {
 }
 
 // Отрисовка страницы
-func page(title, path string, body g.Node) g.Node {
+func page(tr *i18n.Printer, title, path string, state pageState, body g.Node) g.Node {
 	// стили
 	var styles []g.Node
-	for _, style := range stylesExternal {
-		styles = append(styles, Link(Rel("stylesheet"), Href(style)))
+	for _, style := range assets.Styles {
+		styles = append(styles, Link(Rel("stylesheet"), Href(assetHref(style))))
 	}
 
+	// CSRF токен и функция запросов к API для JS
+	styles = append(styles,
+		Meta(Name("csrf-token"), Content(state.csrfToken)),
+		Script(g.Raw(apiJS)),
+		Script(g.Raw(langJS)))
+
 	// 	styles = append(styles, StyleAttr(`
 	// .picker__date-display {
 	//   background-color:blue;
@@ -116,27 +161,29 @@ func page(title, path string, body g.Node) g.Node {
 
 	return c.HTML5(c.HTML5Props{
 		Title:    title,
-		Language: "ru",
+		Language: string(tr.Lang()),
 		Head:     styles,
 		Body: []g.Node{colorStyleAttr, Class("bg-gray-700 mb-3 max-w-7xl mx-0 px-0 sm:px-0 lg:px-0"),
-			Div(navbar(path, navInfo),
-				Div(Class("prose-sm px-5 py-0 mt-0"), body)),
+			Div(navbar(tr, path, navInfo, state.searches),
+				Div(Class("prose-sm px-5 py-0 mt-0"), renderFlashes(state.flashes), body)),
 		},
 	})
 }
 
 // Рендер навбара
-func navbar(currentPath string, navInfo []pageInfoGroup) g.Node {
+func navbar(tr *i18n.Printer, currentPath string, navInfo []pageInfoGroup, searches []model.SavedSearch) g.Node {
 
 	return Nav(Class("bg-gray-700 mb-3 max-w-7xl mx-0 px-0 sm:px-0 lg:px-0"),
 		Div(Class("flex flex-wrap items-center space-x-0"),
 			B(H3(Class("text-4xl py-0 px-5 h-12 "), StyleAttr("color:#91B4FF"),
-				g.Text("Логгер"))),
+				g.Text(tr.T("Логгер")))),
 			Div(Class("mt-0"), g.Group(g.Map(len(navInfo),
 				func(i int) g.Node {
 					pGroup := navInfo[i]
-					return navbarLink(pGroup.pages[0].path, pGroup.pages[0].name, pGroup.contains(currentPath))
+					return navbarLink(pGroup.pages[0].path, tr.T(pGroup.pages[0].name), pGroup.contains(currentPath))
 				}))),
+			renderSearchSelect(tr, searches),
+			renderLangSelect(tr),
 		),
 	)
 }
@@ -153,15 +200,26 @@ func navbarLink(path, text string, active bool) g.Node {
 	)
 }
 
-func (router *HTTPRouter) renderNotLoginGeneral() g.Node {
+// Рендер flash сообщений
+func renderFlashes(flashes []flashMessage) g.Node {
+	return g.Group(g.Map(len(flashes), func(i int) g.Node {
+		return Div(c.Classes{
+			"py-2 text-sm":   true,
+			"text-red-300":   flashes[i].kind == flashError,
+			"text-green-300": flashes[i].kind != flashError,
+		}, g.Text(flashes[i].text))
+	}))
+}
+
+func (router *HTTPRouter) renderNotLoginGeneral(tr *i18n.Printer) g.Node {
 	body := Div(Class("text-gray-300 hover:text-white "),
 		A(StyleAttr(`border-bottom: 1px solid grey; padding-bottom: 5px;`),
-			Href("/login"), g.Text("Необходимо войти в личный кабинет")))
+			Href("/login"), g.Text(tr.T("Необходимо войти в личный кабинет"))))
 	return body
 }
 
-func (router *HTTPRouter) renderNotImplemeted() g.Node {
+func (router *HTTPRouter) renderNotImplemeted(tr *i18n.Printer) g.Node {
 	body := Div(Class("text-white"),
-		g.Text("Тут пока еще ничего нет"))
+		g.Text(tr.T("Тут пока еще ничего нет")))
 	return body
 }
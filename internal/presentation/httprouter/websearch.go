@@ -1,16 +1,19 @@
 package httprouter
 
 import (
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	g "github.com/maragudk/gomponents"
 	. "github.com/maragudk/gomponents/html"
-	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -21,7 +24,28 @@ function doSearch()
 	var dateFrom = document.getElementById("dateFrom").value
 	var dateTo = document.getElementById("dateTo").value
 
-	window.location.replace("/search?from=" + dateFrom.toString() + "&to=" + dateTo.toString())
+	var url = "/search?from=" + dateFrom.toString() + "&to=" + dateTo.toString()
+	var tz = new URLSearchParams(window.location.search).get("tz")
+	if (tz) {
+		url += "&tz=" + encodeURIComponent(tz)
+	}
+
+	window.location.replace(url)
+}
+
+function setRange(range)
+{
+	if (!range) {
+		return
+	}
+
+	var params = new URLSearchParams(window.location.search)
+	params.set("from", range)
+	params.set("to", "now")
+	params.delete("cursor")
+	params.delete("loaded")
+
+	window.location.replace("/search?" + params.toString())
 }
 
 window.addEventListener("load", function(){    
@@ -30,10 +54,67 @@ window.addEventListener("load", function(){
 
 	var dateFrom = urlParams.get("from")
 	var dateTo = urlParams.get("to")
+
+	// относительный интервал показывается в списке, а не в полях дат
+	if (dateFrom && dateFrom.indexOf("now") === 0) {
+		document.getElementById("searchRange").value = dateFrom
+		return
+	}
 	
 	document.getElementById("dateFrom").value = dateFrom
 	document.getElementById("dateTo").value = dateTo
 });
+`
+	// JS для подгрузки следующих страниц при прокрутке таблицы
+	pageJS = `
+function loadMore()
+{
+	var rows = document.getElementById("logRows")
+	var cursor = rows.dataset.nextCursor
+	if (!cursor || rows.dataset.loading) {
+		return
+	}
+	rows.dataset.loading = "1"
+
+	var params = new URLSearchParams(window.location.search)
+	params.set("cursor", cursor)
+	params.set("loaded", rows.rows.length)
+
+	var xhr = new XMLHttpRequest()
+	xhr.onreadystatechange = function () {
+	    if (xhr.readyState === 4) {
+			delete rows.dataset.loading
+			if (xhr.status == 200) {
+				rows.insertAdjacentHTML("beforeend", xhr.responseText)
+				rows.dataset.nextCursor = xhr.getResponseHeader("X-Next-Cursor") || ""
+				var limited = xhr.getResponseHeader("X-Records-Limited")
+				if (limited) {
+					document.getElementById("searchMessage").textContent = decodeURIComponent(limited)
+				}
+			} else {
+				rows.dataset.nextCursor = ""
+				console.log("load ERROR:", xhr.responseText)
+			}
+			document.getElementById("loadMore").style.display = rows.dataset.nextCursor ? "" : "none"
+	    }
+	}
+
+	xhr.open("get", "/search/page?" + params.toString(), true)
+	xhr.send()
+}
+
+window.addEventListener("load", function(){
+	var more = document.getElementById("loadMore")
+	if (!more || !("IntersectionObserver" in window)) {
+		return
+	}
+
+	new IntersectionObserver(function (entries) {
+		if (entries[0].isIntersecting) {
+			loadMore()
+		}
+	}, {root: document.getElementById("logScroll")}).observe(more)
+});
 `
 )
 
@@ -84,58 +165,251 @@ var (
 	tableDivClass    = Class("relative overflow-x-auto shadow-md sm:rounded-lg")
 
 	requestTimeFormat = "2006-01-02T15:04"
-	userTimeFormat    = "02.01.2006 15:04:05"
+
+	// Относительные интервалы поиска: с момента now-<длительность> по now
+	searchRanges = []struct {
+		from string
+		name string
+	}{
+		{from: "now-15m", name: "15 минут"},
+		{from: "now-1h", name: "1 час"},
+		{from: "now-6h", name: "6 часов"},
+		{from: "now-24h", name: "24 часа"},
+		{from: "now-168h", name: "7 дней"},
+	}
+
+	errNoInterval = errors.New("no date interval")
+	errBadRelTime = errors.New("relative time must be now or now-<duration>")
 )
 
 func (router *HTTPRouter) webIndex(w http.ResponseWriter, r *http.Request) g.Node {
-	if u, _, _ := router.isAuthenticated(r); u == nil {
-		return router.renderNotLoginGeneral()
+	u, _, _ := router.isAuthenticated(r)
+	tr := userPrinter(r, u)
+
+	if u == nil {
+		return router.renderNotLoginGeneral(tr)
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+
+	page := &model.LogPage{
+		Records:    nil,
+		NextCursor: "",
+	}
+
+	timeFrom, timeTo, err := searchInterval(r, tr.Location())
+	if err == nil {
+		// первая страница, остальные подгружаются при прокрутке через webSearchPage
+		page, err = router.domain.LogUsecase.FindPage(r.Context(), timeFrom, timeTo, cursor,
+			router.config.Runtime().WebPageSize)
+	}
+
+	if err != nil {
+		page = &model.LogPage{
+			Records:    nil,
+			NextCursor: "",
+		}
+	}
+
+	// без JS следующая страница открывается по ссылке
+	nextQuery := r.URL.Query()
+	nextQuery.Set("cursor", page.NextCursor)
+	firstQuery := r.URL.Query()
+	firstQuery.Del("cursor")
+
+	table := Div(Class("flex flex-col "), StyleAttr("height:88vh; table-layout: fixed;"),
+		Div(ID("logScroll"), Class("flex-grow overflow-auto"),
+			Table(tableClass, colorStyleAttr,
+				THead(tableHeaderClass, tableHeaderColorStyleAttr,
+					Tr(g.Group(renderLogTableHeaders(tr)))),
+				TBody(ID("logRows"), tableBodyClass, tableColorStyleAttr,
+					g.Attr("data-next-cursor", page.NextCursor),
+					g.Group(renderLogRows(tr, page.Records, 0)))),
+			g.If(page.NextCursor != "",
+				A(ID("loadMore"), buttonClassRowNewLineSmall, Href("/search?"+nextQuery.Encode()),
+					g.Attr("onclick", "loadMore(); return false;"), g.Text(tr.T("Дальше")))),
+			g.If(cursor != "",
+				A(buttonClassRowNewLineSmall, Href("/search?"+firstQuery.Encode()), g.Text(tr.T("В начало"))))))
+
+	var errorMessage string
+	if errors.Is(err, errNoInterval) {
+		errorMessage = tr.T("Не указан интервал дат")
+	} else if err != nil {
+		errorMessage = err.Error()
+	}
+
+	searchParams := Div(Class("flex flex-wrap items-center space-x-0"),
+		Div(textClassRowSameLine, g.Text(tr.T("Время с"))),
+		Div(Input(buttonClassRowSameLine, ID("dateFrom"), Type("datetime-local"))),
+		Div(textClassRowSameLine, g.Text(tr.T("по"))),
+		Div(Input(buttonClassRowSameLine, ID("dateTo"), Type("datetime-local"))),
+		Div(Input(buttonClassRowSameLine,
+			ID("search"), Type("button"), Value(tr.T("Поиск")), g.Attr("onclick", "doSearch()"))),
+		Div(textClassRowSameLine, g.Text(tr.T("или за последние"))),
+		Div(renderSearchRanges(tr)),
+		Div(textClassRowSameLine, g.Text(tr.Tf("Часовой пояс: %s", tr.Location()))),
+		g.If(err == nil, renderSearchActions(tr, shareQuery(r, tr.Location()))),
+		g.If(err == nil, renderExport(tr, shareQuery(r, tr.Location()))),
+		Div(Label(ID("searchMessage"), Class("text-red-300"), g.Text(errorMessage))),
+		Script(g.Raw(searchJS)),
+		Script(g.Raw(pageJS)),
+	)
+
+	return Div(searchParams, Div(tableDivClass, colorStyleAttr, table))
+}
+
+// Следующая страница таблицы записей (фрагмент HTML со строками таблицы).
+// Курсор следующей страницы передается в хедере X-Next-Cursor
+func (router *HTTPRouter) webSearchPage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, _, _ := router.isAuthenticated(r)
+		if u == nil {
+			router.respondError(w, r, http.StatusUnauthorized, errNotAuthenticated)
+
+			return
+		}
+
+		tr := userPrinter(r, u)
+
+		timeFrom, timeTo, err := searchInterval(r, tr.Location())
+		if err != nil {
+			router.respondError(w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		// общее количество записей в таблице ограничено, чтобы страница не разрасталась бесконечно
+		runtime := router.config.Runtime()
+		loaded, _ := strconv.Atoi(r.URL.Query().Get("loaded"))
+
+		limit := runtime.WebPageSize
+		if rest := runtime.MaxLogRecordsResultWeb - loaded; rest < limit {
+			limit = rest
+		}
+
+		if limit <= 0 {
+			w.Header().Set("X-Records-Limited", url.PathEscape(
+				tr.Tf("Слишком много записей, показано %d", runtime.MaxLogRecordsResultWeb)))
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+
+		page, err := router.domain.LogUsecase.FindPage(r.Context(), timeFrom, timeTo, r.URL.Query().Get("cursor"), limit)
+		if err != nil {
+			router.respondDomainError(w, r, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		for _, row := range renderLogRows(tr, page.Records, 0) {
+			if err := row.Render(w); err != nil {
+				return
+			}
+		}
 	}
+}
 
+// Интервал дат из параметров запроса веб интерфейса. Время указано в часовом поясе loc
+func searchInterval(r *http.Request, loc *time.Location) (timeFrom time.Time, timeTo time.Time, err error) {
 	timeFromRequest := r.URL.Query().Get("from")
 	timeToRequest := r.URL.Query().Get("to")
 
-	var timeFrom time.Time
-	var timeTo time.Time
+	if len(timeFromRequest) == 0 || len(timeToRequest) == 0 {
+		return time.Time{}, time.Time{}, errNoInterval
+	}
 
-	var err error
+	now := time.Now()
 
-	if len(timeFromRequest) > 0 && len(timeToRequest) > 0 {
-		timeFrom, err = time.Parse(requestTimeFormat, timeFromRequest)
-		if err == nil {
-			timeTo, err = time.Parse(requestTimeFormat, timeToRequest)
-		}
-	} else {
-		err = errors.New("Не указан интервал дат")
+	if timeFrom, err = parseSearchTime(timeFromRequest, loc, now); err != nil {
+		return time.Time{}, time.Time{}, errors.Wrap(err, "bad from")
 	}
 
-	var tableHeaders []g.Node
+	if timeTo, err = parseSearchTime(timeToRequest, loc, now); err != nil {
+		return time.Time{}, time.Time{}, errors.Wrap(err, "bad to")
+	}
+
+	return timeFrom, timeTo, nil
+}
+
+// Время из параметра веб интерфейса: в формате requestTimeFormat в часовом поясе loc
+// или относительно текущего момента (now, now-1h, now-30m). Относительное время позволяет
+// сохранять поиски и ссылки, которые всегда показывают последние записи
+func parseSearchTime(v string, loc *time.Location, now time.Time) (time.Time, error) {
+	if !strings.HasPrefix(v, "now") {
+		t, err := time.ParseInLocation(requestTimeFormat, v, loc)
+
+		return t, errors.Wrap(err, "parse error")
+	}
+
+	offset := strings.TrimPrefix(v, "now")
+	if offset == "" {
+		return now.In(loc), nil
+	}
+
+	if !strings.HasPrefix(offset, "-") {
+		return time.Time{}, errBadRelTime
+	}
+
+	d, err := time.ParseDuration(offset[1:])
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "parse error")
+	}
+
+	return now.Add(-d).In(loc), nil
+}
+
+// Выгрузка записей поиска в выбранном формате
+func renderExport(tr *i18n.Printer, query string) g.Node {
+	return g.Group([]g.Node{
+		Div(Select(buttonClassRowSameLine, ID("exportFormat"),
+			g.Group(g.Map(len(exportFormats), func(i int) g.Node {
+				return Option(Value(exportFormats[i]), g.Text(exportFormats[i]))
+			})))),
+		Div(Input(buttonClassRowSameLine, ID("export"), Type("button"), Value(tr.T("Экспорт")),
+			g.Attr("data-query", query),
+			g.Attr("onclick", `window.location.href = "/api/private/export?format=" + `+
+				`document.getElementById("exportFormat").value + "&" + this.dataset.query`))),
+	})
+}
+
+// Список относительных интервалов поиска
+func renderSearchRanges(tr *i18n.Printer) g.Node {
+	return Select(buttonClassRowSameLine, ID("searchRange"), g.Attr("onchange", "setRange(this.value)"),
+		Option(Value(""), g.Text("-")),
+		g.Group(g.Map(len(searchRanges), func(i int) g.Node {
+			return Option(Value(searchRanges[i].from), g.Text(tr.T(searchRanges[i].name)))
+		})))
+}
+
+// Заголовки таблицы записей
+func renderLogTableHeaders(tr *i18n.Printer) []g.Node {
+	tableHeaders := make([]g.Node, 0, len(tableInfo))
 	for _, header := range tableInfo {
 		tableHeaders = append(tableHeaders,
 			Th(Class("sticky top-0 px-6 py-3 text-xs uppercase bg-gray-700 text-white"),
 				g.Group(header.headeStyle),
 				g.Attr("width", fmt.Sprintf("%d%%", header.columnWidth)),
 				g.Group(header.headerAttrs), Class(header.headerClass), tableHeaderColorStyleAttr,
-				g.Text(header.headerName)))
+				g.Text(tr.T(header.headerName))))
 	}
 
-	var logRecords *[]model.LogRecord
-	var limited bool
-	if err == nil {
-		logRecords, limited, err = router.domain.LogUsecase.Find(timeFrom, timeTo, config.AppConfig.MaxLogRecordsResultWeb)
-	}
-	if logRecords == nil {
-		logRecords = &[]model.LogRecord{}
-	}
+	return tableHeaders
+}
 
-	tableRows := make([]g.Node, len(*logRecords))
-	for _, record := range *logRecords {
+// Строки таблицы записей. Время записи - ссылка на страницу записи, запись currentID выделяется
+func renderLogRows(tr *i18n.Printer, records []model.LogRecord, currentID uint64) []g.Node {
+	tableRows := make([]g.Node, 0, len(records))
+
+	for _, record := range records {
 		var rowItems []g.Node
 		for hnum, header := range tableInfo {
 			var cellName string
 			switch hnum {
 			case 0:
-				cellName = record.LogTime.Format(userTimeFormat)
+				cellName = tr.Time(record.LogTime)
 			case 1:
 				cellName = fmt.Sprintf("%d", record.Level)
 			case 2:
@@ -144,41 +418,27 @@ func (router *HTTPRouter) webIndex(w http.ResponseWriter, r *http.Request) g.Nod
 				log.Panicln("internal error")
 			}
 
+			cell := g.Text(cellName)
+			if hnum == 0 {
+				cell = A(Class("hover:underline"), Href(recordPath(record.ID)), cell)
+			}
+
 			rowItems = append(rowItems,
 				Td(
 					g.Group(header.columnAttrs),
 					Class(header.columnClass), tableColorStyleAttr,
-					g.Text(cellName)))
+					cell))
 		}
-		tableRows = append(tableRows, Tr(Class(`border-b bg-gray-800 border-gray-700`),
-			tableColorStyleAttr,
-			g.Group(rowItems)))
-	}
 
-	table := Div(Class("flex flex-col "), StyleAttr("height:88vh; table-layout: fixed;"),
-		Div(Class("flex-grow overflow-auto"),
-			Table(tableClass, colorStyleAttr,
-				THead(tableHeaderClass, tableHeaderColorStyleAttr,
-					Tr(g.Group(tableHeaders)),
-					TBody(tableBodyClass, tableColorStyleAttr, g.Group(tableRows))))))
+		rowStyle := tableColorStyleAttr
+		if record.ID == currentID {
+			rowStyle = tableHeaderColorStyleAttr
+		}
 
-	var errorMessage string
-	if err != nil {
-		errorMessage = err.Error()
-	} else if limited {
-		errorMessage = fmt.Sprintf("Слишком много записей, показано %d", config.AppConfig.MaxLogRecordsResultWeb)
+		tableRows = append(tableRows, Tr(Class(`border-b bg-gray-800 border-gray-700`),
+			rowStyle,
+			g.Group(rowItems)))
 	}
 
-	searchParams := Div(Class("flex flex-wrap items-center space-x-0"),
-		Div(textClassRowSameLine, g.Text("Время с")),
-		Div(Input(buttonClassRowSameLine, ID("dateFrom"), Type("datetime-local"))),
-		Div(textClassRowSameLine, g.Text("по")),
-		Div(Input(buttonClassRowSameLine, ID("dateTo"), Type("datetime-local"))),
-		Div(Input(buttonClassRowSameLine,
-			ID("search"), Type("button"), Value("Поиск"), g.Attr("onclick", "doSearch()"))),
-		Div(Label(ID("searchMessage"), Div(Class("text-red-300"), g.Text(errorMessage)))),
-		Script(g.Raw(searchJS)),
-	)
-
-	return Div(searchParams, Div(tableDivClass, colorStyleAttr, table))
+	return tableRows
 }
@@ -0,0 +1,138 @@
+package httprouter
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+)
+
+// JS для сохраненных поисков
+const searchesJS = `
+function saveSearch(button)
+{
+	var name = prompt(button.dataset.prompt)
+	if (!name) {
+		return
+	}
+
+	apiREST("POST", "/api/private/searches", {
+		name: name,
+		query: button.dataset.query,
+		shared: document.getElementById("searchShared").checked
+	})
+}
+
+function setSearchShared(id, shared)
+{
+	apiREST("PUT", "/api/private/searches/" + id, {shared: shared})
+}
+
+function removeSearch(id, button)
+{
+	if (confirm(button.dataset.confirm)) {
+		apiREST("DELETE", "/api/private/searches/" + id, null)
+	}
+}`
+
+// Адрес страницы поиска с параметрами query
+func searchURL(query string) string {
+	return "/search?" + query
+}
+
+// Запрос поиска для ссылки и сохранения: интервал дат вместе с часовым поясом, в котором он задан,
+// чтобы поиск открывался одинаково у всех пользователей
+func shareQuery(r *http.Request, loc *time.Location) string {
+	search := &model.SavedSearch{
+		ID:        0,
+		UserID:    0,
+		Name:      "",
+		Query:     r.URL.Query().Encode(),
+		Shared:    false,
+		CreatedAt: time.Time{},
+	}
+	search.Prepare()
+
+	values := search.Values()
+	values.Set("tz", loc.String())
+
+	return values.Encode()
+}
+
+// Сохраненные поиски текущего пользователя для навбара. Ошибки не мешают отрисовке страницы
+func (router *HTTPRouter) navSearches(r *http.Request) []model.SavedSearch {
+	user, _, _ := router.isAuthenticated(r)
+	if user == nil {
+		return nil
+	}
+
+	searches, err := router.domain.SearchUsecase.List(r.Context(), user)
+	if err != nil {
+		return nil
+	}
+
+	return *searches
+}
+
+// Выбор сохраненного поиска в навбаре
+func renderSearchSelect(tr *i18n.Printer, searches []model.SavedSearch) g.Node {
+	if len(searches) == 0 {
+		return nil
+	}
+
+	return Select(Class("ml-2 px-2 py-1 text-sm rounded-md bg-gray-800 text-gray-100 border-gray-600"),
+		ID("savedSearches"), g.Attr("onchange", "if (this.value) location.href = this.value"),
+		Option(Value(""), g.Text(tr.T("Сохраненные поиски"))),
+		g.Group(g.Map(len(searches), func(i int) g.Node {
+			return Option(Value(searchURL(searches[i].Query)), g.Text(searches[i].Name))
+		})))
+}
+
+// Ссылка на текущий поиск и его сохранение
+func renderSearchActions(tr *i18n.Printer, query string) g.Node {
+	return g.Group([]g.Node{
+		Div(A(buttonClassRowSameLine, ID("shareSearch"), Href(searchURL(query)), g.Text(tr.T("Ссылка на поиск")))),
+		Div(Input(buttonClassRowSameLine, ID("saveSearch"), Type("button"), Value(tr.T("Сохранить поиск")),
+			g.Attr("data-query", query), g.Attr("data-prompt", tr.T("Название поиска")),
+			g.Attr("onclick", "saveSearch(this)"))),
+		Div(textClassRowSameLine, Label(Input(ID("searchShared"), Type("checkbox")), g.Text(" "+tr.T("Общий")))),
+		Script(g.Raw(searchesJS)),
+	})
+}
+
+// Таблица сохраненных поисков в личном кабинете. Изменять можно свои поиски, админ - также общие
+func renderSearches(tr *i18n.Printer, user *model.User, searches []model.SavedSearch) g.Node {
+	headers := []string{"Название", "Запрос", "Общий", ""}
+
+	rows := g.Map(len(searches), func(i int) g.Node {
+		s := searches[i]
+		editable := s.UserID == user.ID || user.IsAdmin()
+
+		return Tr(Class(`border-b bg-gray-800 border-gray-700`), tableColorStyleAttr,
+			Td(Class(columnClass), tableColorStyleAttr,
+				A(Class("hover:underline"), Href(searchURL(s.Query)), g.Text(s.Name))),
+			Td(Class(columnClass), tableColorStyleAttr, g.Text(s.Query)),
+			Td(Class(columnClass), tableColorStyleAttr,
+				Input(Type("checkbox"), g.If(s.Shared, g.Attr("checked")), g.If(!editable, Disabled()),
+					g.Attr("onchange", fmt.Sprintf("setSearchShared(%d, this.checked)", s.ID)))),
+			Td(Class(columnClass), tableColorStyleAttr,
+				g.If(editable, Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Удалить")),
+					g.Attr("data-confirm", tr.Tf("Удалить поиск %s?", s.Name)),
+					g.Attr("onclick", fmt.Sprintf("removeSearch(%d, this)", s.ID))))))
+	})
+
+	return Div(
+		Div(sectionClass, g.Text(tr.T("Сохраненные поиски"))),
+		Div(tableDivClass, colorStyleAttr,
+			Table(tableClass, colorStyleAttr,
+				THead(tableHeaderClass, tableHeaderColorStyleAttr,
+					Tr(g.Group(g.Map(len(headers), func(i int) g.Node {
+						return Th(Class("px-6 py-3"), tableHeaderColorStyleAttr, g.Text(tr.T(headers[i])))
+					})))),
+				TBody(tableBodyClass, tableColorStyleAttr, g.Group(rows)))),
+		Script(g.Raw(searchesJS)))
+}
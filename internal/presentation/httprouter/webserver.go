@@ -27,23 +27,37 @@ var (
 )
 
 func (router *HTTPRouter) initWebRoutes() {
+	// встроенные css и шрифты
+	router.router.PathPrefix(assetsPrefix).Handler(router.serveAssets()).Methods("GET", "HEAD").Name("assets")
 
-	router.router.HandleFunc("/", router.createWebHandler(router.webIndex)).Methods("GET")
+	router.router.HandleFunc("/", router.createWebHandler(router.webIndex)).Methods("GET").Name("web-index")
 	router.router.HandleFunc("/search", router.createWebHandler(router.webIndex)).
 		Methods("GET").Queries(
 		"from", "{from}",
-		"to", "{to}")
-	router.router.HandleFunc("/login", router.createWebHandler(router.webLogin)).Methods("GET")
-	router.router.HandleFunc("/stats", router.createWebHandler(router.webStats)).Methods("GET")
-	router.router.HandleFunc("/admin", router.createWebHandler(router.webAdmin)).Methods("GET")
+		"to", "{to}").Name("web-search")
+	router.router.HandleFunc("/search/page", router.webSearchPage()).Methods("GET").Name("web-search-page")
+	router.router.HandleFunc("/record/{id:[0-9]+}", router.createWebHandler(router.webRecord)).
+		Methods("GET").Name("web-record")
+	router.router.HandleFunc("/login", router.createWebHandler(router.webLogin)).Methods("GET").Name("web-login")
+	router.router.HandleFunc("/stats", router.createWebHandler(router.webStats)).Methods("GET").Name("web-stats")
+	router.router.HandleFunc("/admin", router.createWebHandler(router.webAdmin)).Methods("GET").Name("web-admin")
 }
 
 type pageHandlerFunc func(http.ResponseWriter, *http.Request) g.Node
 
 func (router *HTTPRouter) createWebHandler(pageHandler pageHandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// сессия сохраняется в куки, поэтому до отрисовки страницы
+		state := router.webPageState(w, r)
+		state.searches = router.navSearches(r)
 		body := pageHandler(w, r)
-		info := getNavInfoByPath(r.URL.Path)
-		_ = page(info.name, r.URL.Path, body).Render(w)
+		tr := requestPrinter(r)
+
+		title := AppName
+		if info := getNavInfoByPath(r.URL.Path); info != nil {
+			title = tr.T(info.name)
+		}
+
+		_ = page(tr, title, r.URL.Path, state, body).Render(w)
 	}
 }
@@ -7,9 +7,11 @@ import (
 )
 
 func (router *HTTPRouter) webStats(w http.ResponseWriter, r *http.Request) g.Node {
+	tr := requestPrinter(r)
+
 	if u, _, _ := router.isAuthenticated(r); u == nil {
-		return router.renderNotLoginGeneral()
+		return router.renderNotLoginGeneral(tr)
 	}
 
-	return router.renderNotImplemeted()
+	return router.renderNotImplemeted(tr)
 }
@@ -0,0 +1,60 @@
+package httprouter
+
+import (
+	"fmt"
+
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+)
+
+const (
+	// JS для управления токенами API. Используется в личном кабинете и админке.
+	// Переведенные сообщения берутся из data- атрибутов кнопок
+	tokensJS = `
+function createToken(id, button)
+{
+	var name = prompt(button.dataset.prompt)
+	if (name === null) {
+		return
+	}
+
+	apiREST("POST", "/api/private/users/" + id + "/tokens", {name: name}, function (resp) {
+		prompt(button.dataset.created, resp.token)
+	})
+}
+
+function revokeToken(id, button)
+{
+	if (!confirm(button.dataset.confirm)) {
+		return
+	}
+
+	apiREST("DELETE", "/api/private/tokens/" + id, null)
+}`
+)
+
+// Список токенов пользователя с кнопками отзыва и создания нового токена
+func renderTokens(tr *i18n.Printer, userID uint64, tokens []model.APIToken) g.Node {
+	items := g.Map(len(tokens), func(i int) g.Node {
+		t := tokens[i]
+
+		used := tr.T("не использовался")
+		if t.LastUsedAt != nil {
+			used = tr.Tf("использован %s", tr.Time(*t.LastUsedAt))
+		}
+
+		return Div(
+			g.Text(tr.Tf("%s (создан %s, %s)", t.Name, tr.Time(t.CreatedAt), used)),
+			Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Отозвать")),
+				g.Attr("data-confirm", tr.Tf("Отозвать токен %s?", t.Name)),
+				g.Attr("onclick", fmt.Sprintf("revokeToken(%d, this)", t.ID))))
+	})
+
+	return g.Group(append(items,
+		Input(buttonClassRowSameLine, Type("button"), Value(tr.T("Новый токен")),
+			g.Attr("data-prompt", tr.T("Название токена")),
+			g.Attr("data-created", tr.T("Токен показывается только один раз, сохраните его")),
+			g.Attr("onclick", fmt.Sprintf("createToken(%d, this)", userID)))))
+}
@@ -0,0 +1,162 @@
+package i18n
+
+// Перевод на английский язык
+var english = map[string]string{
+	// навбар
+	"Логгер":            "Logger",
+	"Просмотр":          "View",
+	"Поиск":             "Search",
+	"Запись журнала":    "Log record",
+	"Статистика":        "Statistics",
+	"Администрирование": "Administration",
+	"Личный кабинет":    "Account",
+
+	// общие
+	"Необходимо войти в личный кабинет":      "Please log in to your account",
+	"Тут пока еще ничего нет":                "Nothing here yet",
+	"Ошибка сервера: %v":                     "Server error: %v",
+	"Раздел доступен только администраторам": "This section is available to administrators only",
+
+	// журнал
+	"Время":      "Time",
+	"Уровень":    "Level",
+	"Информация": "Message",
+	"Время с":    "Time from",
+	"по":         "to",
+	"Дальше":     "Next",
+	"В начало":   "First page",
+	"Не указан интервал дат":             "Date interval is not specified",
+	"Слишком много записей, показано %d": "Too many records, %d shown",
+	"Время получения":                    "Received",
+	"Запись %d не найдена":               "Record %d not found",
+	"Запись журнала %d":                  "Log record %d",
+	"Постоянная ссылка":                  "Permalink",
+	"Показать соседние записи":           "Show surrounding records",
+	"Скрыть соседние записи":             "Hide surrounding records",
+	"Журнал за этот период":              "Log for this period",
+	"Соседние записи":                    "Surrounding records",
+
+	// вход и личный кабинет
+	"Логин":            "Login",
+	"Пароль":           "Password",
+	"Войти":            "Log in",
+	"Выйти":            "Log out",
+	"Привет":           "Hello",
+	"Вход не выполнен": "Not logged in",
+	"Пользователь не существует": "User does not exist",
+	"Смена пароля":               "Change password",
+	"Текущий пароль":             "Current password",
+	"Новый пароль":               "New password",
+	"Повтор":                     "Repeat",
+	"Сменить":                    "Change",
+	"Пароли не совпадают":        "Passwords do not match",
+	"Сессии":                     "Sessions",
+	"Вход":                       "Logged in",
+	"Последнее обращение":        "Last seen",
+	"Адрес":                      "Address",
+	"Браузер":                    "Browser",
+	"Текущая":                    "Current",
+	"Завершить":                  "End",
+
+	"Пароль изменен, остальные сессии завершены": "Password changed, other sessions ended",
+	"Сессия завершена":                           "Session ended",
+
+	// часовой пояс
+	"Часовой пояс":          "Time zone",
+	"Часовой пояс: %s":      "Time zone: %s",
+	"Часовой пояс браузера": "Browser time zone",
+	"Часовой пояс изменен":  "Time zone changed",
+
+	"Например, Europe/Moscow. Пусто - часовой пояс браузера": "For example, Europe/Moscow. Empty - browser time zone",
+
+	// сохраненные поиски
+	"или за последние":   "or for the last",
+	"15 минут":           "15 minutes",
+	"1 час":              "1 hour",
+	"6 часов":            "6 hours",
+	"24 часа":            "24 hours",
+	"7 дней":             "7 days",
+	"Ссылка на поиск":    "Search link",
+	"Сохранить поиск":    "Save search",
+	"Название поиска":    "Search name",
+	"Общий":              "Shared",
+	"Сохраненные поиски": "Saved searches",
+	"Название":           "Name",
+	"Запрос":             "Query",
+	"Удалить поиск %s?":  "Delete search %s?",
+	"Поиск %s сохранен":  "Search %s saved",
+	"Поиск изменен":      "Search updated",
+	"Поиск удален":       "Search deleted",
+
+	// токены
+	"Токены API":         "API tokens",
+	"Токены":             "Tokens",
+	"Новый токен":        "New token",
+	"Отозвать":           "Revoke",
+	"Название токена":    "Token name",
+	"Отозвать токен %s?": "Revoke token %s?",
+	"%s (создан %s, %s)": "%s (created %s, %s)",
+	"не использовался":   "never used",
+	"использован %s":     "used %s",
+	"Токен %s создан":    "Token %s created",
+	"Токен отозван":      "Token revoked",
+
+	"Токен показывается только один раз, сохраните его": "The token is shown only once, save it",
+
+	// пользователи
+	"Имя":                 "Name",
+	"Роль":                "Role",
+	"Добавить":            "Add",
+	"Сохранить":           "Save",
+	"Сменить пароль":      "Change password",
+	"Удалить":             "Delete",
+	"Новый пароль для %s": "New password for %s",
+	"Удалить пользователя %s?": "Delete user %s?",
+	"Пользователь %s добавлен": "User %s added",
+	"Пользователь изменен":     "User updated",
+	"Пользователь %d удален":   "User %d deleted",
+
+	// оповещения
+	"Правила оповещений":           "Alert rules",
+	"Условие":                      "Condition",
+	"Состояние":                    "State",
+	"Включено":                     "Enabled",
+	"Выключено":                    "Disabled",
+	"Уровень от":                   "Level from",
+	"Шаблон":                       "Pattern",
+	"регулярное выражение":         "regular expression",
+	"Больше":                       "More than",
+	"записей за (мин.)":            "records in (min)",
+	"больше %d записей за %d мин.": "more than %d records in %d min",
+	"больше %d записей за %d сек.": "more than %d records in %d sec",
+	"уровень от %d":                "level from %d",
+	"Сработало %s":                 "Firing since %s",
+	"Норма (%d)":                   "OK (%d)",
+	"заглушено до %s":              "silenced until %s",
+	"Заглушить":                    "Silence",
+	"Снять заглушку":               "Unsilence",
+	"Заглушить уведомления на сколько минут?": "Silence notifications for how many minutes?",
+	"Удалить правило %s?":                     "Delete rule %s?",
+	"Правило %s добавлено":                    "Rule %s added",
+	"Правило изменено":                        "Rule updated",
+	"Правило удалено":                         "Rule deleted",
+	"Правило %s заглушено на %d мин.":         "Rule %s silenced for %d min",
+	"Заглушка правила %s снята":               "Rule %s unsilenced",
+
+	// уведомления
+	"Каналы уведомлений": "Notification channels",
+	"Тип":                "Type",
+	"Канал":              "Channel",
+	"Правило":            "Rule",
+	"Результат":          "Result",
+	"тест":               "test",
+	"Отправить тест":     "Send test",
+	"Последние уведомления":                             "Recent notifications",
+	"Доставлено (попыток: %d)":                          "Delivered (attempts: %d)",
+	"Ошибка (попыток: %d): %s":                          "Failed (attempts: %d): %s",
+	"Тестовое уведомление отправлено в канал %s":        "Test notification sent to channel %s",
+	"Каналы уведомлений не настроены (NOTIFY_CHANNELS)": "No notification channels configured (NOTIFY_CHANNELS)",
+	"Не удалось отправить уведомление в канал %s: %s":   "Failed to send notification to channel %s: %s",
+
+	"Экспорт": "Export",
+}
@@ -0,0 +1,125 @@
+// Package i18n Перевод строк веб интерфейса. Исходные строки написаны на русском языке и служат
+// ключами каталога, для остальных языков переводы задаются в catalog_*.go. Строка без перевода
+// показывается как есть
+package i18n
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Lang Язык интерфейса (код ISO 639-1)
+type Lang string
+
+const (
+	Russian Lang = "ru"
+	English Lang = "en"
+
+	// Default Язык по умолчанию, на нем написаны исходные строки
+	Default = Russian
+)
+
+// Languages Поддерживаемые языки. Первый используется, если язык клиента не поддерживается
+var Languages = []Lang{Russian, English}
+
+// Переводы по языкам. Для языка по умолчанию каталога нет
+var catalogs = map[Lang]map[string]string{
+	English: english,
+}
+
+// Форматы даты и времени по языкам
+var timeFormats = map[Lang]string{
+	Russian: "02.01.2006 15:04:05",
+	English: "01/02/2006 15:04:05",
+}
+
+// Выбор языка по Accept-Language. Индексы тегов совпадают с индексами Languages
+var matcher = func() language.Matcher {
+	tags := make([]language.Tag, 0, len(Languages))
+	for _, lang := range Languages {
+		tags = append(tags, language.Make(string(lang)))
+	}
+
+	return language.NewMatcher(tags)
+}()
+
+// Parse Язык по коду. false, если язык не поддерживается
+func Parse(s string) (Lang, bool) {
+	for _, lang := range Languages {
+		if string(lang) == s {
+			return lang, true
+		}
+	}
+
+	return Default, false
+}
+
+// Negotiate Выбор языка по заголовку Accept-Language
+func Negotiate(acceptLanguage string) Lang {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+
+	return Languages[index]
+}
+
+// Printer Перевод строк на выбранный язык и отображение времени в выбранном часовом поясе
+type Printer struct {
+	lang    Lang
+	catalog map[string]string
+	loc     *time.Location
+}
+
+// NewPrinter Перевод на язык lang. Время отображается в UTC
+func NewPrinter(lang Lang) *Printer {
+	return &Printer{
+		lang:    lang,
+		catalog: catalogs[lang],
+		loc:     time.UTC,
+	}
+}
+
+// In Копия с отображением времени в часовом поясе loc
+func (p *Printer) In(loc *time.Location) *Printer {
+	c := *p
+	c.loc = loc
+
+	return &c
+}
+
+// Location Часовой пояс, в котором отображается время
+func (p *Printer) Location() *time.Location {
+	return p.loc
+}
+
+// Lang Язык перевода
+func (p *Printer) Lang() Lang {
+	return p.lang
+}
+
+// T Перевод строки
+func (p *Printer) T(msg string) string {
+	if s, ok := p.catalog[msg]; ok {
+		return s
+	}
+
+	return msg
+}
+
+// Tf Перевод строки формата fmt.Sprintf с подстановкой аргументов
+func (p *Printer) Tf(format string, args ...interface{}) string {
+	return fmt.Sprintf(p.T(format), args...)
+}
+
+// Time Дата и время в формате языка и часовом поясе Printer
+func (p *Printer) Time(t time.Time) string {
+	return t.In(p.loc).Format(timeFormats[p.lang])
+}
@@ -0,0 +1,58 @@
+package i18n_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/presentation/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expected       i18n.Lang
+	}{
+		{name: "empty", acceptLanguage: "", expected: i18n.Russian},
+		{name: "english", acceptLanguage: "en-US,en;q=0.9", expected: i18n.English},
+		{name: "russian", acceptLanguage: "ru-RU,ru;q=0.9,en;q=0.8", expected: i18n.Russian},
+		{name: "second choice", acceptLanguage: "de-DE,de;q=0.9,en;q=0.5", expected: i18n.English},
+		{name: "unsupported", acceptLanguage: "ja", expected: i18n.Russian},
+		{name: "bad header", acceptLanguage: ";;;", expected: i18n.Russian},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, i18n.Negotiate(tc.acceptLanguage))
+		})
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	en := i18n.NewPrinter(i18n.English)
+	assert.Equal(t, "Search", en.T("Поиск"))
+	assert.Equal(t, "User bob added", en.Tf("Пользователь %s добавлен", "bob"))
+	// строка без перевода показывается как есть
+	assert.Equal(t, "Нет перевода", en.T("Нет перевода"))
+
+	ru := i18n.NewPrinter(i18n.Russian)
+	assert.Equal(t, "Поиск", ru.T("Поиск"))
+
+	tm := time.Date(2022, 4, 23, 18, 25, 43, 0, time.UTC)
+	assert.Equal(t, "23.04.2022 18:25:43", ru.Time(tm))
+	assert.Equal(t, "04/23/2022 18:25:43", en.Time(tm))
+
+	msk, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	assert.Equal(t, "23.04.2022 21:25:43", ru.In(msk).Time(tm))
+	assert.Equal(t, time.UTC, ru.Location())
+
+	lang, ok := i18n.Parse("en")
+	assert.True(t, ok)
+	assert.Equal(t, i18n.English, lang)
+
+	_, ok = i18n.Parse("de")
+	assert.False(t, ok)
+}
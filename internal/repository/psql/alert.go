@@ -0,0 +1,140 @@
+// Package psql Содержит реализацию интерфейса репозитория правил оповещений для postgresql
+package psql
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Релизация интерфейса AlertInterface для psql
+type alertImpl struct {
+	dbImpl *sqlDbImpl
+	db     *pgxpool.Pool
+}
+
+// NewAlert Возвращаем интерфейс работы с правилами оповещений
+func NewAlert(db repository.DBOInterface) repository.AlertInterface { //nolint:ireturn
+	dbImpl, ok := db.(*sqlDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &alertImpl{
+		dbImpl: dbImpl,
+		db:     dbImpl.db,
+	}
+}
+
+const alertColumns = "id, name, min_level, pattern, threshold, window_sec, enabled, silenced_until, created_at"
+
+// Insert Добавить правило
+func (r *alertImpl) Insert(ctx context.Context, rule *model.AlertRule) error {
+	if err := rule.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO alert_rules (name, min_level, pattern, threshold, window_sec, enabled, silenced_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		rule.Name, rule.MinLevel, rule.Pattern, rule.Threshold, rule.WindowSec, rule.Enabled, rule.SilencedUntil,
+	).Scan(&rule.ID, &rule.CreatedAt)
+
+	rule.CreatedAt = rule.CreatedAt.UTC()
+
+	return dbError(ctx, err, "QueryRow error")
+}
+
+// Update Изменить правило
+func (r *alertImpl) Update(ctx context.Context, rule *model.AlertRule) error {
+	if err := rule.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	tag, err := r.db.Exec(ctx,
+		`UPDATE alert_rules SET name = $1, min_level = $2, pattern = $3, threshold = $4, window_sec = $5,
+		enabled = $6, silenced_until = $7 WHERE id = $8`,
+		rule.Name, rule.MinLevel, rule.Pattern, rule.Threshold, rule.WindowSec, rule.Enabled, rule.SilencedUntil,
+		rule.ID)
+	if err != nil {
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// Remove Удалить правило
+func (r *alertImpl) Remove(ctx context.Context, ruleID uint64) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM alert_rules WHERE id = $1", ruleID)
+	if err != nil {
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// FindByID Поиск правила по ID
+func (r *alertImpl) FindByID(ctx context.Context, ruleID uint64) (*model.AlertRule, error) {
+	rule, err := scanAlertRule(r.db.QueryRow(ctx, "SELECT "+alertColumns+" FROM alert_rules WHERE id = $1", ruleID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, dbError(ctx, err, "QueryRow error")
+	}
+
+	return rule, nil
+}
+
+// GetAll Все правила
+func (r *alertImpl) GetAll(ctx context.Context) (*[]model.AlertRule, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+alertColumns+" FROM alert_rules ORDER BY id")
+	if err != nil {
+		return nil, dbError(ctx, err, "query error")
+	}
+	defer rows.Close()
+
+	rules := []model.AlertRule{}
+
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, dbError(ctx, err, "rows scan error")
+		}
+
+		rules = append(rules, *rule)
+	}
+
+	return &rules, dbError(ctx, rows.Err(), "rows error")
+}
+
+func scanAlertRule(row pgx.Row) (*model.AlertRule, error) {
+	var rule model.AlertRule
+	if err := row.Scan(&rule.ID, &rule.Name, &rule.MinLevel, &rule.Pattern, &rule.Threshold, &rule.WindowSec,
+		&rule.Enabled, &rule.SilencedUntil, &rule.CreatedAt); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	rule.CreatedAt = rule.CreatedAt.UTC()
+	if rule.SilencedUntil != nil {
+		until := rule.SilencedUntil.UTC()
+		rule.SilencedUntil = &until
+	}
+
+	return &rule, nil
+}
@@ -3,55 +3,107 @@ package psql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/logger"
+	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/repository"
-	"github.com/pkg/errors"
+	werrors "github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-var sqlDB *sqlDbImpl
-
 // Реализация SqlDbInterface для psql
 type sqlDbImpl struct {
-	db *pgxpool.Pool
+	db        *pgxpool.Pool
+	collector prometheus.Collector
 }
 
-func CreatePsqlDBO() (repository.DBOInterface, error) {
-	sqlDB = new(sqlDbImpl)
+// CreatePsqlDBO Подключение к БД
+func CreatePsqlDBO(cfg *config.Config) (repository.DBOInterface, error) {
+	sqlDB := new(sqlDbImpl)
 
-	if err := sqlDB.dbConnect(); err != nil {
+	if err := sqlDB.dbConnect(cfg); err != nil {
 		return nil, err
 	}
 
+	// метрики пула соединений
+	sqlDB.collector = newPoolCollector(sqlDB.db)
+	if err := prometheus.Register(sqlDB.collector); err != nil {
+		logger.Logger().Warnf("pgxpool metrics register error: %v", err)
+	}
+
 	return sqlDB, nil
 }
 
 // Close Завершение работы с хранилищем
+//
 //goland:noinspection GoUnnecessarilyExportedIdentifiers
 func (s *sqlDbImpl) Close() {
+	if s.collector != nil {
+		prometheus.Unregister(s.collector)
+	}
+
 	s.db.Close()
 }
 
+// Ping Проверка доступности БД
+func (s *sqlDbImpl) Ping(ctx context.Context) error {
+	return dbError(ctx, s.db.Ping(ctx), "ping error")
+}
+
+// Stats Состояние пула соединений
+func (s *sqlDbImpl) Stats() model.DBStats {
+	stat := s.db.Stat()
+
+	return model.DBStats{
+		TotalConns:    stat.TotalConns(),
+		IdleConns:     stat.IdleConns(),
+		AcquiredConns: stat.AcquiredConns(),
+		MaxConns:      stat.MaxConns(),
+	}
+}
+
 // Подключение к БД
-func (s *sqlDbImpl) dbConnect() error {
-	url := config.AppConfig.DatabaseURL
+func (s *sqlDbImpl) dbConnect(cfg *config.Config) error {
+	url := cfg.DatabaseURL
 	url = fmt.Sprintf("%s %s=%d %s=%ds", url,
-		"pool_max_conns", config.AppConfig.MaxDbSessions,
-		"pool_max_conn_idle_time", config.AppConfig.MaxDbSessionIdleTimeSec)
+		"pool_max_conns", cfg.MaxDbSessions,
+		"pool_max_conn_idle_time", cfg.MaxDbSessionIdleTimeSec)
 
 	dbPool, err := pgxpool.Connect(context.Background(), url)
 	if err != nil {
-		return errors.Wrap(err, "connect error")
+		return werrors.Wrap(err, "connect error")
 	}
 
 	s.db = dbPool
 
 	// пробуем открыть БД
 	if err := dbPool.Ping(context.Background()); err != nil {
-		return errors.Wrap(err, "ping error")
+		return dbError(context.Background(), err, "ping error")
 	}
 
 	return nil
 }
+
+// Обертка ошибки БД. Ошибки сети и таймауты означают временную недоступность хранилища.
+// Ошибка журналируется с номером запроса из контекста
+func dbError(ctx context.Context, err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	logger.FromContext(ctx).WithError(err).Warn(message)
+
+	var netErr net.Error
+	if pgconn.Timeout(err) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return apperr.Wrap(apperr.KindUnavailable, err, message)
+	}
+
+	return werrors.Wrap(err, message)
+}
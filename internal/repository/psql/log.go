@@ -3,57 +3,76 @@ package psql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/logger"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/repository"
-	"github.com/pkg/errors"
 )
 
 // Релизация интерфейса LogInterface для psql
 type logImpl struct {
 	dbImpl *sqlDbImpl
 	db     *pgxpool.Pool
+	// максимальное количество записей в ответе
+	maxRecords int
 }
 
 // NewLog Возвращаем интерфейс работы с логами
-func NewLog(db repository.DBOInterface) repository.LogInterface { //nolint:ireturn
+func NewLog(db repository.DBOInterface, cfg *config.Config) repository.LogInterface { //nolint:ireturn
 	dbImpl, ok := db.(*sqlDbImpl)
 	if !ok {
 		log.Panicln("internal error")
 	}
 
 	return &logImpl{
-		dbImpl: dbImpl,
-		db:     dbImpl.db,
+		dbImpl:     dbImpl,
+		db:         dbImpl.db,
+		maxRecords: cfg.MaxLogRecordsResult,
 	}
 }
 
-func (p *logImpl) Insert(records *[]model.LogRecord) error {
-	var sqlText string
+func (p *logImpl) Insert(ctx context.Context, records *[]model.LogRecord) error {
+	// параметризованные запросы отправляются одним пакетом и выполняются в одной неявной транзакции
+	batch := &pgx.Batch{}
 
 	for _, lr := range *records {
 		if err := lr.Validate(); err != nil {
-			return errors.Wrap(err, "validate error")
+			return apperr.Validation(err)
 		}
 
-		t, _ := lr.LogTime.UTC().MarshalText()
-		sqlText += fmt.Sprintf(`INSERT INTO log (record_timestamp, level, message1, message2, message3) 
-		 					    VALUES ('%s', %d, '%s', '%s', '%s');`,
-			t, lr.Level, lr.Message1, lr.Message2, lr.Message3)
+		batch.Queue(`INSERT INTO log (record_timestamp, level, message1, message2, message3) 
+					 VALUES ($1, $2, $3, $4, $5)`,
+			lr.LogTime, lr.Level, lr.Message1, lr.Message2, lr.Message3)
 	}
 
-	_, err := p.db.Exec(context.Background(), sqlText)
+	results := p.db.SendBatch(ctx, batch)
 
-	return errors.Wrap(err, "exec error")
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+
+			return dbError(ctx, err, "exec error")
+		}
+	}
+
+	return dbError(ctx, results.Close(), "batch close error")
 }
 
-func (p *logImpl) Find(dateFrom time.Time, dateTo time.Time, limit int) (records *[]model.LogRecord, limited bool, err error) {
-	rows, err := p.db.Query(context.Background(),
+func (p *logImpl) Find(ctx context.Context, dateFrom time.Time, dateTo time.Time, limit int) (
+	records *[]model.LogRecord, limited bool, err error,
+) {
+	start := time.Now()
+
+	rows, err := p.db.Query(ctx,
 		`SELECT id, record_timestamp, real_timestamp, level,  message1, COALESCE(message2, ''), COALESCE(message3, '') 
 		FROM log
 		WHERE ($1 OR record_timestamp >= $2) AND ($3 OR record_timestamp <= $4)
@@ -61,7 +80,7 @@ func (p *logImpl) Find(dateFrom time.Time, dateTo time.Time, limit int) (records
 		LIMIT $5`,
 		dateFrom.IsZero(), dateFrom, dateTo.IsZero(), dateTo, limit+1)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "query error")
+		return nil, false, dbError(ctx, err, "query error")
 	}
 	defer rows.Close() // освобождаем контекст sql запроса при выходе
 
@@ -75,7 +94,7 @@ func (p *logImpl) Find(dateFrom time.Time, dateTo time.Time, limit int) (records
 
 		if err := rows.Scan(&record.ID, &record.LogTime, &record.RealTime,
 			&record.Level, &record.Message1, &record.Message2, &record.Message3); err != nil {
-			return nil, false, errors.Wrap(err, "rows scan error")
+			return nil, false, dbError(ctx, err, "rows scan error")
 		}
 
 		rowCount++
@@ -84,11 +103,12 @@ func (p *logImpl) Find(dateFrom time.Time, dateTo time.Time, limit int) (records
 			break
 		}
 
-		if rowCount > uint64(config.AppConfig.MaxLogRecordsResult) {
-			err := fmt.Errorf("too many records, max %d", config.AppConfig.MaxLogRecordsResult)
-			return nil, false, err
+		if rowCount > uint64(p.maxRecords) {
+			return nil, false, apperr.New(apperr.KindValidation,
+				fmt.Sprintf("too many records, max %d", p.maxRecords))
 		}
 
+		toUTC(&record)
 		recs = append(recs, record)
 	}
 
@@ -96,5 +116,143 @@ func (p *logImpl) Find(dateFrom time.Time, dateTo time.Time, limit int) (records
 	// поэтому надежнее сделать как defer rows.Close(), так и прямое закрытие здесь
 	rows.Close()
 
+	logger.FromContext(ctx).Debugf("log find: %d records in %v", len(recs), time.Since(start))
+
 	return &recs, limited, nil
 }
+
+func (p *logImpl) FindPage(ctx context.Context, dateFrom time.Time, dateTo time.Time, after *model.LogCursor,
+	limit int,
+) (records *[]model.LogRecord, more bool, err error) {
+	start := time.Now()
+
+	// условия добавляются только для заданных границ, чтобы планировщик мог использовать индекс
+	// по (record_timestamp, id) для сравнения строк с курсором
+	var (
+		where []string
+		args  []interface{}
+	)
+
+	if !dateFrom.IsZero() {
+		args = append(args, dateFrom)
+		where = append(where, fmt.Sprintf("record_timestamp >= $%d", len(args)))
+	}
+
+	if !dateTo.IsZero() {
+		args = append(args, dateTo)
+		where = append(where, fmt.Sprintf("record_timestamp <= $%d", len(args)))
+	}
+
+	if after != nil {
+		args = append(args, after.LogTime.UTC(), after.ID)
+		where = append(where, fmt.Sprintf("(record_timestamp, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	query := `SELECT id, record_timestamp, real_timestamp, level, message1, COALESCE(message2, ''), COALESCE(message3, '')
+		FROM log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	// запрашиваем на одну запись больше, чтобы узнать есть ли следующая страница
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY record_timestamp DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, false, dbError(ctx, err, "query error")
+	}
+	defer rows.Close()
+
+	recs := make([]model.LogRecord, 0, limit)
+
+	for rows.Next() {
+		var record model.LogRecord
+
+		if err := rows.Scan(&record.ID, &record.LogTime, &record.RealTime,
+			&record.Level, &record.Message1, &record.Message2, &record.Message3); err != nil {
+			return nil, false, dbError(ctx, err, "rows scan error")
+		}
+
+		if len(recs) == limit {
+			more = true
+
+			break
+		}
+
+		toUTC(&record)
+		recs = append(recs, record)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, false, dbError(ctx, err, "rows error")
+	}
+
+	logger.FromContext(ctx).Debugf("log find page: %d records in %v", len(recs), time.Since(start))
+
+	return &recs, more, nil
+}
+
+func (p *logImpl) FindNewer(ctx context.Context, before model.LogCursor, limit int) (*[]model.LogRecord, error) {
+	rows, err := p.db.Query(ctx,
+		`SELECT id, record_timestamp, real_timestamp, level,  message1, COALESCE(message2, ''), COALESCE(message3, '') 
+		FROM log
+		WHERE (record_timestamp, id) > ($1, $2)
+		ORDER BY record_timestamp, id
+		LIMIT $3`,
+		before.LogTime.UTC(), before.ID, limit)
+	if err != nil {
+		return nil, dbError(ctx, err, "query error")
+	}
+	defer rows.Close()
+
+	recs := make([]model.LogRecord, 0, limit)
+
+	for rows.Next() {
+		var record model.LogRecord
+
+		if err := rows.Scan(&record.ID, &record.LogTime, &record.RealTime,
+			&record.Level, &record.Message1, &record.Message2, &record.Message3); err != nil {
+			return nil, dbError(ctx, err, "rows scan error")
+		}
+
+		toUTC(&record)
+		recs = append(recs, record)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err, "rows error")
+	}
+
+	return &recs, nil
+}
+
+func (p *logImpl) FindByID(ctx context.Context, id uint64) (*model.LogRecord, error) {
+	var record model.LogRecord
+
+	if err := p.db.QueryRow(ctx,
+		`SELECT id, record_timestamp, real_timestamp, level,  message1, COALESCE(message2, ''), COALESCE(message3, '') 
+		FROM log
+		WHERE id = $1`, id).Scan(&record.ID, &record.LogTime, &record.RealTime,
+		&record.Level, &record.Message1, &record.Message2, &record.Message3); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, dbError(ctx, err, "QueryRow error")
+	}
+
+	toUTC(&record)
+
+	return &record, nil
+}
+
+// Время записи в UTC. pgx возвращает timestamptz в локальном часовом поясе сервера
+func toUTC(record *model.LogRecord) {
+	record.LogTime = record.LogTime.UTC()
+	record.RealTime = record.RealTime.UTC()
+}
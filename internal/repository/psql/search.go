@@ -0,0 +1,127 @@
+// Package psql Содержит реализацию интерфейса репозитория сохраненных поисков для postgresql
+package psql
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Релизация интерфейса SearchInterface для psql
+type searchImpl struct {
+	dbImpl *sqlDbImpl
+	db     *pgxpool.Pool
+}
+
+// NewSearch Возвращаем интерфейс работы с сохраненными поисками
+func NewSearch(db repository.DBOInterface) repository.SearchInterface { //nolint:ireturn
+	dbImpl, ok := db.(*sqlDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &searchImpl{
+		dbImpl: dbImpl,
+		db:     dbImpl.db,
+	}
+}
+
+const searchColumns = "id, user_id, name, query, shared, created_at"
+
+// Insert Добавить поиск
+func (r *searchImpl) Insert(ctx context.Context, search *model.SavedSearch) error {
+	if err := search.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	err := r.db.QueryRow(ctx,
+		"INSERT INTO saved_searches (user_id, name, query, shared) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		search.UserID, search.Name, search.Query, search.Shared,
+	).Scan(&search.ID, &search.CreatedAt)
+
+	search.CreatedAt = search.CreatedAt.UTC()
+
+	return dbError(ctx, err, "QueryRow error")
+}
+
+// Update Изменить поиск
+func (r *searchImpl) Update(ctx context.Context, search *model.SavedSearch) error {
+	if err := search.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	tag, err := r.db.Exec(ctx, "UPDATE saved_searches SET name = $1, query = $2, shared = $3 WHERE id = $4",
+		search.Name, search.Query, search.Shared, search.ID)
+	if err != nil {
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrSearchNotFound
+	}
+
+	return nil
+}
+
+// Remove Удалить поиск
+func (r *searchImpl) Remove(ctx context.Context, searchID uint64) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM saved_searches WHERE id = $1", searchID)
+	if err != nil {
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrSearchNotFound
+	}
+
+	return nil
+}
+
+// FindByID Поиск по ID
+func (r *searchImpl) FindByID(ctx context.Context, searchID uint64) (*model.SavedSearch, error) {
+	var s model.SavedSearch
+
+	err := r.db.QueryRow(ctx, "SELECT "+searchColumns+" FROM saved_searches WHERE id = $1", searchID).
+		Scan(&s.ID, &s.UserID, &s.Name, &s.Query, &s.Shared, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, dbError(ctx, err, "QueryRow error")
+	}
+
+	s.CreatedAt = s.CreatedAt.UTC()
+
+	return &s, nil
+}
+
+// GetByUser Поиски пользователя и общие поиски остальных пользователей
+func (r *searchImpl) GetByUser(ctx context.Context, userID uint64) (*[]model.SavedSearch, error) {
+	rows, err := r.db.Query(ctx,
+		"SELECT "+searchColumns+" FROM saved_searches WHERE user_id = $1 OR shared ORDER BY lower(name), id", userID)
+	if err != nil {
+		return nil, dbError(ctx, err, "query error")
+	}
+	defer rows.Close()
+
+	searches := []model.SavedSearch{}
+
+	for rows.Next() {
+		var s model.SavedSearch
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Name, &s.Query, &s.Shared, &s.CreatedAt); err != nil {
+			return nil, dbError(ctx, err, "rows scan error")
+		}
+
+		s.CreatedAt = s.CreatedAt.UTC()
+		searches = append(searches, s)
+	}
+
+	return &searches, dbError(ctx, rows.Err(), "rows error")
+}
@@ -0,0 +1,125 @@
+// Package psql Содержит реализацию интерфейса репозитория сессий для postgresql
+package psql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Релизация интерфейса SessionInterface для psql.
+// Время задается на стороне приложения в UTC, чтобы не зависеть от часового пояса сервера БД
+type sessionImpl struct {
+	dbImpl *sqlDbImpl
+	db     *pgxpool.Pool
+}
+
+// NewSession Возвращаем интерфейс работы с сессиями
+func NewSession(db repository.DBOInterface) repository.SessionInterface { //nolint:ireturn
+	dbImpl, ok := db.(*sqlDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &sessionImpl{
+		dbImpl: dbImpl,
+		db:     dbImpl.db,
+	}
+}
+
+const sessionColumns = "id, user_id, created_at, last_seen_at, expires_at, remote_addr, user_agent"
+
+// Insert Добавить сессию
+func (r *sessionImpl) Insert(ctx context.Context, session *model.Session, hash string) error {
+	now := time.Now().UTC()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	err := r.db.QueryRow(ctx,
+		`WITH expired AS (DELETE FROM user_sessions WHERE user_id = $1 AND expires_at <= $3)
+		INSERT INTO user_sessions (user_id, session_hash, created_at, last_seen_at, expires_at, remote_addr, user_agent)
+		VALUES ($1, $2, $3, $3, $4, $5, $6) RETURNING id`,
+		session.UserID, hash, now, session.ExpiresAt.UTC(), session.RemoteAddr, session.UserAgent,
+	).Scan(&session.ID)
+
+	return dbError(ctx, err, "QueryRow error")
+}
+
+// Remove Удалить сессию
+func (r *sessionImpl) Remove(ctx context.Context, sessionID uint64) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM user_sessions WHERE id = $1", sessionID)
+	if err != nil {
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RemoveByUser Удалить все сессии пользователя, кроме exceptID
+func (r *sessionImpl) RemoveByUser(ctx context.Context, userID uint64, exceptID uint64) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM user_sessions WHERE user_id = $1 AND id <> $2", userID, exceptID)
+
+	return dbError(ctx, err, "Exec error")
+}
+
+// FindByID Поиск сессии по ID
+func (r *sessionImpl) FindByID(ctx context.Context, sessionID uint64) (*model.Session, error) {
+	return r.scanOne(ctx, r.db.QueryRow(ctx,
+		"SELECT "+sessionColumns+" FROM user_sessions WHERE id = $1", sessionID))
+}
+
+// FindByHash Поиск действующей сессии по хэшу с отметкой времени обращения
+func (r *sessionImpl) FindByHash(ctx context.Context, hash string) (*model.Session, error) {
+	return r.scanOne(ctx, r.db.QueryRow(ctx,
+		`UPDATE user_sessions SET last_seen_at = $2 WHERE session_hash = $1 AND expires_at > $2
+		RETURNING `+sessionColumns, hash, time.Now().UTC()))
+}
+
+// GetByUser Действующие сессии пользователя
+func (r *sessionImpl) GetByUser(ctx context.Context, userID uint64) (*[]model.Session, error) {
+	rows, err := r.db.Query(ctx,
+		"SELECT "+sessionColumns+" FROM user_sessions WHERE user_id = $1 AND expires_at > $2 ORDER BY id",
+		userID, time.Now().UTC())
+	if err != nil {
+		return nil, dbError(ctx, err, "query error")
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+
+	for rows.Next() {
+		var s model.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt,
+			&s.RemoteAddr, &s.UserAgent); err != nil {
+			return nil, dbError(ctx, err, "rows scan error")
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return &sessions, dbError(ctx, rows.Err(), "rows error")
+}
+
+func (r *sessionImpl) scanOne(ctx context.Context, row pgx.Row) (*model.Session, error) {
+	var s model.Session
+	if err := row.Scan(&s.ID, &s.UserID, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt,
+		&s.RemoteAddr, &s.UserAgent); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, dbError(ctx, err, "QueryRow error")
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,127 @@
+// Package psql Содержит реализацию интерфейса репозитория токенов для postgresql
+package psql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Релизация интерфейса TokenInterface для psql
+type tokenImpl struct {
+	dbImpl *sqlDbImpl
+	db     *pgxpool.Pool
+}
+
+// NewToken Возвращаем интерфейс работы с токенами
+func NewToken(db repository.DBOInterface) repository.TokenInterface { //nolint:ireturn
+	dbImpl, ok := db.(*sqlDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &tokenImpl{
+		dbImpl: dbImpl,
+		db:     dbImpl.db,
+	}
+}
+
+const tokenColumns = "id, user_id, name, created_at, last_used_at"
+
+// Insert Добавить токен
+func (r *tokenImpl) Insert(ctx context.Context, token *model.APIToken, hash string) error {
+	err := r.db.QueryRow(ctx,
+		"INSERT INTO api_tokens (user_id, name, token_hash) VALUES ($1, $2, $3) RETURNING id, created_at",
+		token.UserID, token.Name, hash,
+	).Scan(&token.ID, &token.CreatedAt)
+
+	return dbError(ctx, err, "QueryRow error")
+}
+
+// Remove Удалить токен
+func (r *tokenImpl) Remove(ctx context.Context, tokenID uint64) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM api_tokens WHERE id = $1", tokenID)
+	if err != nil {
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// FindByID Поиск токена по ID
+func (r *tokenImpl) FindByID(ctx context.Context, tokenID uint64) (*model.APIToken, error) {
+	return r.scanOne(ctx, r.db.QueryRow(ctx,
+		"SELECT "+tokenColumns+" FROM api_tokens WHERE id = $1", tokenID))
+}
+
+// FindByHash Поиск токена по хэшу с отметкой времени использования не чаще TokenUsageInterval
+func (r *tokenImpl) FindByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	token, err := r.scanOne(ctx, r.db.QueryRow(ctx,
+		"SELECT "+tokenColumns+" FROM api_tokens WHERE token_hash = $1", hash))
+	if err != nil || token == nil {
+		return token, err
+	}
+
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < repository.TokenUsageInterval {
+		return token, nil
+	}
+
+	// условие повторяется в запросе: при одновременных запросах с токеном время обновит только один из них
+	if _, err := r.db.Exec(ctx,
+		`UPDATE api_tokens SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`,
+		token.ID, now, now.Add(-repository.TokenUsageInterval)); err != nil {
+		return nil, dbError(ctx, err, "Exec error")
+	}
+
+	token.LastUsedAt = &now
+
+	return token, nil
+}
+
+// GetByUser Токены пользователя
+func (r *tokenImpl) GetByUser(ctx context.Context, userID uint64) (*[]model.APIToken, error) {
+	rows, err := r.db.Query(ctx,
+		"SELECT "+tokenColumns+" FROM api_tokens WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, dbError(ctx, err, "query error")
+	}
+	defer rows.Close()
+
+	tokens := []model.APIToken{}
+
+	for rows.Next() {
+		var t model.APIToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.CreatedAt, &t.LastUsedAt); err != nil {
+			return nil, dbError(ctx, err, "rows scan error")
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return &tokens, dbError(ctx, rows.Err(), "rows error")
+}
+
+func (r *tokenImpl) scanOne(ctx context.Context, row pgx.Row) (*model.APIToken, error) {
+	var t model.APIToken
+	if err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.CreatedAt, &t.LastUsedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, dbError(ctx, err, "QueryRow error")
+	}
+
+	return &t, nil
+}
@@ -3,13 +3,13 @@ package psql
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"log"
 	"strings"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"github.com/n-r-w/log-server/internal/app"
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/repository"
@@ -21,11 +21,13 @@ import (
 // Релизация интерфейса UserInterface для psql
 type userImpl struct {
 	dbImpl *sqlDbImpl
+	admin  model.SuperAdmin
+	config *config.Config
 	db     *pgxpool.Pool
 } //nolint:nolintlint,ireturn
 
 // NewUser Возвращаем интерфейс работы с пользователем
-func NewUser(db repository.DBOInterface) repository.UserInterface { //nolint:ireturn
+func NewUser(db repository.DBOInterface, cfg *config.Config) repository.UserInterface { //nolint:ireturn
 	dbImpl, ok := db.(*sqlDbImpl)
 	if !ok {
 		log.Panicln("internal error")
@@ -33,44 +35,49 @@ func NewUser(db repository.DBOInterface) repository.UserInterface { //nolint:ire
 
 	return &userImpl{
 		dbImpl: dbImpl,
+		admin:  cfg.SuperAdmin(),
+		config: cfg,
 		db:     dbImpl.db,
 	}
 }
 
 // Insert Добавить нового пользвателя
-func (r *userImpl) Insert(user *model.User) error {
-	if user.ID == config.AppConfig.SuperAdminID || strings.EqualFold(user.Login, config.AppConfig.SuperAdminLogin) {
+func (r *userImpl) Insert(ctx context.Context, user *model.User) error {
+	if r.admin.IsAdmin(user.ID) || r.admin.IsAdminLogin(user.Login) {
 		return repository.ErrCantChangeAdminUser
 	}
 
-	if err := user.Prepare(true); err != nil {
-		return werrors.Wrap(err, "user prepare error")
+	// проверяем до подготовки, т.к. после нее открытый пароль очищается
+	if err := user.Validate(r.config.Runtime().PasswordPolicy); err != nil {
+		return apperr.Validation(err)
 	}
 
-	if err := user.Validate(); err != nil {
-		return werrors.Wrap(err, "user validate error")
+	if err := user.Prepare(true); err != nil {
+		return werrors.Wrap(err, "user prepare error")
 	}
 
-	err := r.db.QueryRow(context.Background(),
-		"INSERT INTO users (login, name, encrypted_password) VALUES ($1, $2, $3) RETURNING id",
+	err := r.db.QueryRow(ctx,
+		"INSERT INTO users (login, name, role, encrypted_password, timezone) VALUES ($1, $2, $3, $4, $5) RETURNING id",
 		user.Login,
 		user.Name,
+		user.Role,
 		user.EncryptedPassword,
+		user.Timezone,
 	).Scan(&user.ID)
 	if err != nil {
 		if e := pgerror.UniqueViolation(err); e != nil {
 			return repository.ErrLoginExist
 		}
 
-		return werrors.Wrap(err, "QueryRow error")
+		return dbError(ctx, err, "QueryRow error")
 	}
 
-	return werrors.Wrap(err, "QueryRow error")
+	return nil
 }
 
 // ChangePassword Изменить пароль пользователя
-func (r *userImpl) ChangePassword(userID uint64, password string) error {
-	if userID == config.AppConfig.SuperAdminID {
+func (r *userImpl) ChangePassword(ctx context.Context, userID uint64, password string) error {
+	if r.admin.IsAdmin(userID) {
 		return repository.ErrCantChangeAdminPassword
 	}
 
@@ -82,7 +89,7 @@ func (r *userImpl) ChangePassword(userID uint64, password string) error {
 	}
 
 	var user *model.User
-	user, err = r.FindByID(userID)
+	user, err = r.FindByID(ctx, userID)
 
 	if err != nil {
 		return err
@@ -92,88 +99,99 @@ func (r *userImpl) ChangePassword(userID uint64, password string) error {
 		return repository.ErrUserNotFound
 	}
 
+	// проверяем новый пароль, а не сохраненный хэш
 	user.Password = password
-	if err = user.Validate(); err != nil {
-		return werrors.Wrap(err, "Validate error")
+	user.EncryptedPassword = ""
+
+	if err = user.Validate(r.config.Runtime().PasswordPolicy); err != nil {
+		return apperr.Validation(err)
 	}
 
 	if err = user.Prepare(true); err != nil {
 		return werrors.Wrap(err, "Prepare error")
 	}
 
-	_, err = r.db.Exec(context.Background(), "UPDATE users SET encrypted_password=$1 WHERE id=$2", enc, userID)
+	_, err = r.db.Exec(ctx, "UPDATE users SET encrypted_password=$1 WHERE id=$2", enc, userID)
 	if err != nil {
 		if e := pgerror.UniqueViolation(err); e != nil {
 			return repository.ErrLoginExist
 		}
 
-		return werrors.Wrap(err, "Exec error")
+		return dbError(ctx, err, "Exec error")
 	}
 
 	return nil
 }
 
 // FindByID Поиск пользователя по ID
-func (r *userImpl) FindByID(userID uint64) (*model.User, error) {
+func (r *userImpl) FindByID(ctx context.Context, userID uint64) (*model.User, error) {
 	// не админ ли это?
-	if userID == config.AppConfig.SuperAdminID {
-		return model.AdminUser(), nil
+	if r.admin.IsAdmin(userID) {
+		return r.admin.User(), nil
 	}
 
 	u := &model.User{
 		ID:                0,
 		Login:             "",
 		Name:              "",
+		Role:              "",
 		Password:          "",
 		EncryptedPassword: "",
+		Timezone:          "",
 	}
-	if err := r.db.QueryRow(context.Background(),
-		"SELECT id, login, name, encrypted_password FROM users WHERE id = $1",
+	if err := r.db.QueryRow(ctx,
+		"SELECT id, login, name, role, encrypted_password, timezone FROM users WHERE id = $1",
 		userID,
 	).Scan(
 		&u.ID,
 		&u.Login,
 		&u.Name,
+		&u.Role,
 		&u.EncryptedPassword,
+		&u.Timezone,
 	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil //nolint:nilnil
 		}
 
-		return nil, werrors.Wrap(err, "QueryRow error")
+		return nil, dbError(ctx, err, "QueryRow error")
 	}
 
 	return u, nil
 }
 
 // FindByLogin Поиск пользователя по логину
-func (r *userImpl) FindByLogin(login string) (*model.User, error) {
+func (r *userImpl) FindByLogin(ctx context.Context, login string) (*model.User, error) {
 	u := &model.User{
 		ID:                0,
 		Login:             "",
 		Name:              "",
+		Role:              "",
 		Password:          "",
 		EncryptedPassword: "",
+		Timezone:          "",
 	}
 
 	// не админ ли это?
-	if strings.EqualFold(login, config.AppConfig.SuperAdminLogin) {
-		u = model.AdminUser()
+	if r.admin.IsAdminLogin(login) {
+		u = r.admin.User()
 	} else {
-		if err := r.db.QueryRow(context.Background(),
-			"SELECT id, login, name, encrypted_password FROM users WHERE login = $1",
+		if err := r.db.QueryRow(ctx,
+			"SELECT id, login, name, role, encrypted_password, timezone FROM users WHERE login = $1",
 			login,
 		).Scan(
 			&u.ID,
 			&u.Login,
 			&u.Name,
+			&u.Role,
 			&u.EncryptedPassword,
+			&u.Timezone,
 		); err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
+			if errors.Is(err, pgx.ErrNoRows) {
 				return nil, nil // nolint:nilnil
 			}
 
-			return nil, werrors.Wrap(err, "QueryRow error")
+			return nil, dbError(ctx, err, "QueryRow error")
 		}
 	}
 
@@ -181,11 +199,11 @@ func (r *userImpl) FindByLogin(login string) (*model.User, error) {
 }
 
 // GetUsers Получить список пользователей
-func (r *userImpl) GetUsers() (*[]model.User, error) {
-	rows, err := r.db.Query(context.Background(),
-		`SELECT id, login, name, encrypted_password FROM users`)
+func (r *userImpl) GetUsers(ctx context.Context) (*[]model.User, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, login, name, role, encrypted_password, timezone FROM users ORDER BY login`)
 	if err != nil {
-		return nil, werrors.Wrap(err, "query error")
+		return nil, dbError(ctx, err, "query error")
 	}
 	defer rows.Close() // освобождаем контекст sql запроса при выходе
 
@@ -193,10 +211,10 @@ func (r *userImpl) GetUsers() (*[]model.User, error) {
 
 	for rows.Next() {
 		var usr model.User
-		err = rows.Scan(&usr.ID, &usr.Login, &usr.Name, &usr.EncryptedPassword)
+		err = rows.Scan(&usr.ID, &usr.Login, &usr.Name, &usr.Role, &usr.EncryptedPassword, &usr.Timezone)
 
 		if err != nil {
-			return nil, werrors.Wrap(err, "rows scan error")
+			return nil, dbError(ctx, err, "rows scan error")
 		}
 
 		users = append(users, usr)
@@ -207,10 +225,50 @@ func (r *userImpl) GetUsers() (*[]model.User, error) {
 	return &users, nil
 }
 
-func (r *userImpl) Remove(_ uint64) error {
-	return app.ErrNotImplemented
+// Remove Удалить пользователя. Его токены удаляются каскадно, сессии и сохраненные поиски - в том же запросе
+func (r *userImpl) Remove(ctx context.Context, userID uint64) error {
+	if r.admin.IsAdmin(userID) {
+		return repository.ErrCantChangeAdminUser
+	}
+
+	tag, err := r.db.Exec(ctx,
+		`WITH s AS (DELETE FROM user_sessions WHERE user_id = $1),
+		q AS (DELETE FROM saved_searches WHERE user_id = $1)
+		DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
 }
 
-func (r *userImpl) Update(_ *model.User) error {
-	return app.ErrNotImplemented
+// Update Изменить логин, имя, роль и часовой пояс пользователя. Пароль меняется через ChangePassword
+func (r *userImpl) Update(ctx context.Context, user *model.User) error {
+	if r.admin.IsAdmin(user.ID) || r.admin.IsAdminLogin(user.Login) {
+		return repository.ErrCantChangeAdminUser
+	}
+
+	if err := user.Validate(r.config.Runtime().PasswordPolicy); err != nil {
+		return apperr.Validation(err)
+	}
+
+	tag, err := r.db.Exec(ctx, "UPDATE users SET login=$1, name=$2, role=$3, timezone=$4 WHERE id=$5",
+		user.Login, user.Name, user.Role, user.Timezone, user.ID)
+	if err != nil {
+		if e := pgerror.UniqueViolation(err); e != nil {
+			return repository.ErrLoginExist
+		}
+
+		return dbError(ctx, err, "Exec error")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
 }
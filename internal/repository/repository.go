@@ -3,40 +3,119 @@
 package repository
 
 import (
-	"errors"
+	"context"
 	"time"
 
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/domain/model"
 )
 
-// DBOInterface Интерфейс объекта доступа к данным. Не содержит методов кроме закрытия, т.к.
+// DBOInterface Интерфейс объекта доступа к данным. Не содержит методов кроме закрытия и проверки состояния, т.к.
 // внутри него скрывается конкретная реализация доступа, которая не нужна извне
 type DBOInterface interface {
 	Close()
+	// Ping Проверка доступности хранилища
+	Ping(ctx context.Context) error
+	// Stats Состояние пула соединений
+	Stats() model.DBStats
 }
 
-// UserInterface Интерфейс работы с данными пользователей
+// UserInterface Интерфейс работы с данными пользователей.
+// Во все методы передается контекст запроса, при его отмене операция с БД прерывается
 type UserInterface interface {
 	// Insert добавить нового пользователя. ID прописывается в модель
-	Insert(user *model.User) error
-	Remove(userID uint64) error
-	Update(user *model.User) error
-	ChangePassword(userID uint64, password string) error
-
-	FindByID(userID uint64) (*model.User, error)
-	FindByLogin(login string) (*model.User, error)
-	GetUsers() (*[]model.User, error)
+	Insert(ctx context.Context, user *model.User) error
+	Remove(ctx context.Context, userID uint64) error
+	Update(ctx context.Context, user *model.User) error
+	ChangePassword(ctx context.Context, userID uint64, password string) error
+
+	FindByID(ctx context.Context, userID uint64) (*model.User, error)
+	FindByLogin(ctx context.Context, login string) (*model.User, error)
+	GetUsers(ctx context.Context) (*[]model.User, error)
+}
+
+// TokenUsageInterval Время использования токена отмечается не чаще этого интервала, чтобы не писать в БД на каждый запрос
+const TokenUsageInterval = time.Minute
+
+// TokenInterface Интерфейс работы с токенами доступа к API. Хранится только хэш токена
+type TokenInterface interface {
+	// Insert Добавить токен. ID и время создания прописываются в модель
+	Insert(ctx context.Context, token *model.APIToken, hash string) error
+	Remove(ctx context.Context, tokenID uint64) error
+
+	FindByID(ctx context.Context, tokenID uint64) (*model.APIToken, error)
+	// FindByHash Поиск токена по хэшу. Отмечает время использования токена, если оно старше TokenUsageInterval
+	FindByHash(ctx context.Context, hash string) (*model.APIToken, error)
+	// GetByUser Токены пользователя
+	GetByUser(ctx context.Context, userID uint64) (*[]model.APIToken, error)
+}
+
+// SessionInterface Интерфейс работы с сессиями пользователей. Хранится только хэш идентификатора сессии
+type SessionInterface interface {
+	// Insert Добавить сессию. ID и время создания прописываются в модель. Заодно удаляются истекшие сессии пользователя
+	Insert(ctx context.Context, session *model.Session, hash string) error
+	Remove(ctx context.Context, sessionID uint64) error
+	// RemoveByUser Удалить все сессии пользователя, кроме exceptID
+	RemoveByUser(ctx context.Context, userID uint64, exceptID uint64) error
+
+	FindByID(ctx context.Context, sessionID uint64) (*model.Session, error)
+	// FindByHash Поиск действующей сессии по хэшу. Отмечает время последнего обращения
+	FindByHash(ctx context.Context, hash string) (*model.Session, error)
+	// GetByUser Действующие сессии пользователя
+	GetByUser(ctx context.Context, userID uint64) (*[]model.Session, error)
+}
+
+// SearchInterface Интерфейс работы с сохраненными поисками
+type SearchInterface interface {
+	// Insert Добавить поиск. ID и время создания прописываются в модель
+	Insert(ctx context.Context, search *model.SavedSearch) error
+	// Update Изменить название, запрос и признак общего поиска
+	Update(ctx context.Context, search *model.SavedSearch) error
+	Remove(ctx context.Context, searchID uint64) error
+
+	// FindByID Поиск по ID. nil, если не найден
+	FindByID(ctx context.Context, searchID uint64) (*model.SavedSearch, error)
+	// GetByUser Поиски пользователя и общие поиски остальных пользователей, упорядоченные по названию
+	GetByUser(ctx context.Context, userID uint64) (*[]model.SavedSearch, error)
+}
+
+// AlertInterface Интерфейс работы с правилами оповещений
+type AlertInterface interface {
+	// Insert Добавить правило. ID и время создания прописываются в модель
+	Insert(ctx context.Context, rule *model.AlertRule) error
+	Update(ctx context.Context, rule *model.AlertRule) error
+	Remove(ctx context.Context, ruleID uint64) error
+
+	// FindByID Поиск правила по ID. nil, если не найдено
+	FindByID(ctx context.Context, ruleID uint64) (*model.AlertRule, error)
+	// GetAll Все правила, упорядоченные по ID
+	GetAll(ctx context.Context) (*[]model.AlertRule, error)
 }
 
+// LogInterface Интерфейс работы с журналом
 type LogInterface interface {
-	Insert(records *[]model.LogRecord) error
+	Insert(ctx context.Context, records *[]model.LogRecord) error
 
-	Find(dateFrom time.Time, dateTo time.Time, limit int) (records *[]model.LogRecord, limited bool, err error)
+	Find(ctx context.Context, dateFrom time.Time, dateTo time.Time, limit int) (
+		records *[]model.LogRecord, limited bool, err error)
+	// FindPage Страница записей в порядке убывания времени записи и ID, начиная после курсора after
+	// (nil - с начала). more - есть ли записи после страницы
+	FindPage(ctx context.Context, dateFrom time.Time, dateTo time.Time, after *model.LogCursor, limit int) (
+		records *[]model.LogRecord, more bool, err error)
+	// FindNewer Записи, более новые чем курсор, в порядке возрастания времени записи и ID
+	FindNewer(ctx context.Context, before model.LogCursor, limit int) (*[]model.LogRecord, error)
+	// FindByID Поиск записи по ID. nil, если запись не найдена
+	FindByID(ctx context.Context, id uint64) (*model.LogRecord, error)
 }
 
 var (
-	ErrLoginExist              = errors.New("login exist")
-	ErrUserNotFound            = errors.New("user not found")
-	ErrCantChangeAdminPassword = errors.New("can't change admin password")
-	ErrCantChangeAdminUser     = errors.New("can't change admin user")
+	ErrLoginExist              = apperr.New(apperr.KindConflict, "login exist")
+	ErrUserNotFound            = apperr.New(apperr.KindNotFound, "user not found")
+	ErrCantChangeAdminPassword = apperr.New(apperr.KindForbidden, "can't change admin password")
+	ErrCantChangeAdminUser     = apperr.New(apperr.KindForbidden, "can't change admin user")
+	ErrTokenNotFound           = apperr.New(apperr.KindNotFound, "token not found")
+	ErrSessionNotFound         = apperr.New(apperr.KindNotFound, "session not found")
+	ErrLogRecordNotFound       = apperr.New(apperr.KindNotFound, "log record not found")
+	ErrSearchNotFound          = apperr.New(apperr.KindNotFound, "saved search not found")
+	ErrAlertRuleNotFound       = apperr.New(apperr.KindNotFound, "alert rule not found")
 )
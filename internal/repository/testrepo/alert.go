@@ -0,0 +1,117 @@
+package testrepo
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Релизация интерфейса AlertInterface для хранилища в памяти
+type testAlertImpl struct {
+	dbImpl *testDbImpl
+}
+
+// NewAlert Возвращаем интерфейс работы с правилами оповещений
+func NewAlert(db repository.DBOInterface) repository.AlertInterface { //nolint:ireturn
+	dbImpl, ok := db.(*testDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &testAlertImpl{
+		dbImpl: dbImpl,
+	}
+}
+
+// Insert Добавить правило
+func (r *testAlertImpl) Insert(ctx context.Context, rule *model.AlertRule) error {
+	if err := ctx.Err(); err != nil {
+		return apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	if err := rule.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	r.dbImpl.alertMutex.Lock()
+	defer r.dbImpl.alertMutex.Unlock()
+
+	r.dbImpl.alertIDMax++
+	rule.ID = r.dbImpl.alertIDMax
+	rule.CreatedAt = time.Now().UTC()
+
+	rcopy := *rule
+	r.dbImpl.alertByID[rule.ID] = &rcopy
+
+	return nil
+}
+
+// Update Изменить правило
+func (r *testAlertImpl) Update(_ context.Context, rule *model.AlertRule) error {
+	if err := rule.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	r.dbImpl.alertMutex.Lock()
+	defer r.dbImpl.alertMutex.Unlock()
+
+	existing, ok := r.dbImpl.alertByID[rule.ID]
+	if !ok {
+		return repository.ErrAlertRuleNotFound
+	}
+
+	rcopy := *rule
+	rcopy.CreatedAt = existing.CreatedAt
+	r.dbImpl.alertByID[rule.ID] = &rcopy
+
+	return nil
+}
+
+// Remove Удалить правило
+func (r *testAlertImpl) Remove(_ context.Context, ruleID uint64) error {
+	r.dbImpl.alertMutex.Lock()
+	defer r.dbImpl.alertMutex.Unlock()
+
+	if _, ok := r.dbImpl.alertByID[ruleID]; !ok {
+		return repository.ErrAlertRuleNotFound
+	}
+
+	delete(r.dbImpl.alertByID, ruleID)
+
+	return nil
+}
+
+// FindByID Поиск правила по ID
+func (r *testAlertImpl) FindByID(_ context.Context, ruleID uint64) (*model.AlertRule, error) {
+	r.dbImpl.alertMutex.RLock()
+	defer r.dbImpl.alertMutex.RUnlock()
+
+	rule, ok := r.dbImpl.alertByID[ruleID]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	rcopy := *rule
+
+	return &rcopy, nil
+}
+
+// GetAll Все правила
+func (r *testAlertImpl) GetAll(_ context.Context) (*[]model.AlertRule, error) {
+	r.dbImpl.alertMutex.RLock()
+	rules := make([]model.AlertRule, 0, len(r.dbImpl.alertByID))
+
+	for _, rule := range r.dbImpl.alertByID {
+		rules = append(rules, *rule)
+	}
+	r.dbImpl.alertMutex.RUnlock()
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return &rules, nil
+}
@@ -1,6 +1,7 @@
 package testrepo
 
 import (
+	"context"
 	"sync"
 
 	"github.com/n-r-w/log-server/internal/domain/model"
@@ -18,14 +19,34 @@ type testDbImpl struct {
 	logMutex sync.RWMutex
 	logIdMax uint64
 	logByID  map[uint64]*model.LogRecord
+
+	tokenMutex sync.RWMutex
+	tokenIDMax uint64
+	tokenByID  map[uint64]*testToken
+
+	sessionMutex sync.RWMutex
+	sessionIDMax uint64
+	sessionByID  map[uint64]*testSession
+
+	searchMutex sync.RWMutex
+	searchIDMax uint64
+	searchByID  map[uint64]*model.SavedSearch
+
+	alertMutex sync.RWMutex
+	alertIDMax uint64
+	alertByID  map[uint64]*model.AlertRule
 }
 
 func CreateTestlDBO() (repository.DBOInterface, error) { //nolint:ireturn
 	testDB = &testDbImpl{
-		userIdMax: 1,
-		userByID:  make(map[uint64]*model.User),
-		logIdMax:  1,
-		logByID:   make(map[uint64]*model.LogRecord),
+		userIdMax:   1,
+		userByID:    make(map[uint64]*model.User),
+		logIdMax:    0,
+		logByID:     make(map[uint64]*model.LogRecord),
+		tokenByID:   make(map[uint64]*testToken),
+		sessionByID: make(map[uint64]*testSession),
+		searchByID:  make(map[uint64]*model.SavedSearch),
+		alertByID:   make(map[uint64]*model.AlertRule),
 	}
 
 	return testDB, nil
@@ -35,3 +56,18 @@ func CreateTestlDBO() (repository.DBOInterface, error) { //nolint:ireturn
 //goland:noinspection GoUnnecessarilyExportedIdentifiers
 func (d *testDbImpl) Close() {
 }
+
+// Ping Проверка доступности хранилища. Хранилище в памяти доступно всегда
+func (d *testDbImpl) Ping(ctx context.Context) error {
+	return ctx.Err() //nolint:wrapcheck
+}
+
+// Stats Состояние пула соединений. У хранилища в памяти соединений нет
+func (d *testDbImpl) Stats() model.DBStats {
+	return model.DBStats{
+		TotalConns:    0,
+		IdleConns:     0,
+		AcquiredConns: 0,
+		MaxConns:      0,
+	}
+}
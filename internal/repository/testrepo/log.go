@@ -1,9 +1,12 @@
 package testrepo
 
 import (
+	"context"
 	"log"
+	"sort"
 	"time"
 
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/repository"
 )
@@ -25,21 +28,31 @@ func NewLog(db repository.DBOInterface) repository.LogInterface { //nolint:iretu
 	}
 }
 
-func (p *testLogImpl) Insert(records *[]model.LogRecord) error {
+func (p *testLogImpl) Insert(ctx context.Context, records *[]model.LogRecord) error {
+	if err := ctx.Err(); err != nil {
+		return apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
 	p.dbImpl.logMutex.Lock()
 	for _, record := range *records {
 		// если тут не делать копию, то в мапе всегда окажется последняя запись
 		rcopy := record
-		p.dbImpl.logByID[p.dbImpl.logIdMax] = &rcopy
 		p.dbImpl.logIdMax++
 		rcopy.ID = p.dbImpl.logIdMax
+		p.dbImpl.logByID[rcopy.ID] = &rcopy
 	}
 	p.dbImpl.logMutex.Unlock()
 
 	return nil
 }
 
-func (p *testLogImpl) Find(dateFrom time.Time, dateTo time.Time, limit int) (records *[]model.LogRecord, limited bool, err error) {
+func (p *testLogImpl) Find(ctx context.Context, dateFrom time.Time, dateTo time.Time, limit int) (
+	records *[]model.LogRecord, limited bool, err error,
+) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
 	recs := make([]model.LogRecord, 0, 100)
 
 	p.dbImpl.logMutex.RLock()
@@ -52,12 +65,112 @@ func (p *testLogImpl) Find(dateFrom time.Time, dateTo time.Time, limit int) (rec
 			continue
 		}
 
-		recs = append(recs, *r)
 		if len(recs) >= limit {
+			limited = true
+
 			break
 		}
+
+		recs = append(recs, *r)
+	}
+	p.dbImpl.logMutex.RUnlock()
+
+	return &recs, limited, nil
+}
+
+func (p *testLogImpl) FindPage(ctx context.Context, dateFrom time.Time, dateTo time.Time, after *model.LogCursor,
+	limit int,
+) (records *[]model.LogRecord, more bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	recs := make([]model.LogRecord, 0, limit)
+
+	p.dbImpl.logMutex.RLock()
+	for _, r := range p.dbImpl.logByID {
+		if !dateFrom.IsZero() && r.LogTime.Before(dateFrom) {
+			continue
+		}
+
+		if !dateTo.IsZero() && r.LogTime.After(dateTo) {
+			continue
+		}
+
+		if after != nil && !logBefore(r, after) {
+			continue
+		}
+
+		recs = append(recs, *r)
+	}
+	p.dbImpl.logMutex.RUnlock()
+
+	// как и в psql, по убыванию времени записи и ID
+	sort.Slice(recs, func(i, j int) bool {
+		return logBefore(&recs[j], &model.LogCursor{LogTime: recs[i].LogTime, ID: recs[i].ID})
+	})
+
+	if len(recs) > limit {
+		recs = recs[:limit]
+		more = true
+	}
+
+	return &recs, more, nil
+}
+
+func (p *testLogImpl) FindNewer(ctx context.Context, before model.LogCursor, limit int) (*[]model.LogRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	recs := make([]model.LogRecord, 0, limit)
+
+	p.dbImpl.logMutex.RLock()
+	for _, r := range p.dbImpl.logByID {
+		// сама запись курсора и более старые
+		if (r.LogTime.Equal(before.LogTime) && r.ID == before.ID) || logBefore(r, &before) {
+			continue
+		}
+
+		recs = append(recs, *r)
 	}
 	p.dbImpl.logMutex.RUnlock()
 
-	return &recs, false, nil
+	// как и в psql, по возрастанию времени записи и ID
+	sort.Slice(recs, func(i, j int) bool {
+		return logBefore(&recs[i], &model.LogCursor{LogTime: recs[j].LogTime, ID: recs[j].ID})
+	})
+
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+
+	return &recs, nil
+}
+
+func (p *testLogImpl) FindByID(ctx context.Context, id uint64) (*model.LogRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	p.dbImpl.logMutex.RLock()
+	defer p.dbImpl.logMutex.RUnlock()
+
+	r, ok := p.dbImpl.logByID[id]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	rcopy := *r
+
+	return &rcopy, nil
+}
+
+// Находится ли запись после курсора в порядке убывания времени записи и ID
+func logBefore(r *model.LogRecord, c *model.LogCursor) bool {
+	if !r.LogTime.Equal(c.LogTime) {
+		return r.LogTime.Before(c.LogTime)
+	}
+
+	return r.ID < c.ID
 }
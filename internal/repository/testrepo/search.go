@@ -0,0 +1,127 @@
+package testrepo
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Релизация интерфейса SearchInterface для хранилища в памяти
+type testSearchImpl struct {
+	dbImpl *testDbImpl
+}
+
+// NewSearch Возвращаем интерфейс работы с сохраненными поисками
+func NewSearch(db repository.DBOInterface) repository.SearchInterface { //nolint:ireturn
+	dbImpl, ok := db.(*testDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &testSearchImpl{
+		dbImpl: dbImpl,
+	}
+}
+
+// Insert Добавить поиск
+func (r *testSearchImpl) Insert(ctx context.Context, search *model.SavedSearch) error {
+	if err := ctx.Err(); err != nil {
+		return apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	if err := search.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	r.dbImpl.searchMutex.Lock()
+	defer r.dbImpl.searchMutex.Unlock()
+
+	r.dbImpl.searchIDMax++
+	search.ID = r.dbImpl.searchIDMax
+	search.CreatedAt = time.Now().UTC()
+
+	s := *search
+	r.dbImpl.searchByID[s.ID] = &s
+
+	return nil
+}
+
+// Update Изменить поиск
+func (r *testSearchImpl) Update(_ context.Context, search *model.SavedSearch) error {
+	if err := search.Validate(); err != nil {
+		return apperr.Validation(err)
+	}
+
+	r.dbImpl.searchMutex.Lock()
+	defer r.dbImpl.searchMutex.Unlock()
+
+	s, ok := r.dbImpl.searchByID[search.ID]
+	if !ok {
+		return repository.ErrSearchNotFound
+	}
+
+	s.Name = search.Name
+	s.Query = search.Query
+	s.Shared = search.Shared
+
+	return nil
+}
+
+// Remove Удалить поиск
+func (r *testSearchImpl) Remove(_ context.Context, searchID uint64) error {
+	r.dbImpl.searchMutex.Lock()
+	defer r.dbImpl.searchMutex.Unlock()
+
+	if _, ok := r.dbImpl.searchByID[searchID]; !ok {
+		return repository.ErrSearchNotFound
+	}
+
+	delete(r.dbImpl.searchByID, searchID)
+
+	return nil
+}
+
+// FindByID Поиск по ID
+func (r *testSearchImpl) FindByID(_ context.Context, searchID uint64) (*model.SavedSearch, error) {
+	r.dbImpl.searchMutex.RLock()
+	defer r.dbImpl.searchMutex.RUnlock()
+
+	s, ok := r.dbImpl.searchByID[searchID]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	search := *s
+
+	return &search, nil
+}
+
+// GetByUser Поиски пользователя и общие поиски остальных пользователей
+func (r *testSearchImpl) GetByUser(_ context.Context, userID uint64) (*[]model.SavedSearch, error) {
+	r.dbImpl.searchMutex.RLock()
+	searches := []model.SavedSearch{}
+
+	for _, s := range r.dbImpl.searchByID {
+		if s.UserID == userID || s.Shared {
+			searches = append(searches, *s)
+		}
+	}
+	r.dbImpl.searchMutex.RUnlock()
+
+	sort.Slice(searches, func(i, j int) bool {
+		a, b := strings.ToLower(searches[i].Name), strings.ToLower(searches[j].Name)
+		if a == b {
+			return searches[i].ID < searches[j].ID
+		}
+
+		return a < b
+	})
+
+	return &searches, nil
+}
@@ -0,0 +1,150 @@
+package testrepo
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Сессия вместе с хэшем
+type testSession struct {
+	session model.Session
+	hash    string
+}
+
+// Релизация интерфейса SessionInterface для хранилища в памяти
+type testSessionImpl struct {
+	dbImpl *testDbImpl
+}
+
+// NewSession Возвращаем интерфейс работы с сессиями
+func NewSession(db repository.DBOInterface) repository.SessionInterface { //nolint:ireturn
+	dbImpl, ok := db.(*testDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &testSessionImpl{
+		dbImpl: dbImpl,
+	}
+}
+
+// Insert Добавить сессию
+func (r *testSessionImpl) Insert(ctx context.Context, session *model.Session, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	now := time.Now().UTC()
+
+	r.dbImpl.sessionMutex.Lock()
+	defer r.dbImpl.sessionMutex.Unlock()
+
+	for id, s := range r.dbImpl.sessionByID {
+		if s.session.UserID == session.UserID && !s.session.ExpiresAt.After(now) {
+			delete(r.dbImpl.sessionByID, id)
+		}
+	}
+
+	r.dbImpl.sessionIDMax++
+	session.ID = r.dbImpl.sessionIDMax
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	r.dbImpl.sessionByID[session.ID] = &testSession{
+		session: *session,
+		hash:    hash,
+	}
+
+	return nil
+}
+
+// Remove Удалить сессию
+func (r *testSessionImpl) Remove(_ context.Context, sessionID uint64) error {
+	r.dbImpl.sessionMutex.Lock()
+	defer r.dbImpl.sessionMutex.Unlock()
+
+	if _, ok := r.dbImpl.sessionByID[sessionID]; !ok {
+		return repository.ErrSessionNotFound
+	}
+
+	delete(r.dbImpl.sessionByID, sessionID)
+
+	return nil
+}
+
+// RemoveByUser Удалить все сессии пользователя, кроме exceptID
+func (r *testSessionImpl) RemoveByUser(_ context.Context, userID uint64, exceptID uint64) error {
+	r.dbImpl.removeSessions(userID, exceptID)
+
+	return nil
+}
+
+// FindByID Поиск сессии по ID
+func (r *testSessionImpl) FindByID(_ context.Context, sessionID uint64) (*model.Session, error) {
+	r.dbImpl.sessionMutex.RLock()
+	defer r.dbImpl.sessionMutex.RUnlock()
+
+	s, ok := r.dbImpl.sessionByID[sessionID]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	session := s.session
+
+	return &session, nil
+}
+
+// FindByHash Поиск действующей сессии по хэшу с отметкой времени обращения
+func (r *testSessionImpl) FindByHash(_ context.Context, hash string) (*model.Session, error) {
+	now := time.Now().UTC()
+
+	r.dbImpl.sessionMutex.Lock()
+	defer r.dbImpl.sessionMutex.Unlock()
+
+	for _, s := range r.dbImpl.sessionByID {
+		if s.hash == hash && s.session.ExpiresAt.After(now) {
+			s.session.LastSeenAt = now
+			session := s.session
+
+			return &session, nil
+		}
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// GetByUser Действующие сессии пользователя
+func (r *testSessionImpl) GetByUser(_ context.Context, userID uint64) (*[]model.Session, error) {
+	now := time.Now().UTC()
+
+	r.dbImpl.sessionMutex.RLock()
+	sessions := []model.Session{}
+
+	for _, s := range r.dbImpl.sessionByID {
+		if s.session.UserID == userID && s.session.ExpiresAt.After(now) {
+			sessions = append(sessions, s.session)
+		}
+	}
+	r.dbImpl.sessionMutex.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+
+	return &sessions, nil
+}
+
+// Удалить сессии пользователя, кроме exceptID
+func (d *testDbImpl) removeSessions(userID uint64, exceptID uint64) {
+	d.sessionMutex.Lock()
+	defer d.sessionMutex.Unlock()
+
+	for id, s := range d.sessionByID {
+		if s.session.UserID == userID && id != exceptID {
+			delete(d.sessionByID, id)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package testrepo
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/app/apperr"
+	"github.com/n-r-w/log-server/internal/domain/model"
+	"github.com/n-r-w/log-server/internal/repository"
+)
+
+// Токен вместе с хэшем
+type testToken struct {
+	token model.APIToken
+	hash  string
+}
+
+// Релизация интерфейса TokenInterface для хранилища в памяти
+type testTokenImpl struct {
+	dbImpl *testDbImpl
+}
+
+// NewToken Возвращаем интерфейс работы с токенами
+func NewToken(db repository.DBOInterface) repository.TokenInterface { //nolint:ireturn
+	dbImpl, ok := db.(*testDbImpl)
+	if !ok {
+		log.Panicln("internal error")
+	}
+
+	return &testTokenImpl{
+		dbImpl: dbImpl,
+	}
+}
+
+// Insert Добавить токен
+func (r *testTokenImpl) Insert(ctx context.Context, token *model.APIToken, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	r.dbImpl.tokenMutex.Lock()
+	defer r.dbImpl.tokenMutex.Unlock()
+
+	r.dbImpl.tokenIDMax++
+	token.ID = r.dbImpl.tokenIDMax
+	token.CreatedAt = time.Now().UTC()
+	r.dbImpl.tokenByID[token.ID] = &testToken{
+		token: *token,
+		hash:  hash,
+	}
+
+	return nil
+}
+
+// Remove Удалить токен
+func (r *testTokenImpl) Remove(_ context.Context, tokenID uint64) error {
+	r.dbImpl.tokenMutex.Lock()
+	defer r.dbImpl.tokenMutex.Unlock()
+
+	if _, ok := r.dbImpl.tokenByID[tokenID]; !ok {
+		return repository.ErrTokenNotFound
+	}
+
+	delete(r.dbImpl.tokenByID, tokenID)
+
+	return nil
+}
+
+// FindByID Поиск токена по ID
+func (r *testTokenImpl) FindByID(_ context.Context, tokenID uint64) (*model.APIToken, error) {
+	r.dbImpl.tokenMutex.RLock()
+	defer r.dbImpl.tokenMutex.RUnlock()
+
+	t, ok := r.dbImpl.tokenByID[tokenID]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	token := t.token
+
+	return &token, nil
+}
+
+// FindByHash Поиск токена по хэшу с отметкой времени использования не чаще TokenUsageInterval
+func (r *testTokenImpl) FindByHash(_ context.Context, hash string) (*model.APIToken, error) {
+	r.dbImpl.tokenMutex.Lock()
+	defer r.dbImpl.tokenMutex.Unlock()
+
+	for _, t := range r.dbImpl.tokenByID {
+		if t.hash == hash {
+			now := time.Now().UTC()
+			if t.token.LastUsedAt == nil || now.Sub(*t.token.LastUsedAt) >= repository.TokenUsageInterval {
+				t.token.LastUsedAt = &now
+			}
+
+			token := t.token
+
+			return &token, nil
+		}
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// GetByUser Токены пользователя
+func (r *testTokenImpl) GetByUser(_ context.Context, userID uint64) (*[]model.APIToken, error) {
+	r.dbImpl.tokenMutex.RLock()
+	tokens := []model.APIToken{}
+
+	for _, t := range r.dbImpl.tokenByID {
+		if t.token.UserID == userID {
+			tokens = append(tokens, t.token)
+		}
+	}
+	r.dbImpl.tokenMutex.RUnlock()
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+
+	return &tokens, nil
+}
@@ -1,9 +1,12 @@
 package testrepo
 
 import (
+	"context"
 	"log"
+	"sort"
 	"strings"
 
+	"github.com/n-r-w/log-server/internal/app/apperr"
 	"github.com/n-r-w/log-server/internal/app/config"
 	"github.com/n-r-w/log-server/internal/domain/model"
 	"github.com/n-r-w/log-server/internal/repository"
@@ -13,10 +16,12 @@ import (
 // Релизация интерфейса UserInterface для psql
 type testUserImpl struct {
 	dbImpl *testDbImpl
+	admin  model.SuperAdmin
+	config *config.Config
 } //nolint:nolintlint,ireturn
 
 // NewUser Возвращаем интерфейс работы с пользователем
-func NewUser(db repository.DBOInterface) repository.UserInterface { //nolint:ireturn
+func NewUser(db repository.DBOInterface, cfg *config.Config) repository.UserInterface { //nolint:ireturn
 	dbImpl, ok := db.(*testDbImpl)
 	if !ok {
 		log.Panicln("internal error")
@@ -24,41 +29,57 @@ func NewUser(db repository.DBOInterface) repository.UserInterface { //nolint:ire
 
 	return &testUserImpl{
 		dbImpl: dbImpl,
+		admin:  cfg.SuperAdmin(),
+		config: cfg,
 	}
 }
 
 // Insert Добавить нового пользвателя
-func (r *testUserImpl) Insert(user *model.User) error {
-	if user.ID == config.AppConfig.SuperAdminID || strings.EqualFold(user.Login, config.AppConfig.SuperAdminLogin) {
+func (r *testUserImpl) Insert(ctx context.Context, user *model.User) error {
+	if err := ctx.Err(); err != nil {
+		return apperr.Wrap(apperr.KindUnavailable, err, "context error")
+	}
+
+	if r.admin.IsAdmin(user.ID) || r.admin.IsAdminLogin(user.Login) {
 		return repository.ErrCantChangeAdminUser
 	}
 
+	// проверяем до подготовки, т.к. после нее открытый пароль очищается
+	if err := user.Validate(r.config.Runtime().PasswordPolicy); err != nil {
+		return apperr.Validation(err)
+	}
+
 	if err := user.Prepare(true); err != nil {
 		return werrors.Wrap(err, "user prepare error")
 	}
 
-	if err := user.Validate(); err != nil {
-		return werrors.Wrap(err, "user validate error")
+	r.dbImpl.userMutex.Lock()
+	defer r.dbImpl.userMutex.Unlock()
+
+	// аналог unique constraint в БД
+	for _, u := range r.dbImpl.userByID {
+		if u.Login == user.Login {
+			return repository.ErrLoginExist
+		}
 	}
 
-	r.dbImpl.userMutex.Lock()
 	r.dbImpl.userIdMax++
-	r.dbImpl.userByID[r.dbImpl.userIdMax] = user
 	user.ID = r.dbImpl.userIdMax
-	r.dbImpl.userMutex.Unlock()
+	ucopy := *user
+	r.dbImpl.userByID[user.ID] = &ucopy
 
 	return nil
 }
 
 // ChangePassword Изменить пароль пользователя
-func (r *testUserImpl) ChangePassword(userID uint64, password string) error {
-	if userID == config.AppConfig.SuperAdminID {
+func (r *testUserImpl) ChangePassword(ctx context.Context, userID uint64, password string) error {
+	if r.admin.IsAdmin(userID) {
 		return repository.ErrCantChangeAdminPassword
 	}
 
 	password = strings.TrimSpace(password)
 
-	user, err := r.FindByID(userID)
+	user, err := r.FindByID(ctx, userID)
 
 	if err != nil {
 		return err
@@ -68,35 +89,63 @@ func (r *testUserImpl) ChangePassword(userID uint64, password string) error {
 		return repository.ErrUserNotFound
 	}
 
-	user.Password = password
-	if err = user.Validate(); err != nil {
-		return werrors.Wrap(err, "Validate error")
+	// проверяем новый пароль, а не сохраненный хэш
+	changed := *user
+	changed.Password = password
+	changed.EncryptedPassword = ""
+
+	if err = changed.Validate(r.config.Runtime().PasswordPolicy); err != nil {
+		return apperr.Validation(err)
+	}
+
+	if err = changed.Prepare(true); err != nil {
+		return werrors.Wrap(err, "user prepare error")
 	}
 
+	r.dbImpl.userMutex.Lock()
+	r.dbImpl.userByID[userID] = &changed
+	r.dbImpl.userMutex.Unlock()
+
 	return nil
 }
 
 // FindByID Поиск пользователя по ID
-func (r *testUserImpl) FindByID(userID uint64) (*model.User, error) {
+func (r *testUserImpl) FindByID(_ context.Context, userID uint64) (*model.User, error) {
 	// не админ ли это?
-	if userID == config.AppConfig.SuperAdminID {
-		return model.AdminUser(), nil
+	if r.admin.IsAdmin(userID) {
+		return r.admin.User(), nil
+	}
+
+	r.dbImpl.userMutex.RLock()
+	defer r.dbImpl.userMutex.RUnlock()
+
+	u, ok := r.dbImpl.userByID[userID]
+	if !ok {
+		return nil, nil //nolint:nilnil
 	}
 
-	return r.dbImpl.userByID[userID], nil
+	// копия, чтобы изменения модели не попадали в хранилище в обход Update
+	ucopy := *u
+
+	return &ucopy, nil
 }
 
 // FindByLogin Поиск пользователя по логину
-func (r *testUserImpl) FindByLogin(login string) (*model.User, error) {
+func (r *testUserImpl) FindByLogin(_ context.Context, login string) (*model.User, error) {
 
 	// не админ ли это?
-	if strings.EqualFold(login, config.AppConfig.SuperAdminLogin) {
-		return model.AdminUser(), nil
+	if r.admin.IsAdminLogin(login) {
+		return r.admin.User(), nil
 	}
 
+	r.dbImpl.userMutex.RLock()
+	defer r.dbImpl.userMutex.RUnlock()
+
 	for _, u := range r.dbImpl.userByID {
 		if u.Login == login {
-			return u, nil
+			ucopy := *u
+
+			return &ucopy, nil
 		}
 	}
 
@@ -104,31 +153,80 @@ func (r *testUserImpl) FindByLogin(login string) (*model.User, error) {
 }
 
 // GetUsers Получить список пользователей
-func (r *testUserImpl) GetUsers() (*[]model.User, error) {
+func (r *testUserImpl) GetUsers(_ context.Context) (*[]model.User, error) {
+	r.dbImpl.userMutex.RLock()
 	users := make([]model.User, 0, len(r.dbImpl.userByID))
 	for _, u := range r.dbImpl.userByID {
 		users = append(users, *u)
 	}
+	r.dbImpl.userMutex.RUnlock()
+
+	// как и в psql, сортировка по логину
+	sort.Slice(users, func(i, j int) bool { return users[i].Login < users[j].Login })
 
 	return &users, nil
 }
 
-func (r *testUserImpl) Remove(id uint64) error {
-	if u, _ := r.FindByID(id); u == nil {
+// Remove Удалить пользователя вместе с его токенами, сессиями и сохраненными поисками
+func (r *testUserImpl) Remove(ctx context.Context, id uint64) error {
+	if r.admin.IsAdmin(id) {
+		return repository.ErrCantChangeAdminUser
+	}
+
+	if u, _ := r.FindByID(ctx, id); u == nil {
 		return repository.ErrUserNotFound
 	}
 
+	r.dbImpl.userMutex.Lock()
 	delete(r.dbImpl.userByID, id)
+	r.dbImpl.userMutex.Unlock()
+
+	r.dbImpl.tokenMutex.Lock()
+	for tokenID, t := range r.dbImpl.tokenByID {
+		if t.token.UserID == id {
+			delete(r.dbImpl.tokenByID, tokenID)
+		}
+	}
+	r.dbImpl.tokenMutex.Unlock()
+
+	r.dbImpl.removeSessions(id, 0)
+
+	r.dbImpl.searchMutex.Lock()
+	for searchID, s := range r.dbImpl.searchByID {
+		if s.UserID == id {
+			delete(r.dbImpl.searchByID, searchID)
+		}
+	}
+	r.dbImpl.searchMutex.Unlock()
 
 	return nil
 }
 
-func (r *testUserImpl) Update(user *model.User) error {
-	if u, _ := r.FindByID(user.ID); u == nil {
+// Update Изменить логин, имя, роль и часовой пояс пользователя
+func (r *testUserImpl) Update(ctx context.Context, user *model.User) error {
+	if r.admin.IsAdmin(user.ID) || r.admin.IsAdminLogin(user.Login) {
+		return repository.ErrCantChangeAdminUser
+	}
+
+	if err := user.Validate(r.config.Runtime().PasswordPolicy); err != nil {
+		return apperr.Validation(err)
+	}
+
+	if u, _ := r.FindByID(ctx, user.ID); u == nil {
 		return repository.ErrUserNotFound
 	}
 
-	r.dbImpl.userByID[user.ID] = user
+	r.dbImpl.userMutex.Lock()
+	defer r.dbImpl.userMutex.Unlock()
+
+	for _, u := range r.dbImpl.userByID {
+		if u.Login == user.Login && u.ID != user.ID {
+			return repository.ErrLoginExist
+		}
+	}
+
+	updated := *user
+	r.dbImpl.userByID[user.ID] = &updated
 
 	return nil
 }
@@ -0,0 +1,117 @@
+// Package testserver Сборка изолированных экземпляров сервера для тестов. Каждый экземпляр
+// имеет собственную конфигурацию и хранилище в оперативной памяти, поэтому в одном процессе
+// можно одновременно использовать несколько серверов с разными настройками
+package testserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+	"github.com/n-r-w/log-server/internal/app/alerting"
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/app/notify"
+	"github.com/n-r-w/log-server/internal/domain"
+	"github.com/n-r-w/log-server/internal/domain/usecase"
+	"github.com/n-r-w/log-server/internal/presentation/httprouter"
+	"github.com/n-r-w/log-server/internal/repository"
+	"github.com/n-r-w/log-server/internal/repository/testrepo"
+	"github.com/stretchr/testify/require"
+)
+
+// Server Экземпляр сервера для тестов
+type Server struct {
+	Config      *config.Config
+	Router      *httprouter.HTTPRouter
+	Domain      *domain.Domain
+	DBO         repository.DBOInterface
+	UserRepo    repository.UserInterface
+	LogRepo     repository.LogInterface
+	TokenRepo   repository.TokenInterface
+	SessionRepo repository.SessionInterface
+	SearchRepo  repository.SearchInterface
+	AlertRepo   repository.AlertInterface
+}
+
+// New Создание сервера с конфигурацией по умолчанию. options изменяют конфигурацию до создания сервера
+func New(t testing.TB, options ...func(cfg *config.Config)) *Server {
+	t.Helper()
+
+	cfg := config.Default()
+	for _, option := range options {
+		option(cfg)
+	}
+
+	// фейковая БД
+	dbo, err := testrepo.CreateTestlDBO()
+	require.NoError(t, err)
+
+	// репозитории
+	userRepo := testrepo.NewUser(dbo, cfg)
+	logRepo := testrepo.NewLog(dbo)
+	tokenRepo := testrepo.NewToken(dbo)
+	sessionRepo := testrepo.NewSession(dbo)
+	searchRepo := testrepo.NewSearch(dbo)
+	alertRepo := testrepo.NewAlert(dbo)
+
+	// сценарии
+	userCase := usecase.NewUserCase(userRepo, sessionRepo, cfg)
+	senders, err := notify.New(cfg.NotifyChannels)
+	require.NoError(t, err)
+
+	// очередь уведомлений не обрабатывается, тестовые уведомления отправляются синхронно
+	notifyCase := usecase.NewNotifyCase(senders, cfg)
+	alertCase := usecase.NewAlertCase(alertRepo, alerting.Notifiers{alerting.LogNotifier{}, notifyCase})
+	logCase := usecase.NewLogCase(logRepo, cfg, alertCase)
+	healthCase := usecase.NewHealthCase(dbo, logCase)
+	tokenCase := usecase.NewTokenCase(tokenRepo, userRepo, cfg)
+	sessionCase := usecase.NewSessionCase(sessionRepo, userRepo, cfg)
+	searchCase := usecase.NewSearchCase(searchRepo)
+
+	dom := domain.NewDomain(logCase, userCase, healthCase, tokenCase, sessionCase, searchCase, alertCase, notifyCase)
+
+	t.Cleanup(dbo.Close)
+
+	return &Server{
+		Config:      cfg,
+		Router:      httprouter.NewRouter(dom, sessions.NewCookieStore([]byte(cfg.SessionEncriptionKey)), cfg),
+		Domain:      dom,
+		DBO:         dbo,
+		UserRepo:    userRepo,
+		LogRepo:     logRepo,
+		TokenRepo:   tokenRepo,
+		SessionRepo: sessionRepo,
+		SearchRepo:  searchRepo,
+		AlertRepo:   alertRepo,
+	}
+}
+
+// SessionCookie Куки новой сессии пользователя userID
+func (s *Server) SessionCookie(t testing.TB, userID uint64) *http.Cookie {
+	t.Helper()
+
+	secret, err := s.Domain.SessionUsecase.Create(context.Background(), userID, "", "")
+	require.NoError(t, err)
+
+	sc := securecookie.New([]byte(s.Config.SessionEncriptionKey), nil)
+	value, err := sc.Encode(httprouter.SessionName, map[interface{}]interface{}{
+		httprouter.SessionKeyName: secret,
+	})
+	require.NoError(t, err)
+
+	return &http.Cookie{ //nolint:exhaustivestruct,exhaustruct
+		Name:  httprouter.SessionName,
+		Value: value,
+	}
+}
+
+// Serve Выполнить запрос
+func (s *Server) Serve(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	return rec
+}
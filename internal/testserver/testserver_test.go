@@ -0,0 +1,31 @@
+package testserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/log-server/internal/app/config"
+	"github.com/n-r-w/log-server/internal/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+// Два сервера с разными настройками в одном процессе не влияют друг на друга
+func TestNew_Isolated(t *testing.T) {
+	strict := testserver.New(t, func(cfg *config.Config) {
+		cfg.PasswordRegex = "^.{8,}$"
+	})
+	relaxed := testserver.New(t)
+
+	addUser := func(s *testserver.Server) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/private/add-user",
+			strings.NewReader(`{"login": "user", "name": "user", "password": "123"}`))
+		req.AddCookie(s.SessionCookie(t, s.Config.SuperAdminID))
+
+		return s.Serve(req).Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, addUser(strict))
+	assert.Equal(t, http.StatusCreated, addUser(relaxed))
+}
@@ -51,3 +51,22 @@ func LogIf(err error, msg string) error {
 
 	return err
 }
+
+// Первые символы значений, которые табличные редакторы считают формулой
+const csvFormulaChars = "=+-@\t\r"
+
+// EscapeCSVFormula Защита от CSV injection: значение, которое табличный редактор принял бы за формулу
+// (начинается с = + - @, табуляции или возврата каретки), экранируется апострофом.
+// Значение, которое само начинается с апострофа, тоже экранируется, чтобы преобразование было обратимым
+func EscapeCSVFormula(s string) string {
+	if s != "" && (s[0] == '\'' || strings.IndexByte(csvFormulaChars, s[0]) >= 0) {
+		return "'" + s
+	}
+
+	return s
+}
+
+// UnescapeCSVFormula Обратное преобразование к EscapeCSVFormula: удаляется один апостроф в начале значения
+func UnescapeCSVFormula(s string) string {
+	return strings.TrimPrefix(s, "'")
+}
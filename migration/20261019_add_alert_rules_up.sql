@@ -0,0 +1,15 @@
+CREATE TABLE alert_rules (
+  id bigserial not null primary key,
+  name text not null,
+  -- минимальный уровень записи (0 - любой)
+  min_level integer not null default 0,
+  -- регулярное выражение для message1, message2 и message3 (пустое - любая запись)
+  pattern text not null default '',
+  -- правило срабатывает, если в окне больше threshold записей
+  threshold integer not null,
+  window_sec integer not null,
+  enabled boolean not null default true,
+  -- до этого момента уведомления не отправляются
+  silenced_until timestamp with time zone,
+  created_at timestamp with time zone not null default now()
+);
@@ -0,0 +1 @@
+DROP INDEX idx_log_record_timestamp_id;
@@ -0,0 +1,5 @@
+-- постраничное чтение журнала по (record_timestamp, id) в порядке убывания
+CREATE INDEX idx_log_record_timestamp_id
+    ON public.log USING btree
+    (record_timestamp DESC, id DESC)
+;
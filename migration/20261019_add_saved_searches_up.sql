@@ -0,0 +1,15 @@
+-- сохраненные поиски встроенного админа (id 1) тоже хранятся здесь, поэтому без внешнего ключа на users
+CREATE TABLE saved_searches (
+  id bigserial not null primary key,
+  user_id bigint not null,
+  name text not null,
+  -- строка запроса страницы поиска (from, to, tz)
+  query text not null,
+  -- общий поиск виден всем пользователям
+  shared boolean not null default false,
+  created_at timestamp with time zone not null default now()
+);
+CREATE INDEX idx_saved_searches_user_id
+    ON saved_searches USING btree
+    (user_id ASC NULLS LAST)
+;
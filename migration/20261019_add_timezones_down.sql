@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN timezone;
+ALTER TABLE log
+  ALTER COLUMN record_timestamp TYPE timestamp without time zone USING record_timestamp AT TIME ZONE 'UTC',
+  ALTER COLUMN real_timestamp TYPE timestamp without time zone USING real_timestamp AT TIME ZONE 'UTC'
+;
@@ -0,0 +1,7 @@
+-- время записей хранится с часовым поясом. Раньше сервер записывал время записей в UTC
+ALTER TABLE log
+  ALTER COLUMN record_timestamp TYPE timestamp with time zone USING record_timestamp AT TIME ZONE 'UTC',
+  ALTER COLUMN real_timestamp TYPE timestamp with time zone USING real_timestamp AT TIME ZONE 'UTC'
+;
+-- часовой пояс веб интерфейса пользователя. Пустой - часовой пояс браузера
+ALTER TABLE users ADD COLUMN timezone text NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN role text NOT NULL DEFAULT 'user';
+
+CREATE TABLE api_tokens (
+  id bigserial not null primary key,
+  user_id bigint not null references users (id) on delete cascade,
+  name text not null,
+  -- sha256 от токена, сам токен не хранится
+  token_hash text not null unique,
+  created_at timestamp without time zone not null default now(),
+  last_used_at timestamp without time zone
+);
+CREATE INDEX idx_api_tokens_user_id
+    ON api_tokens USING btree
+    (user_id ASC NULLS LAST)
+;
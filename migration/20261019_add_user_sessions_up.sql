@@ -0,0 +1,16 @@
+-- сессии встроенного админа (id 1) тоже хранятся здесь, поэтому без внешнего ключа на users
+CREATE TABLE user_sessions (
+  id bigserial not null primary key,
+  user_id bigint not null,
+  -- sha256 от идентификатора сессии, сам идентификатор хранится только в куки
+  session_hash text not null unique,
+  created_at timestamp without time zone not null default now(),
+  last_seen_at timestamp without time zone not null default now(),
+  expires_at timestamp without time zone not null,
+  remote_addr text not null,
+  user_agent text not null
+);
+CREATE INDEX idx_user_sessions_user_id
+    ON user_sessions USING btree
+    (user_id ASC NULLS LAST)
+;
@@ -0,0 +1,9 @@
+ALTER TABLE user_sessions
+  ALTER COLUMN created_at TYPE timestamp without time zone USING created_at AT TIME ZONE 'UTC',
+  ALTER COLUMN last_seen_at TYPE timestamp without time zone USING last_seen_at AT TIME ZONE 'UTC',
+  ALTER COLUMN expires_at TYPE timestamp without time zone USING expires_at AT TIME ZONE 'UTC'
+;
+ALTER TABLE api_tokens
+  ALTER COLUMN created_at TYPE timestamp without time zone,
+  ALTER COLUMN last_used_at TYPE timestamp without time zone
+;
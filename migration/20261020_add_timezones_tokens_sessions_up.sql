@@ -0,0 +1,11 @@
+-- время токенов и сессий хранится с часовым поясом, как и время записей (20261019_add_timezones).
+-- Время токенов записывалось функцией now() в часовом поясе БД, время сессий - сервером в UTC
+ALTER TABLE api_tokens
+  ALTER COLUMN created_at TYPE timestamp with time zone,
+  ALTER COLUMN last_used_at TYPE timestamp with time zone
+;
+ALTER TABLE user_sessions
+  ALTER COLUMN created_at TYPE timestamp with time zone USING created_at AT TIME ZONE 'UTC',
+  ALTER COLUMN last_seen_at TYPE timestamp with time zone USING last_seen_at AT TIME ZONE 'UTC',
+  ALTER COLUMN expires_at TYPE timestamp with time zone USING expires_at AT TIME ZONE 'UTC'
+;
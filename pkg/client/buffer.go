@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBatchSize     = 500
+	defaultFlushInterval = time.Second
+	defaultCapacity      = 10000
+)
+
+// ErrBufferClosed Буфер закрыт
+var ErrBufferClosed = errors.New("buffer is closed")
+
+// BufferConfig Настройки буфера
+type BufferConfig struct {
+	// BatchSize Максимальный размер пачки, отправляемой одним запросом. По умолчанию 500
+	BatchSize int
+	// FlushInterval Период отправки накопленных записей. По умолчанию 1 секунда
+	FlushInterval time.Duration
+	// Capacity Размер очереди. При ее заполнении новые записи отбрасываются. По умолчанию 10000
+	Capacity int
+	// OnError Вызывается из фоновой горутины при ошибке отправки пачки. Записи пачки при этом теряются
+	OnError func(err error, records []Record)
+}
+
+// Buffer Очередь записей, отправляемых на сервер пачками в отдельной горутине.
+// Log не блокируется при недоступности сервера: при заполнении очереди записи отбрасываются
+type Buffer struct {
+	client *Client
+	config BufferConfig
+
+	records chan Record
+	flushes chan flushRequest
+	stop    chan flushRequest
+	done    chan struct{}
+
+	closeOnce sync.Once
+	closed    int32
+	dropped   uint64
+	failed    uint64
+}
+
+// Запрос отправки накопленных записей
+type flushRequest struct {
+	ctx    context.Context //nolint:containedctx
+	result chan error
+}
+
+// NewBuffer Создание буфера и запуск фоновой отправки. Буфер нужно закрыть методом Close
+func NewBuffer(client *Client, config BufferConfig) *Buffer {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+
+	if config.Capacity <= 0 {
+		config.Capacity = defaultCapacity
+	}
+
+	b := &Buffer{
+		client:    client,
+		config:    config,
+		records:   make(chan Record, config.Capacity),
+		flushes:   make(chan flushRequest),
+		stop:      make(chan flushRequest, 1),
+		done:      make(chan struct{}),
+		closeOnce: sync.Once{},
+		closed:    0,
+		dropped:   0,
+		failed:    0,
+	}
+
+	go b.run()
+
+	return b
+}
+
+// Log Поставить запись в очередь. Не блокируется. Возвращает false, если запись отброшена
+func (b *Buffer) Log(record Record) bool {
+	if atomic.LoadInt32(&b.closed) != 0 {
+		atomic.AddUint64(&b.dropped, 1)
+
+		return false
+	}
+
+	select {
+	case b.records <- record:
+		return true
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+
+		return false
+	}
+}
+
+// Flush Отправить все записи, поставленные в очередь до вызова
+func (b *Buffer) Flush(ctx context.Context) error {
+	req := flushRequest{ctx: ctx, result: make(chan error, 1)}
+
+	select {
+	case b.flushes <- req:
+	case <-b.done:
+		return ErrBufferClosed
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "flush")
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "flush")
+	}
+}
+
+// Close Отправить накопленные записи и остановить фоновую отправку.
+// Записи, переданные в Log после закрытия, отбрасываются
+func (b *Buffer) Close(ctx context.Context) error {
+	req := flushRequest{ctx: ctx, result: make(chan error, 1)}
+	started := false
+
+	b.closeOnce.Do(func() {
+		atomic.StoreInt32(&b.closed, 1)
+		b.stop <- req
+		started = true
+	})
+
+	if !started {
+		<-b.done
+
+		return nil
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "close")
+	}
+}
+
+// Dropped Количество записей, отброшенных из-за переполнения очереди или после закрытия
+func (b *Buffer) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// Failed Количество записей, потерянных из-за ошибок отправки
+func (b *Buffer) Failed() uint64 {
+	return atomic.LoadUint64(&b.failed)
+}
+
+func (b *Buffer) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, b.config.BatchSize)
+
+	for {
+		select {
+		case record := <-b.records:
+			batch = append(batch, record)
+			if len(batch) >= b.config.BatchSize {
+				_ = b.send(context.Background(), batch)
+				batch = make([]Record, 0, b.config.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				_ = b.send(context.Background(), batch)
+				batch = make([]Record, 0, b.config.BatchSize)
+			}
+		case req := <-b.flushes:
+			req.result <- b.drain(req.ctx, batch)
+			batch = make([]Record, 0, b.config.BatchSize)
+		case req := <-b.stop:
+			req.result <- b.drain(req.ctx, batch)
+
+			return
+		}
+	}
+}
+
+// Отправка накопленной пачки и всего, что есть в очереди. Возвращает первую ошибку
+func (b *Buffer) drain(ctx context.Context, batch []Record) error {
+	var firstErr error
+
+	for {
+		select {
+		case record := <-b.records:
+			batch = append(batch, record)
+			if len(batch) < b.config.BatchSize {
+				continue
+			}
+		default:
+		}
+
+		if len(batch) == 0 {
+			return firstErr
+		}
+
+		full := len(batch) >= b.config.BatchSize
+		if err := b.send(ctx, batch); err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		if !full {
+			return firstErr
+		}
+
+		batch = make([]Record, 0, b.config.BatchSize)
+	}
+}
+
+// Отправка пачки. При ошибке записи считаются потерянными
+func (b *Buffer) send(ctx context.Context, batch []Record) error {
+	err := b.client.Add(ctx, batch)
+	if err == nil {
+		return nil
+	}
+
+	atomic.AddUint64(&b.failed, uint64(len(batch)))
+
+	if b.config.OnError != nil {
+		b.config.OnError(err, batch)
+	}
+
+	return err
+}
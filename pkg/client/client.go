@@ -0,0 +1,510 @@
+// Package client Клиент REST API сервера журнала для Go приложений.
+// Client - типизированные запросы к API, Buffer - фоновая отправка записей пачками,
+// LogrusHook и SlogHandler - подключение к logrus и log/slog
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	schemalog "github.com/n-r-w/log-server/api/schema/schema.log"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// имя куки сессии сервера
+	sessionCookie = "logserver"
+	// хедер запроса ответа в формате protobuf
+	binaryFormatHeader   = "binary-format"
+	binaryFormatProtobuf = "protobuf"
+	recordsLimitedHeader = "X-Records-Limited"
+
+	defaultTimeout      = 30 * time.Second
+	defaultRetryBackoff = 500 * time.Millisecond
+	// максимальный размер читаемого ответа с ошибкой
+	maxErrorBody = 1 << 20
+)
+
+var (
+	// ErrNoServer Не задан адрес сервера
+	ErrNoServer = errors.New("server address is required")
+	// ErrNoSession Сервер не вернул куки сессии при логине
+	ErrNoSession = errors.New("server did not return a session cookie")
+)
+
+// Config Настройки клиента
+type Config struct {
+	// Server Адрес сервера, например http://localhost:8080
+	Server string
+	// Token Токен API. Если не задан, используется сессия, открытая Login
+	Token string
+	// Session Куки сессии, открытой ранее (Client.Session), например сохраненной между запусками программы
+	Session string
+	// HTTPClient HTTP клиент. По умолчанию http.Client с таймаутом 30 секунд
+	HTTPClient *http.Client
+	// Retries Число повторов запроса при сетевых ошибках и ответах 429, 502, 503 и 504. 0 - без повторов.
+	// Повтор добавления записей может привести к дублям, если сервер сохранил пачку, но ответ не дошел
+	Retries int
+	// RetryBackoff Пауза перед первым повтором, далее удваивается. По умолчанию 500 мс.
+	// Хедер Retry-After в ответе сервера имеет приоритет
+	RetryBackoff time.Duration
+	// DisableGzip Отправлять записи без сжатия
+	DisableGzip bool
+	// UserAgent Значение хедера User-Agent
+	UserAgent string
+}
+
+// Client Клиент REST API сервера журнала. Безопасен для использования из нескольких горутин
+type Client struct {
+	config Config
+	server string
+	http   *http.Client
+
+	mu     sync.Mutex
+	cookie string
+}
+
+// New Создание клиента
+func New(config Config) (*Client, error) {
+	if config.Server == "" {
+		return nil, ErrNoServer
+	}
+
+	if _, err := url.ParseRequestURI(config.Server); err != nil {
+		return nil, errors.Wrap(err, "server")
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout} //nolint:exhaustivestruct,exhaustruct
+	}
+
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaultRetryBackoff
+	}
+
+	if config.UserAgent == "" {
+		config.UserAgent = "log-server-go-client"
+	}
+
+	return &Client{
+		config: config,
+		server: strings.TrimRight(config.Server, "/"),
+		http:   httpClient,
+		mu:     sync.Mutex{},
+		cookie: config.Session,
+	}, nil
+}
+
+// Server Адрес сервера
+func (c *Client) Server() string {
+	return c.server
+}
+
+// Session Куки сессии, открытой Login. Пустая строка, если сессии нет
+func (c *Client) Session() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cookie
+}
+
+// Login Открыть сессию по логину и паролю. Используется, если не задан токен API
+func (c *Client) Login(ctx context.Context, login, password string) error {
+	body, err := json.Marshal(map[string]string{"login": login, "password": password})
+	if err != nil {
+		return errors.Wrap(err, "json")
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/api/auth/login", nil, body, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == sessionCookie {
+			c.mu.Lock()
+			c.cookie = cookie.Value
+			c.mu.Unlock()
+
+			return nil
+		}
+	}
+
+	return ErrNoSession
+}
+
+// Logout Закрыть сессию, открытую Login
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.doJSON(ctx, http.MethodDelete, "/api/auth/close", nil, nil, nil); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.cookie = ""
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Whoami Текущий пользователь
+func (c *Client) Whoami(ctx context.Context) (*User, error) {
+	var user User
+
+	if err := c.doJSON(ctx, http.MethodGet, "/api/private/whoami", nil, nil, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Users Список пользователей (только для администратора)
+func (c *Client) Users(ctx context.Context) ([]User, error) {
+	var users []User
+
+	if err := c.doJSON(ctx, http.MethodGet, "/api/private/users", nil, nil, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Add Добавить записи одним запросом. Тело запроса сжимается gzip, если это не отключено в Config
+func (c *Client) Add(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return errors.Wrap(err, "json")
+	}
+
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	if !c.config.DisableGzip {
+		var buf bytes.Buffer
+
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return errors.Wrap(err, "gzip")
+		}
+
+		if err := zw.Close(); err != nil {
+			return errors.Wrap(err, "gzip")
+		}
+
+		data = buf.Bytes()
+		header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/api/private/add-log", nil, data, header)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(resp.Body.Close(), "response")
+}
+
+// Records Записи за интервал в порядке убывания времени. Нулевое время - без ограничения.
+// Ответ передается в формате protobuf (api/schema), размер ограничен MAX_LOG_RECORDS_RESULT сервера.
+// limited - за интервал записей больше и возвращены только самые новые
+func (c *Client) Records(ctx context.Context, from, to time.Time) (records []Record, limited bool, err error) {
+	body, err := json.Marshal(map[string]time.Time{"timeFrom": from, "timeTo": to})
+	if err != nil {
+		return nil, false, errors.Wrap(err, "json")
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, "/api/private/records", nil, body, http.Header{
+		binaryFormatHeader: {binaryFormatProtobuf},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	// пустой результат приходит в виде JSON
+	if resp.Header.Get(binaryFormatHeader) != binaryFormatProtobuf {
+		return nil, false, nil
+	}
+
+	limited = resp.Header.Get(recordsLimitedHeader) == "true"
+
+	// protobuf ответ сжимается gzip без хедера Content-Encoding
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "gzip")
+	}
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "gzip")
+	}
+
+	var msg schemalog.LogRecords
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil, false, errors.Wrap(err, "protobuf")
+	}
+
+	records = make([]Record, 0, len(msg.Records))
+	for _, r := range msg.Records {
+		records = append(records, Record{
+			ID:       r.Id,
+			LogTime:  r.LogTime.AsTime(),
+			RealTime: r.RealTime.AsTime(),
+			Level:    uint(r.Level),
+			Message1: r.Message1,
+			Message2: r.Message2,
+			Message3: r.Message3,
+		})
+	}
+
+	return records, limited, nil
+}
+
+// Page Страница записей. Для обхода всех записей передается NextCursor предыдущей страницы
+func (c *Client) Page(ctx context.Context, q PageQuery) (*Page, error) {
+	query := url.Values{}
+
+	if !q.From.IsZero() {
+		query.Set("from", q.From.Format(time.RFC3339Nano))
+	}
+
+	if !q.To.IsZero() {
+		query.Set("to", q.To.Format(time.RFC3339Nano))
+	}
+
+	if q.Cursor != "" {
+		query.Set("cursor", q.Cursor)
+	}
+
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+
+	var page Page
+
+	if err := c.doJSON(ctx, http.MethodGet, "/api/private/records/page", query, nil, &page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// Record Запись по ID
+func (c *Client) Record(ctx context.Context, id uint64) (*Record, error) {
+	var record Record
+
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/api/private/records/%d", id), nil, nil,
+		&record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Export Выгрузка записей (/api/private/export). Тело ответа читается потоком, его нужно закрыть.
+// Если во время выгрузки на сервере произошла ошибка, соединение обрывается и чтение завершается ошибкой
+func (c *Client) Export(ctx context.Context, q ExportQuery) (io.ReadCloser, error) {
+	query := url.Values{}
+
+	for k, v := range map[string]string{"format": q.Format, "from": q.From, "to": q.To, "tz": q.TZ} {
+		if v != "" {
+			query.Set(k, v)
+		}
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, "/api/private/export", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// Import Загрузка записей из файла (/api/private/import, только для администратора). Запрос не повторяется.
+// Если загрузка прервана, вместе с ошибкой *Error возвращается результат с причиной в поле Error:
+// продолжить можно, передав Line в ImportQuery.Skip
+func (c *Client) Import(ctx context.Context, q ImportQuery, body io.Reader) (*ImportResult, error) {
+	query := url.Values{}
+	query.Set("format", q.Format)
+	query.Set("skip", strconv.Itoa(q.Skip))
+
+	if q.TZ != "" {
+		query.Set("tz", q.TZ)
+	}
+
+	var res ImportResult
+
+	resp, err := c.send(ctx, http.MethodPost, "/api/private/import", query, body, nil)
+	if err != nil {
+		// прерванная загрузка возвращает прогресс вместе с кодом ошибки
+		var apiErr *Error
+		if errors.As(err, &apiErr) && json.Unmarshal(apiErr.body, &res) == nil && res.Error != "" {
+			return &res, err
+		}
+
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, errors.Wrap(err, "json")
+	}
+
+	return &res, nil
+}
+
+// Запрос с JSON ответом. out может быть nil
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body []byte,
+	out interface{},
+) error {
+	resp, err := c.do(ctx, method, path, query, body, http.Header{"Content-Type": {"application/json"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(out), "json")
+}
+
+// Запрос к серверу с повторами. Ответ с кодом не 2xx возвращается как *Error
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body []byte,
+	header http.Header,
+) (*http.Response, error) {
+	backoff := c.config.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+
+		resp, err := c.send(ctx, method, path, query, reader, header)
+		if err == nil {
+			return resp, nil
+		}
+
+		wait, retry := retryDelay(err, backoff)
+		if !retry || attempt >= c.config.Retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+// Однократный запрос к серверу
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body io.Reader,
+	header http.Header,
+) (*http.Response, error) {
+	u := c.server + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "request")
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("User-Agent", c.config.UserAgent)
+
+	if c.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	} else {
+		c.mu.Lock()
+		cookie := c.cookie
+		c.mu.Unlock()
+
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: sessionCookie, Value: cookie}) //nolint:exhaustivestruct,exhaustruct
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "request")
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Code:       "",
+		Message:    "",
+		Fields:     nil,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+		retryAfter: resp.Header.Get("Retry-After"),
+		body:       data,
+	}
+
+	var errBody struct {
+		Error *Error `json:"error"`
+	}
+
+	if json.Unmarshal(data, &errBody) == nil && errBody.Error != nil {
+		apiErr.Code, apiErr.Message, apiErr.Fields = errBody.Error.Code, errBody.Error.Message, errBody.Error.Fields
+		if errBody.Error.RequestID != "" {
+			apiErr.RequestID = errBody.Error.RequestID
+		}
+	}
+
+	return nil, apiErr
+}
+
+// Нужен ли повтор запроса после ошибки и пауза перед ним
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// сетевая ошибка
+		return backoff, true
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	if sec, err := strconv.Atoi(apiErr.retryAfter); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+
+	return backoff, true
+}
@@ -0,0 +1,253 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/internal/testserver"
+	"github.com/n-r-w/log-server/pkg/client"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Клиент тестового сервера с открытой сессией администратора
+func newTestClient(t *testing.T) (*client.Client, *testserver.Server) {
+	t.Helper()
+
+	srv := testserver.New(t)
+	ts := httptest.NewServer(srv.Router)
+	t.Cleanup(ts.Close)
+
+	c, err := client.New(client.Config{
+		Server:       ts.URL,
+		Token:        "",
+		Session:      "",
+		HTTPClient:   ts.Client(),
+		Retries:      0,
+		RetryBackoff: 0,
+		DisableGzip:  false,
+		UserAgent:    "",
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Login(context.Background(), srv.Config.SuperAdminLogin, srv.Config.SuperPassword))
+
+	return c, srv
+}
+
+func TestClient(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, c.Add(ctx, []client.Record{
+		{LogTime: start, Level: client.LevelInfo, Message1: "started", Message2: "billing"},
+		{LogTime: start.Add(time.Minute), Level: client.LevelError, Message1: "failed", Message2: "billing"},
+	}))
+
+	// protobuf
+	records, limited, err := c.Records(ctx, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.False(t, limited)
+
+	// порядок записей тестового хранилища не определен
+	byMessage := map[string]client.Record{}
+	for _, r := range records {
+		byMessage[r.Message1] = r
+	}
+
+	assert.Equal(t, client.LevelError, byMessage["failed"].Level)
+	assert.True(t, byMessage["failed"].LogTime.Equal(start.Add(time.Minute)))
+
+	page, err := c.Page(ctx, client.PageQuery{From: start, To: time.Time{}, Cursor: "", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.NotEmpty(t, page.NextCursor)
+
+	record, err := c.Record(ctx, byMessage["started"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "started", record.Message1)
+
+	user, err := c.Whoami(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Role)
+
+	// ошибка валидации
+	err = c.Add(ctx, []client.Record{{LogTime: start, Level: client.LevelInfo}})
+
+	var apiErr *client.Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Contains(t, apiErr.Fields, "[0].message1")
+
+	require.NoError(t, c.Logout(ctx))
+	_, err = c.Whoami(ctx)
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestClient_ExportImport(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	start := time.Date(2022, 4, 23, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, c.Add(ctx, []client.Record{
+		{LogTime: start, Level: client.LevelInfo, Message1: "exported", Message2: "billing"},
+	}))
+
+	body, err := c.Export(ctx, client.ExportQuery{Format: "ndjson", From: "", To: "", TZ: ""})
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Contains(t, string(data), "exported")
+
+	// сессия, сохраненная между запусками
+	restored, err := client.New(client.Config{
+		Server:       c.Server(),
+		Token:        "",
+		Session:      c.Session(),
+		HTTPClient:   nil,
+		Retries:      0,
+		RetryBackoff: 0,
+		DisableGzip:  false,
+		UserAgent:    "",
+	})
+	require.NoError(t, err)
+
+	res, err := restored.Import(ctx, client.ImportQuery{Format: "ndjson", Skip: 0, TZ: ""}, bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Imported)
+
+	// прерванная загрузка возвращает прогресс вместе с ошибкой
+	res, err = restored.Import(ctx, client.ImportQuery{Format: "proto-delimited", Skip: 0, TZ: ""},
+		strings.NewReader("\x05ab"))
+
+	var apiErr *client.Error
+	require.True(t, errors.As(err, &apiErr))
+	require.NotNil(t, res)
+	assert.NotEmpty(t, res.Error)
+}
+
+func TestClient_Retry(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(ts.Close)
+
+	c, err := client.New(client.Config{
+		Server:       ts.URL,
+		Token:        "token",
+		Session:      "",
+		HTTPClient:   ts.Client(),
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		DisableGzip:  false,
+		UserAgent:    "",
+	})
+	require.NoError(t, err)
+
+	record := client.Record{LogTime: time.Now(), Level: client.LevelInfo, Message1: "retry"}
+	require.NoError(t, c.Add(context.Background(), []client.Record{record}))
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+
+	// повторы исчерпаны
+	atomic.StoreInt32(&calls, 0)
+	c, err = client.New(client.Config{
+		Server:       ts.URL,
+		Token:        "token",
+		Session:      "",
+		HTTPClient:   ts.Client(),
+		Retries:      1,
+		RetryBackoff: time.Millisecond,
+		DisableGzip:  false,
+		UserAgent:    "",
+	})
+	require.NoError(t, err)
+
+	var apiErr *client.Error
+	require.True(t, errors.As(c.Add(context.Background(), []client.Record{record}), &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestBuffer(t *testing.T) {
+	c, srv := newTestClient(t)
+	ctx := context.Background()
+
+	var failed int32
+
+	buf := client.NewBuffer(c, client.BufferConfig{
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		Capacity:      100,
+		OnError:       func(err error, records []client.Record) { atomic.AddInt32(&failed, int32(len(records))) },
+	})
+
+	for i := 0; i < 7; i++ {
+		assert.True(t, buf.Log(client.Record{LogTime: time.Now(), Level: client.LevelInfo, Message1: "buffered"}))
+	}
+
+	require.NoError(t, buf.Flush(ctx))
+
+	records, _, err := srv.LogRepo.Find(ctx, time.Time{}, time.Time{}, 100)
+	require.NoError(t, err)
+	assert.Len(t, *records, 7)
+
+	// logrus: поля в message3
+	logger := logrus.New()
+	logger.SetOutput(&nopWriter{})
+	logger.AddHook(client.NewLogrusHook(buf, "billing", logrus.InfoLevel))
+	logger.WithField("order", 42).Warn("slow payment")
+	logger.Debug("skipped")
+
+	// при закрытии отправляются накопленные записи, после закрытия записи отбрасываются
+	require.NoError(t, buf.Close(ctx))
+	assert.False(t, buf.Log(client.Record{LogTime: time.Now(), Level: client.LevelInfo, Message1: "late"}))
+	assert.EqualValues(t, 1, buf.Dropped())
+	assert.ErrorIs(t, buf.Flush(ctx), client.ErrBufferClosed)
+
+	records, _, err = srv.LogRepo.Find(ctx, time.Time{}, time.Time{}, 100)
+	require.NoError(t, err)
+	require.Len(t, *records, 8)
+
+	var hooked bool
+
+	for _, r := range *records {
+		if r.Message1 == "slow payment" {
+			hooked = true
+
+			assert.Equal(t, client.LevelWarning, r.Level)
+			assert.Equal(t, "billing", r.Message2)
+			assert.JSONEq(t, `{"order":42}`, r.Message3)
+		}
+	}
+
+	assert.True(t, hooked)
+	assert.EqualValues(t, 0, buf.Failed())
+	assert.EqualValues(t, 0, atomic.LoadInt32(&failed))
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusHook Хук logrus, отправляющий записи на сервер через Buffer.
+// Источник записи сохраняется в message2, поля записи - в message3 в виде JSON
+type LogrusHook struct {
+	buffer *Buffer
+	source string
+	levels []logrus.Level
+}
+
+// NewLogrusHook Создание хука. Отправляются записи с уровнем level и выше
+func NewLogrusHook(buffer *Buffer, source string, level logrus.Level) *LogrusHook {
+	levels := make([]logrus.Level, 0, len(logrus.AllLevels))
+	for _, l := range logrus.AllLevels {
+		if l <= level {
+			levels = append(levels, l)
+		}
+	}
+
+	return &LogrusHook{
+		buffer: buffer,
+		source: source,
+		levels: levels,
+	}
+}
+
+// Levels Уровни записей, обрабатываемых хуком
+func (h *LogrusHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire Поставить запись в очередь на отправку. Не блокируется
+func (h *LogrusHook) Fire(entry *logrus.Entry) error {
+	fields := make(map[string]interface{}, len(entry.Data))
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		fields[k] = v
+	}
+
+	h.buffer.Log(Record{
+		ID:       0,
+		LogTime:  entry.Time,
+		RealTime: time.Time{},
+		Level:    logrusLevel(entry.Level),
+		Message1: nonEmpty(entry.Message),
+		Message2: h.source,
+		Message3: marshalFields(fields),
+	})
+
+	return nil
+}
+
+// Уровень записи журнала, соответствующий уровню logrus
+func logrusLevel(l logrus.Level) uint {
+	switch l {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return LevelCritical
+	case logrus.ErrorLevel:
+		return LevelError
+	case logrus.WarnLevel:
+		return LevelWarning
+	case logrus.InfoLevel:
+		return LevelInfo
+	case logrus.DebugLevel, logrus.TraceLevel:
+		return LevelDebug
+	default:
+		return LevelDebug
+	}
+}
+
+// Поля записи в виде JSON для message3. Пустая строка, если полей нет
+func marshalFields(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// Текст для message1, которое сервер требует заполнить. Иначе сервер отклонит всю пачку
+func nonEmpty(message string) string {
+	if message == "" {
+		return "-"
+	}
+
+	return message
+}
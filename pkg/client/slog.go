@@ -0,0 +1,171 @@
+//go:build go1.21
+
+package client
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogHandler Обработчик log/slog, отправляющий записи на сервер через Buffer.
+// Источник записи сохраняется в message2, атрибуты - в message3 в виде JSON с вложенными объектами для групп
+type SlogHandler struct {
+	buffer *Buffer
+	source string
+	level  slog.Leveler
+	// attrs Атрибуты, добавленные WithAttrs
+	attrs map[string]interface{}
+	// groups Текущая группа, заданная WithGroup
+	groups []string
+}
+
+// NewSlogHandler Создание обработчика. Отправляются записи с уровнем level и выше, nil - slog.LevelInfo
+func NewSlogHandler(buffer *Buffer, source string, level slog.Leveler) *SlogHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	return &SlogHandler{
+		buffer: buffer,
+		source: source,
+		level:  level,
+		attrs:  map[string]interface{}{},
+		groups: nil,
+	}
+}
+
+// Enabled Обрабатывается ли запись с уровнем level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle Поставить запись в очередь на отправку. Не блокируется
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := cloneFields(h.attrs)
+
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(fields, h.groups, a)
+
+		return true
+	})
+
+	logTime := r.Time
+	if logTime.IsZero() {
+		logTime = time.Now()
+	}
+
+	h.buffer.Log(Record{
+		ID:       0,
+		LogTime:  logTime,
+		RealTime: time.Time{},
+		Level:    slogLevel(r.Level),
+		Message1: nonEmpty(r.Message),
+		Message2: h.source,
+		Message3: marshalFields(fields),
+	})
+
+	return nil
+}
+
+// WithAttrs Обработчик с дополнительными атрибутами
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.attrs = cloneFields(h.attrs)
+
+	for _, a := range attrs {
+		addAttr(h2.attrs, h.groups, a)
+	}
+
+	return &h2
+}
+
+// WithGroup Обработчик, помещающий последующие атрибуты в группу name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(append(make([]string, 0, len(h.groups)+1), h.groups...), name)
+
+	return &h2
+}
+
+// Уровень записи журнала, соответствующий уровню slog
+func slogLevel(l slog.Level) uint {
+	switch {
+	case l < slog.LevelInfo:
+		return LevelDebug
+	case l < slog.LevelWarn:
+		return LevelInfo
+	case l < slog.LevelError:
+		return LevelWarning
+	default:
+		return LevelError
+	}
+}
+
+// Добавление атрибута в группу groups
+func addAttr(fields map[string]interface{}, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	for _, g := range groups {
+		sub, ok := fields[g].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			fields[g] = sub
+		}
+
+		fields = sub
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+
+		// группа без имени добавляется на текущий уровень
+		var path []string
+		if a.Key != "" {
+			path = []string{a.Key}
+		}
+
+		for _, ga := range attrs {
+			addAttr(fields, path, ga)
+		}
+	case slog.KindAny:
+		v := a.Value.Any()
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		fields[a.Key] = v
+	default:
+		fields[a.Key] = a.Value.Any()
+	}
+}
+
+// Копия атрибутов вместе с вложенными группами
+func cloneFields(fields map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(fields))
+
+	for k, v := range fields {
+		if sub, ok := v.(map[string]interface{}); ok {
+			v = cloneFields(sub)
+		}
+
+		res[k] = v
+	}
+
+	return res
+}
@@ -0,0 +1,47 @@
+//go:build go1.21
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/log-server/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogHandler(t *testing.T) {
+	c, srv := newTestClient(t)
+	ctx := context.Background()
+
+	buf := client.NewBuffer(c, client.BufferConfig{
+		BatchSize:     0,
+		FlushInterval: 0,
+		Capacity:      0,
+		OnError:       nil,
+	})
+
+	logger := slog.New(client.NewSlogHandler(buf, "billing", slog.LevelInfo)).
+		With("service", "api").
+		WithGroup("request").
+		With("id", 7)
+
+	logger.Debug("skipped")
+	logger.Error("payment failed", "err", errors.New("timeout"), slog.Group("user", "id", 1))
+
+	require.NoError(t, buf.Close(ctx))
+
+	records, _, err := srv.LogRepo.Find(ctx, time.Time{}, time.Time{}, 100)
+	require.NoError(t, err)
+	require.Len(t, *records, 1)
+
+	r := (*records)[0]
+	assert.Equal(t, "payment failed", r.Message1)
+	assert.Equal(t, "billing", r.Message2)
+	assert.Equal(t, client.LevelError, r.Level)
+	assert.JSONEq(t, `{"service":"api","request":{"id":7,"err":"timeout","user":{"id":1}}}`, r.Message3)
+}
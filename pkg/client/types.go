@@ -0,0 +1,121 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// Уровни записей, которые использует сам сервер. Сервер не ограничивает клиентов этими значениями
+const (
+	LevelDebug    uint = 1
+	LevelInfo     uint = 2
+	LevelWarning  uint = 3
+	LevelError    uint = 4
+	LevelCritical uint = 5
+)
+
+// Record Запись журнала. При добавлении обязательны LogTime, Level и Message1, ID и RealTime назначает сервер.
+// Message2 обычно используется как источник записи, Message3 - как подробности
+type Record struct {
+	ID       uint64    `json:"id"`
+	LogTime  time.Time `json:"logTime"`
+	RealTime time.Time `json:"realTime"`
+	Level    uint      `json:"level"`
+	Message1 string    `json:"message1"`
+	Message2 string    `json:"message2"`
+	Message3 string    `json:"message3"`
+}
+
+// Page Страница записей в порядке убывания времени. NextCursor пустой, если страница последняя
+type Page struct {
+	Records    []Record `json:"records"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// PageQuery Параметры запроса страницы записей. Нулевое время - без ограничения
+type PageQuery struct {
+	From time.Time
+	To   time.Time
+	// Cursor NextCursor предыдущей страницы
+	Cursor string
+	// Limit Размер страницы. 0 - размер страницы веб интерфейса из конфига сервера
+	Limit int
+}
+
+// ExportQuery Параметры выгрузки записей. Пустые значения - значения сервера по умолчанию
+type ExportQuery struct {
+	// Format csv (по умолчанию), ndjson или proto-delimited
+	Format string
+	// From Начало интервала: RFC3339, время без смещения в часовом поясе TZ или now-<длительность>
+	From string
+	// To Конец интервала
+	To string
+	// TZ Часовой пояс IANA времени без смещения и времени в CSV
+	TZ string
+}
+
+// ImportQuery Параметры загрузки записей из файла
+type ImportQuery struct {
+	// Format ndjson, csv или proto-delimited
+	Format string
+	// Skip Количество уже загруженных строк (продолжение прерванной загрузки)
+	Skip int
+	// TZ Часовой пояс IANA времени CSV без смещения
+	TZ string
+}
+
+// ImportResult Результат загрузки записей
+type ImportResult struct {
+	// Line Номер последней прочитанной строки
+	Line int `json:"line"`
+	// Imported Количество загруженных записей
+	Imported int `json:"imported"`
+	// Failed Количество отклоненных записей
+	Failed int `json:"failed"`
+	// Errors Первые ошибки отдельных строк
+	Errors []ImportError `json:"errors"`
+	// Error Причина прерывания загрузки. Пустая, если файл загружен полностью
+	Error string `json:"error"`
+}
+
+// ImportError Ошибка строки файла
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// User Пользователь
+type User struct {
+	ID       uint64 `json:"id"`
+	Login    string `json:"login"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Timezone string `json:"timezone"`
+}
+
+// Error Ошибка, возвращенная сервером
+type Error struct {
+	// StatusCode HTTP статус ответа
+	StatusCode int
+	// Code Текстовый код ошибки (validation, not_found и т.п.)
+	Code string `json:"code"`
+	// Message Описание ошибки
+	Message string `json:"message"`
+	// Fields Ошибки по отдельным полям, например "[0].message1"
+	Fields map[string]string `json:"fields,omitempty"`
+	// RequestID Номер запроса в журнале сервера
+	RequestID string `json:"requestId,omitempty"`
+
+	// значение хедера Retry-After
+	retryAfter string
+	// тело ответа
+	body []byte
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("log server: status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("log server: status %d: %s", e.StatusCode, e.Message)
+}